@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -12,9 +14,14 @@ import (
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Show current configuration",
-	Long: `Display current configuration from environment variables.
+	Long: `Display the effective configuration and where each value came from.
 
-Shows database connection settings and other environment vars.
+Values are resolved in order of precedence:
+  1. Command line flags (--db-host, --db-port, --log-level, ...)
+  2. Environment variables (MUSICAPP_*)
+  3. Config file (--config, default $HOME/.imapp.yaml)
+  4. docker/docker.env
+  5. Built-in defaults
 
 Examples:
   mlm config`,
@@ -31,13 +38,16 @@ func showConfig() {
 	fmt.Println("📝 Current Configuration:")
 	fmt.Println()
 
-	// Database config
-	fmt.Println("🗄️  Database:")
-	fmt.Printf("  Host:     %s\n", getEnv("MUSICAPP_PG_HOST", "127.0.0.1"))
-	fmt.Printf("  Port:     %s\n", getEnv("MUSICAPP_PG_PORT", "3306"))
-	fmt.Printf("  User:     %s\n", getEnv("MUSICAPP_PG_USER", "user"))
-	fmt.Printf("  Password: %s\n", maskPassword(getEnv("MUSICAPP_PG_PASS", "password")))
-	fmt.Printf("  Database: %s\n", getEnv("MUSICAPP_PG_DATABASE", "mlm"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  SETTING\tVALUE\tSOURCE")
+	for _, s := range cfg.Settings() {
+		value := s.Value
+		if s.Secret {
+			value = maskPassword(value)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", s.Key, value, s.Origin)
+	}
+	w.Flush()
 	fmt.Println()
 
 	// Config file
@@ -54,11 +64,3 @@ func maskPassword(pass string) string {
 	}
 	return pass[:2] + strings.Repeat("*", len(pass)-2)
 }
-
-// ViperPG returns viper with PG env vars loaded (for compatibility)
-func ViperPG() *viper.Viper {
-	v := viper.New()
-	v.AutomaticEnv()
-	v.SetEnvPrefix("MUSICAPP_PG")
-	return v
-}
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 
+	"github.com/aarondl/null/v8"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/spf13/cobra"
+
+	"mlm/internal/musicapp/db/repo"
+	"mlm/models"
 )
 
 // dbCmd represents the db command
@@ -75,9 +80,9 @@ func recreateDatabase() {
 	db := connectDB()
 	defer db.Close()
 
-	// Drop all tables
+	// Drop all tables, including the migration ledger so migrations re-run
 	log.Println("📦 Dropping all tables...")
-	tables := []string{"room_members", "rooms", "users"}
+	tables := []string{"room_members", "rooms", "users", "schema_migrations"}
 
 	_, err := db.Exec("SET FOREIGN_KEY_CHECKS = 0")
 	if err != nil {
@@ -152,33 +157,27 @@ func seedDatabase() {
 		{"eve", "Eve Anderson", "female"},
 	}
 
-	for _, u := range users {
-		_, err := db.Exec(`
-			INSERT INTO users (username, display_name, gender)
-			VALUES (?, ?, ?)
-		`, u.username, u.displayName, u.gender)
-
-		if err != nil {
-			log.Printf("⚠️  Failed to create user %s: %v", u.username, err)
-		} else {
-			log.Printf("✅ Created user: %s", u.username)
+	// Usernames are unique, so re-seeding keeps the users already there
+	seed := make([]*models.User, len(users))
+	for i, u := range users {
+		seed[i] = &models.User{
+			Username:    u.username,
+			DisplayName: null.StringFrom(u.displayName),
+			Gender:      null.StringFrom(u.gender),
 		}
 	}
 
+	result, err := repo.NewUserRepo().BulkUpsert(context.Background(), db, seed)
+	if err != nil {
+		log.Fatalf("❌ Failed to create users: %v", err)
+	}
+	log.Printf("✅ Created %d users (%d already existed)", result.Inserted, result.Unchanged)
+
 	log.Println("🎉 Database seeded successfully")
 }
 
 func connectDB() *sql.DB {
-	dbHost := getEnv("MUSICAPP_PG_HOST", "127.0.0.1")
-	dbPort := getEnv("MUSICAPP_PG_PORT", "3306")
-	dbUser := getEnv("MUSICAPP_PG_USER", "user")
-	dbPass := getEnv("MUSICAPP_PG_PASS", "password")
-	dbName := getEnv("MUSICAPP_PG_DATABASE", "mlm")
-
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		dbUser, dbPass, dbHost, dbPort, dbName)
-
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", cfg.Database.DSN())
 	if err != nil {
 		log.Fatalf("❌ Failed to connect to database: %v", err)
 	}
@@ -3,19 +3,30 @@ package cmd
 import (
 	"database/sql"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/spf13/cobra"
+
+	"mlm/migration"
 )
 
 var (
-	migrateDown bool
-	migrateStep int
+	migrateDown        bool
+	migrateStep        int
+	migrateDir         string
+	migrateRepair      bool
+	migrateLockTimeout time.Duration
+	migrateDryRun      bool
+	migrateBaseline    string
 )
 
 // migrateCmd represents the migrate command
@@ -24,45 +35,159 @@ var migrateCmd = &cobra.Command{
 	Short: "Run database migrations",
 	Long: `Run database migrations to update the schema.
 
-Migrations are SQL files in the migration/ directory:
+Migrations are SQL files in the migration/ directory, embedded into
+the binary at build time:
 - *.up.sql   = Apply migration
 - *.down.sql = Rollback migration
 
+Use --dir to read migrations from disk instead (local development).
+
+A checksum of each applied file is stored as well. If an applied
+migration has been edited since it ran, mlm refuses to continue until
+the file is restored or --repair accepts the new checksum.
+
+Only one runner migrates at a time: mlm takes a MySQL advisory lock
+first and waits up to --lock-timeout for it. If the lock can't be
+acquired it exits with status 3 without touching the schema.
+
+--dry-run prints the migrations that would run, in order, with their
+full SQL, and warns about destructive statements (DROP TABLE, DROP
+COLUMN, TRUNCATE). It runs the same checksum and ledger checks as a
+real run and reports when one would stop it. Nothing is executed.
+
+Applied versions are recorded in the schema_migrations table, so each
+migration only runs once.
+
+Databases migrated before schema_migrations existed have tables but an
+empty ledger. mlm refuses to migrate them rather than re-running every
+migration from 01. Adopt such a database with --baseline <version>,
+which records every migration up to and including <version> as applied
+without executing it, then run mlm migrate as usual. <version> is a
+full name (09_add_user_deletion) or just its number (09).
+
+Subcommands:
+  status  Show applied/pending state of every migration
+  create  Scaffold a new numbered migration pair
+  redo    Roll back and re-apply the last migration
+
 Examples:
   mlm migrate              # Run all pending migrations
-  mlm migrate --down       # Rollback last migration
-  mlm migrate --step 2     # Run next 2 migrations`,
+  mlm migrate --down       # Rollback last applied migration
+  mlm migrate --step 2     # Run next 2 pending migrations
+  mlm migrate --dir ./migration
+  mlm migrate --repair     # Accept edits to already-applied migrations
+  mlm migrate --dry-run    # Preview pending SQL without running it
+  mlm migrate --baseline 09  # Adopt a database already at 09 without running it
+  mlm migrate status
+  mlm migrate create add_room_topic
+  mlm migrate redo`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runMigrations()
 	},
 }
 
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show migration status",
+	Long: `List every migration in the migration/ directory with its
+applied/pending state and the time it was applied.
+
+Examples:
+  mlm migrate status`,
+	Run: func(cmd *cobra.Command, args []string) {
+		showMigrationStatus()
+	},
+}
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new migration",
+	Long: `Scaffold the next numbered NN_name.up.sql / NN_name.down.sql pair
+in the migration/ directory (or --dir). Rebuild mlm to embed it.
+
+Examples:
+  mlm migrate create add_room_topic`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		createMigration(args[0])
+	},
+}
+
+var migrateRedoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Roll back and re-apply the last migration",
+	Long: `Roll back the most recently applied migration and apply it again.
+
+Useful while iterating on a migration in development.
+
+Examples:
+  mlm migrate redo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		redoMigration()
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateCreateCmd)
+	migrateCmd.AddCommand(migrateRedoCmd)
 
 	migrateCmd.Flags().BoolVar(&migrateDown, "down", false, "Rollback migrations")
 	migrateCmd.Flags().IntVar(&migrateStep, "step", 0, "Number of migrations to run (0 = all)")
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the migrations and SQL that would run without executing them")
+	migrateCmd.Flags().StringVar(&migrateBaseline, "baseline", "", "Record migrations up to and including this version as applied without running them")
+	migrateCmd.PersistentFlags().StringVar(&migrateDir, "dir", "", "Read migrations from this directory instead of the embedded files")
+	migrateCmd.PersistentFlags().BoolVar(&migrateRepair, "repair", false, "Accept the current checksum of edited, already-applied migrations")
+	migrateCmd.PersistentFlags().DurationVar(&migrateLockTimeout, "lock-timeout", 60*time.Second, "How long to wait for another migration runner to finish")
+}
+
+// defaultMigrationDir is where migration files live in the repo
+const defaultMigrationDir = "migration"
+
+// migrationFS returns the --dir override when set, otherwise the migrations
+// embedded in the binary
+func migrationFS() fs.FS {
+	if migrateDir != "" {
+		return os.DirFS(migrateDir)
+	}
+	return migration.FS
 }
 
 func runMigrations() {
 	log.Println("🔄 Running database migrations...")
 
-	// Get database configuration
-	dbHost := getEnv("MUSICAPP_PG_HOST", "127.0.0.1")
-	dbPort := getEnv("MUSICAPP_PG_PORT", "3306")
-	dbUser := getEnv("MUSICAPP_PG_USER", "user")
-	dbPass := getEnv("MUSICAPP_PG_PASS", "password")
-	dbName := getEnv("MUSICAPP_PG_DATABASE", "mlm")
+	if migrateBaseline != "" && (migrateDown || migrateDryRun) {
+		log.Fatalf("❌ --baseline can't be combined with --down or --dry-run")
+	}
 
-	// Connect to database
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&multiStatements=true",
-		dbUser, dbPass, dbHost, dbPort, dbName)
+	db := connectMigrationDB()
+	defer db.Close()
+
+	if migrateDryRun {
+		printMigrationPlan(db, migrationFS())
+		return
+	}
+
+	release := acquireMigrationLock(db, migrateLockTimeout)
+	defer release()
+
+	if migrateBaseline != "" {
+		runBaseline(db, migrationFS(), migrateBaseline)
+	} else if migrateDown {
+		runDownMigrations(db, migrationFS())
+	} else {
+		runUpMigrations(db, migrationFS())
+	}
+}
 
-	db, err := sql.Open("mysql", dsn)
+// connectMigrationDB connects with multiStatements enabled so a migration
+// file can hold several statements
+func connectMigrationDB() *sql.DB {
+	db, err := sql.Open("mysql", cfg.Database.DSN("multiStatements=true"))
 	if err != nil {
 		log.Fatalf("❌ Failed to connect to database: %v", err)
 	}
-	defer db.Close()
 
 	if err := db.Ping(); err != nil {
 		log.Fatalf("❌ Failed to ping database: %v", err)
@@ -70,65 +195,68 @@ func runMigrations() {
 
 	log.Println("✅ Connected to database")
 
-	// Get migration files
-	migrationDir := "migration"
-	if migrateDown {
-		runDownMigrations(db, migrationDir)
-	} else {
-		runUpMigrations(db, migrationDir)
-	}
+	return db
 }
 
-func runUpMigrations(db *sql.DB, dir string) {
-	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+func runUpMigrations(db *sql.DB, fsys fs.FS) {
+	if err := createMigrationTable(db); err != nil {
+		log.Fatalf("❌ Failed to create schema_migrations table: %v", err)
+	}
+	checkMigrationDrift(db, fsys)
+
+	files, err := fs.Glob(fsys, "*.up.sql")
 	if err != nil {
 		log.Fatalf("❌ Failed to read migration files: %v", err)
 	}
 
-	sort.Strings(files)
-
 	if len(files) == 0 {
 		log.Println("ℹ️  No migration files found")
 		return
 	}
 
-	count := 0
-	for _, file := range files {
-		if migrateStep > 0 && count >= migrateStep {
-			break
-		}
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to read applied migrations: %v", err)
+	}
 
-		log.Printf("📄 Running: %s", filepath.Base(file))
+	if len(applied) == 0 {
+		refuseUntrackedSchema(db)
+	}
 
-		content, err := os.ReadFile(file)
-		if err != nil {
-			log.Fatalf("❌ Failed to read %s: %v", file, err)
-		}
+	pending := pendingMigrations(files, applied)
 
-		// Execute migration
-		_, err = db.Exec(string(content))
-		if err != nil {
-			log.Fatalf("❌ Migration failed for %s: %v", file, err)
+	if len(pending) == 0 {
+		log.Println("✅ Database is up to date")
+		return
+	}
+
+	count := 0
+	for _, version := range pending {
+		if migrateStep > 0 && count >= migrateStep {
+			break
 		}
 
-		log.Printf("✅ Applied: %s", filepath.Base(file))
+		applyMigration(db, fsys, version)
 		count++
 	}
 
 	log.Printf("🎉 Successfully applied %d migration(s)", count)
 }
 
-func runDownMigrations(db *sql.DB, dir string) {
-	files, err := filepath.Glob(filepath.Join(dir, "*.down.sql"))
-	if err != nil {
-		log.Fatalf("❌ Failed to read migration files: %v", err)
+func runDownMigrations(db *sql.DB, fsys fs.FS) {
+	if err := createMigrationTable(db); err != nil {
+		log.Fatalf("❌ Failed to create schema_migrations table: %v", err)
 	}
+	checkMigrationDrift(db, fsys)
 
-	// Sort in reverse order for rollback
-	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	// Most recently applied first
+	versions, err := getAppliedVersionsDesc(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to read applied migrations: %v", err)
+	}
 
-	if len(files) == 0 {
-		log.Println("ℹ️  No rollback files found")
+	if len(versions) == 0 {
+		log.Println("ℹ️  No applied migrations to roll back")
 		return
 	}
 
@@ -138,41 +266,321 @@ func runDownMigrations(db *sql.DB, dir string) {
 		limit = 1 // Default: rollback only last migration
 	}
 
-	for _, file := range files {
+	for _, version := range versions {
 		if count >= limit {
 			break
 		}
 
-		log.Printf("📄 Rolling back: %s", filepath.Base(file))
+		rollbackMigration(db, fsys, version)
+		count++
+	}
 
-		content, err := os.ReadFile(file)
-		if err != nil {
-			log.Fatalf("❌ Failed to read %s: %v", file, err)
+	log.Printf("🎉 Successfully rolled back %d migration(s)", count)
+}
+
+// pendingMigrations returns the versions of files not yet in the ledger, in
+// order. Only these count towards --step.
+func pendingMigrations(files []string, applied map[string]appliedMigration) []string {
+	sort.Strings(files)
+
+	var pending []string
+	for _, file := range files {
+		version := getMigrationName(file)
+		if _, ok := applied[version]; !ok {
+			pending = append(pending, version)
 		}
+	}
+	return pending
+}
 
-		// Execute rollback
-		_, err = db.Exec(string(content))
+// baselineMigrations returns the pending versions up to and including
+// target, which is either a full version or just its number
+func baselineMigrations(files []string, applied map[string]appliedMigration, target string) ([]string, error) {
+	sort.Strings(files)
+
+	end := -1
+	for i, file := range files {
+		if matchesMigrationVersion(getMigrationName(file), target) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("no migration matches %q", target)
+	}
+
+	return pendingMigrations(files[:end+1], applied), nil
+}
+
+// matchesMigrationVersion reports whether target names version, either in
+// full or by its number, so "9" and "09" both match 09_add_user_deletion
+func matchesMigrationVersion(version, target string) bool {
+	if version == target {
+		return true
+	}
+
+	prefix, _, _ := strings.Cut(version, "_")
+	n, err := strconv.Atoi(prefix)
+	if err != nil {
+		return false
+	}
+	m, err := strconv.Atoi(target)
+	return err == nil && n == m
+}
+
+// runBaseline records the migrations up to and including target as applied
+// without executing them, adopting a database whose schema predates the
+// ledger
+func runBaseline(db *sql.DB, fsys fs.FS, target string) {
+	if err := createMigrationTable(db); err != nil {
+		log.Fatalf("❌ Failed to create schema_migrations table: %v", err)
+	}
+
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		log.Fatalf("❌ Failed to read migration files: %v", err)
+	}
+
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to read applied migrations: %v", err)
+	}
+
+	versions, err := baselineMigrations(files, applied, target)
+	if err != nil {
+		log.Fatalf("❌ Invalid --baseline: %v", err)
+	}
+
+	if len(versions) == 0 {
+		log.Printf("✅ Migrations up to %s are already recorded", target)
+		return
+	}
+
+	for _, version := range versions {
+		content, err := fs.ReadFile(fsys, version+".up.sql")
 		if err != nil {
+			log.Fatalf("❌ Failed to read %s.up.sql: %v", version, err)
+		}
+
+		if err := recordMigration(db, version, migrationChecksum(content)); err != nil {
+			log.Fatalf("❌ Failed to record migration %s: %v", version, err)
+		}
+		log.Printf("📌 Baselined: %s", version)
+	}
+
+	log.Printf("🎉 Recorded %d migration(s) without running them", len(versions))
+}
+
+// applyMigration runs a single up migration and records its version
+func applyMigration(db *sql.DB, fsys fs.FS, version string) {
+	file := version + ".up.sql"
+	log.Printf("📄 Running: %s", file)
+
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		log.Fatalf("❌ Failed to read %s: %v", file, err)
+	}
+
+	// Execute migration. MySQL rejects a comment-only file as an empty query.
+	if hasSQLStatements(string(content)) {
+		if _, err := db.Exec(string(content)); err != nil {
+			log.Fatalf("❌ Migration failed for %s: %v", file, err)
+		}
+	} else {
+		log.Printf("ℹ️  %s has no statements, recording it only", file)
+	}
+
+	// Record version (MySQL DDL auto-commits, so this can't share a transaction)
+	if err := recordMigration(db, version, migrationChecksum(content)); err != nil {
+		log.Fatalf("❌ Failed to record migration %s: %v", version, err)
+	}
+
+	log.Printf("✅ Applied: %s", file)
+}
+
+// hasSQLStatements reports whether content holds anything besides comments,
+// whitespace and bare semicolons
+func hasSQLStatements(content string) bool {
+	inBlockComment := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.Trim(stripSQLComments(line, &inBlockComment), " \t\r;") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// rollbackMigration runs a single down migration and removes its version
+func rollbackMigration(db *sql.DB, fsys fs.FS, version string) {
+	file := version + ".down.sql"
+	log.Printf("📄 Rolling back: %s", file)
+
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		log.Fatalf("❌ Failed to read %s: %v", file, err)
+	}
+
+	// Execute rollback
+	if hasSQLStatements(string(content)) {
+		if _, err := db.Exec(string(content)); err != nil {
 			log.Fatalf("❌ Rollback failed for %s: %v", file, err)
 		}
+	} else {
+		log.Printf("ℹ️  %s has no statements, removing its record only", file)
+	}
 
-		log.Printf("✅ Rolled back: %s", filepath.Base(file))
-		count++
+	if err := removeMigration(db, version); err != nil {
+		log.Fatalf("❌ Failed to remove migration record %s: %v", version, err)
 	}
 
-	log.Printf("🎉 Successfully rolled back %d migration(s)", count)
+	log.Printf("✅ Rolled back: %s", file)
+}
+
+func showMigrationStatus() {
+	db := connectMigrationDB()
+	defer db.Close()
+
+	if err := createMigrationTable(db); err != nil {
+		log.Fatalf("❌ Failed to create schema_migrations table: %v", err)
+	}
+
+	fsys := migrationFS()
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		log.Fatalf("❌ Failed to read migration files: %v", err)
+	}
+	sort.Strings(files)
+
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to read applied migrations: %v", err)
+	}
+
+	fmt.Println("📋 Migration Status:")
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  VERSION\tSTATE\tAPPLIED AT")
+
+	pending := 0
+	seen := make(map[string]bool)
+	for _, file := range files {
+		version := getMigrationName(file)
+		seen[version] = true
+
+		m, ok := applied[version]
+		if ok {
+			state := "✅ applied"
+			if content, err := fs.ReadFile(fsys, file); err == nil && m.Checksum.Valid && m.Checksum.String != migrationChecksum(content) {
+				state = "✏️  modified"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", version, state, m.AppliedAt.Format(time.RFC3339))
+		} else {
+			fmt.Fprintf(w, "  %s\t⏳ pending\t-\n", version)
+			pending++
+		}
+	}
+
+	// Versions recorded in the ledger whose files are gone
+	var missing []string
+	for version := range applied {
+		if !seen[version] {
+			missing = append(missing, version)
+		}
+	}
+	sort.Strings(missing)
+	for _, version := range missing {
+		fmt.Fprintf(w, "  %s\t⚠️  missing file\t%s\n", version, applied[version].AppliedAt.Format(time.RFC3339))
+	}
+	w.Flush()
+
+	fmt.Println()
+	fmt.Printf("%d applied, %d pending\n", len(applied), pending)
 }
 
-// Migration tracking table (optional - for future enhancement)
-func createMigrationTable(db *sql.DB) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version VARCHAR(255) PRIMARY KEY,
-			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`
-	_, err := db.Exec(query)
-	return err
+// createMigration scaffolds the next NN_name.up.sql / NN_name.down.sql pair
+func createMigration(name string) {
+	name = sanitizeMigrationName(name)
+	if name == "" {
+		log.Fatalf("❌ Migration name must contain letters or digits")
+	}
+
+	// New files are always written to disk
+	dir := migrateDir
+	if dir == "" {
+		dir = defaultMigrationDir
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		log.Fatalf("❌ Failed to read migration files: %v", err)
+	}
+
+	next := 1
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(getMigrationName(file), "_")
+		if n, err := strconv.Atoi(prefix); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	version := fmt.Sprintf("%02d_%s", next, name)
+	for _, suffix := range []string{".up.sql", ".down.sql"} {
+		file := filepath.Join(dir, version+suffix)
+		// DO 0 is a no-op, so the pair runs cleanly until it's filled in
+		content := fmt.Sprintf("-- %s%s\n-- Replace DO 0 with this migration's statements\nDO 0;\n", version, suffix)
+
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			log.Fatalf("❌ Failed to create %s: %v", file, err)
+		}
+		log.Printf("✅ Created: %s", file)
+	}
+}
+
+// redoMigration rolls back the last applied migration and applies it again
+func redoMigration() {
+	log.Println("🔁 Redoing last migration...")
+
+	db := connectMigrationDB()
+	defer db.Close()
+
+	release := acquireMigrationLock(db, migrateLockTimeout)
+	defer release()
+
+	if err := createMigrationTable(db); err != nil {
+		log.Fatalf("❌ Failed to create schema_migrations table: %v", err)
+	}
+
+	fsys := migrationFS()
+	checkMigrationDrift(db, fsys)
+
+	versions, err := getAppliedVersionsDesc(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to read applied migrations: %v", err)
+	}
+
+	if len(versions) == 0 {
+		log.Println("ℹ️  No applied migrations to redo")
+		return
+	}
+
+	rollbackMigration(db, fsys, versions[0])
+	applyMigration(db, fsys, versions[0])
+
+	log.Printf("🎉 Successfully redid %s", versions[0])
+}
+
+// sanitizeMigrationName lowercases name and replaces anything other than
+// letters and digits with underscores
+func sanitizeMigrationName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return strings.Trim(b.String(), "_")
 }
 
 func getMigrationName(filePath string) string {
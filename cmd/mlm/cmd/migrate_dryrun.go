@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// destructivePatterns flag statements that lose data when run against a
+// populated database
+var destructivePatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"DROP TABLE", regexp.MustCompile(`(?i)\bDROP\s+TABLE\b`)},
+	{"DROP COLUMN", regexp.MustCompile(`(?i)\bDROP\s+COLUMN\b`)},
+	{"TRUNCATE", regexp.MustCompile(`(?i)\bTRUNCATE\b`)},
+}
+
+// destructiveStatement is a line of a migration matching a destructive pattern
+type destructiveStatement struct {
+	Line int
+	Kind string
+	Text string
+}
+
+// findDestructiveStatements scans SQL for destructive statements, ignoring
+// anything inside comments
+func findDestructiveStatements(content string) []destructiveStatement {
+	var found []destructiveStatement
+
+	inBlockComment := false
+	for i, line := range strings.Split(content, "\n") {
+		code := stripSQLComments(line, &inBlockComment)
+
+		for _, p := range destructivePatterns {
+			if p.re.MatchString(code) {
+				found = append(found, destructiveStatement{
+					Line: i + 1,
+					Kind: p.kind,
+					Text: strings.TrimSpace(line),
+				})
+			}
+		}
+	}
+
+	return found
+}
+
+// stripSQLComments removes --, # and /* */ comments from a single line,
+// tracking block comments that span lines. Quoted strings are not parsed,
+// which is good enough for DDL migrations.
+func stripSQLComments(line string, inBlockComment *bool) string {
+	var b strings.Builder
+
+	for i := 0; i < len(line); i++ {
+		if *inBlockComment {
+			if strings.HasPrefix(line[i:], "*/") {
+				*inBlockComment = false
+				i++
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line[i:], "/*"):
+			*inBlockComment = true
+			i++
+		case strings.HasPrefix(line[i:], "--"), line[i] == '#':
+			return b.String()
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+
+	return b.String()
+}
+
+// printMigrationPlan prints the migrations `mlm migrate` would run with the
+// current flags, their full SQL and any destructive statements, without
+// executing or recording anything. It runs the same checks as a real run
+// and reports when one would stop it.
+func printMigrationPlan(db *sql.DB, fsys fs.FS) {
+	applied := map[string]appliedMigration{}
+
+	exists, err := migrationTableExists(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to check schema_migrations table: %v", err)
+	}
+	if exists {
+		// A real run upgrades a ledger that lacks checksums before reading it
+		hasChecksum, err := ledgerHasChecksum(db)
+		if err != nil {
+			log.Fatalf("❌ Failed to check schema_migrations table: %v", err)
+		}
+		if hasChecksum {
+			applied, err = getAppliedMigrations(db)
+		} else {
+			applied, err = getLegacyAppliedMigrations(db)
+		}
+		if err != nil {
+			log.Fatalf("❌ Failed to read applied migrations: %v", err)
+		}
+	}
+
+	fmt.Println("🔍 Dry run - nothing will be executed")
+	fmt.Println()
+
+	if !reportMigrationDrift(fsys, applied) {
+		return
+	}
+
+	if !migrateDown && len(applied) == 0 {
+		tables, err := untrackedTables(db)
+		if err != nil {
+			log.Fatalf("❌ Failed to list existing tables: %v", err)
+		}
+		if len(tables) > 0 {
+			printUntrackedSchema(os.Stdout, tables)
+			fmt.Println("❌ mlm migrate would refuse to run: the database has no recorded migrations")
+			return
+		}
+	}
+
+	var files []string
+	if migrateDown {
+		var versions []string
+		if exists {
+			versions, err = getAppliedVersionsDesc(db)
+			if err != nil {
+				log.Fatalf("❌ Failed to read applied migrations: %v", err)
+			}
+		}
+
+		limit := migrateStep
+		if limit == 0 {
+			limit = 1 // Default: rollback only last migration
+		}
+		for i, version := range versions {
+			if i >= limit {
+				break
+			}
+			files = append(files, version+".down.sql")
+		}
+	} else {
+		upFiles, err := fs.Glob(fsys, "*.up.sql")
+		if err != nil {
+			log.Fatalf("❌ Failed to read migration files: %v", err)
+		}
+
+		for i, version := range pendingMigrations(upFiles, applied) {
+			if migrateStep > 0 && i >= migrateStep {
+				break
+			}
+			files = append(files, version+".up.sql")
+		}
+	}
+
+	if len(files) == 0 {
+		fmt.Println("ℹ️  No migrations would run")
+		return
+	}
+
+	fmt.Printf("📋 %d migration(s) would run, in order:\n", len(files))
+	for i, file := range files {
+		fmt.Printf("  %d. %s\n", i+1, file)
+	}
+
+	warnings := 0
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			log.Fatalf("❌ Failed to read %s: %v", file, err)
+		}
+
+		fmt.Println()
+		fmt.Printf("==> %s\n", file)
+		fmt.Println(strings.TrimRight(string(content), "\n"))
+
+		for _, stmt := range findDestructiveStatements(string(content)) {
+			fmt.Printf("⚠️  %s at %s:%d: %s\n", stmt.Kind, file, stmt.Line, stmt.Text)
+			warnings++
+		}
+	}
+
+	fmt.Println()
+	if warnings > 0 {
+		fmt.Printf("⚠️  %d destructive statement(s) found - review before running against a populated database\n", warnings)
+	} else {
+		fmt.Println("✅ No destructive statements found")
+	}
+}
+
+// reportMigrationDrift prints what the drift check of a real run would do.
+// It returns false when that check would stop the run.
+func reportMigrationDrift(fsys fs.FS, applied map[string]appliedMigration) bool {
+	drifted, unrecorded := findMigrationDrift(fsys, applied)
+
+	for _, u := range unrecorded {
+		fmt.Printf("📝 Would record checksum for %s\n", u.Version)
+	}
+
+	if len(drifted) == 0 {
+		return true
+	}
+
+	if migrateRepair {
+		for _, d := range drifted {
+			fmt.Printf("🔧 Would accept new checksum for %s\n", d.Version)
+		}
+		return true
+	}
+
+	printMigrationDrift(os.Stdout, drifted)
+	fmt.Printf("❌ mlm migrate would refuse to run: %d applied migration(s) changed; restore the file(s) or re-run with --repair\n", len(drifted))
+	return false
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDestructiveStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected []destructiveStatement
+	}{
+		{
+			name: "success-flags-drop-table",
+			sql:  "DROP TABLE IF EXISTS users;\nCREATE TABLE users (id INT);",
+			expected: []destructiveStatement{
+				{Line: 1, Kind: "DROP TABLE", Text: "DROP TABLE IF EXISTS users;"},
+			},
+		},
+		{
+			name: "success-flags-drop-column-and-truncate",
+			sql:  "ALTER TABLE users\n  drop column email;\ntruncate table rooms;",
+			expected: []destructiveStatement{
+				{Line: 2, Kind: "DROP COLUMN", Text: "drop column email;"},
+				{Line: 3, Kind: "TRUNCATE", Text: "truncate table rooms;"},
+			},
+		},
+		{
+			name: "success-ignores-comments",
+			sql:  "-- DROP COLUMN email first\n# TRUNCATE users\n/* DROP TABLE\nrooms */\nALTER TABLE users ADD COLUMN email VARCHAR(255);",
+		},
+		{
+			name: "success-no-destructive-statements",
+			sql:  "CREATE TABLE rooms (id INT);",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, findDestructiveStatements(tt.sql))
+		})
+	}
+}
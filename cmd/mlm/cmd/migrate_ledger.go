@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// appliedMigration is a row of the schema_migrations ledger
+type appliedMigration struct {
+	AppliedAt time.Time
+	Checksum  sql.NullString // NULL for versions applied before checksums were tracked
+}
+
+// migrationDrift describes an applied migration whose file has since changed
+type migrationDrift struct {
+	Version   string
+	AppliedAt time.Time
+	Recorded  string
+	Current   string
+}
+
+// createMigrationTable creates the schema_migrations ledger if it doesn't
+// exist, and upgrades one created before checksums were tracked
+func createMigrationTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			checksum CHAR(64) NULL
+		)
+	`
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+
+	hasChecksum, err := ledgerHasChecksum(db)
+	if err != nil || hasChecksum {
+		return err
+	}
+	_, err = db.Exec("ALTER TABLE schema_migrations ADD COLUMN checksum CHAR(64) NULL")
+	return err
+}
+
+// ledgerHasChecksum reports whether schema_migrations has the checksum
+// column. Ledgers created before checksums were tracked lack it.
+func ledgerHasChecksum(db *sql.DB) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE()
+		  AND TABLE_NAME = 'schema_migrations'
+		  AND COLUMN_NAME = 'checksum'
+	`).Scan(&count)
+	return count > 0, err
+}
+
+// migrationTableExists reports whether the schema_migrations ledger exists
+func migrationTableExists(db *sql.DB) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE()
+		  AND TABLE_NAME = 'schema_migrations'
+	`).Scan(&count)
+	return count > 0, err
+}
+
+// untrackedTables returns the tables in the current database other than the
+// schema_migrations ledger
+func untrackedTables(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE()
+		  AND TABLE_NAME <> 'schema_migrations'
+		ORDER BY TABLE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+// refuseUntrackedSchema stops an up run against a database that has tables
+// but an empty ledger. Such a database was migrated before schema_migrations
+// existed, and replaying every migration from 01 would fail part way or,
+// worse, drop and recreate tables holding data.
+func refuseUntrackedSchema(db *sql.DB) {
+	tables, err := untrackedTables(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to list existing tables: %v", err)
+	}
+	if len(tables) == 0 {
+		return
+	}
+
+	printUntrackedSchema(os.Stderr, tables)
+	log.Fatalf("❌ Refusing to migrate a database with no recorded migrations")
+}
+
+// printUntrackedSchema explains how to adopt a database whose tables predate
+// the ledger
+func printUntrackedSchema(w io.Writer, tables []string) {
+	fmt.Fprintf(w, "The database already has tables (%s) but schema_migrations is empty.\n", strings.Join(tables, ", "))
+	fmt.Fprintln(w, "It was probably migrated before the ledger existed. Record the migrations")
+	fmt.Fprintln(w, "it already has without running them, then migrate as usual:")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  mlm migrate --baseline <version>")
+	fmt.Fprintln(w, "  mlm migrate")
+	fmt.Fprintln(w)
+}
+
+// getAppliedMigrations returns the ledger keyed by version
+func getAppliedMigrations(db *sql.DB) (map[string]appliedMigration, error) {
+	return queryAppliedMigrations(db, "checksum")
+}
+
+// getLegacyAppliedMigrations reads a ledger that predates the checksum
+// column without upgrading it; every checksum comes back NULL
+func getLegacyAppliedMigrations(db *sql.DB) (map[string]appliedMigration, error) {
+	return queryAppliedMigrations(db, "NULL")
+}
+
+func queryAppliedMigrations(db *sql.DB, checksumExpr string) (map[string]appliedMigration, error) {
+	rows, err := db.Query("SELECT version, applied_at, " + checksumExpr + " FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]appliedMigration)
+	for rows.Next() {
+		var version string
+		var m appliedMigration
+		if err := rows.Scan(&version, &m.AppliedAt, &m.Checksum); err != nil {
+			return nil, err
+		}
+		applied[version] = m
+	}
+
+	return applied, rows.Err()
+}
+
+// getAppliedVersionsDesc returns applied versions, most recently applied first
+func getAppliedVersionsDesc(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT version FROM schema_migrations ORDER BY applied_at DESC, version DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func recordMigration(db *sql.DB, version, checksum string) error {
+	_, err := db.Exec("INSERT INTO schema_migrations (version, checksum) VALUES (?, ?)", version, checksum)
+	return err
+}
+
+func updateMigrationChecksum(db *sql.DB, version, checksum string) error {
+	_, err := db.Exec("UPDATE schema_migrations SET checksum = ? WHERE version = ?", checksum, version)
+	return err
+}
+
+func removeMigration(db *sql.DB, version string) error {
+	_, err := db.Exec("DELETE FROM schema_migrations WHERE version = ?", version)
+	return err
+}
+
+// migrationChecksum returns the hex SHA-256 of a migration file
+func migrationChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// findMigrationDrift compares every applied migration against its file. It
+// returns the versions edited after they ran, and those applied before
+// checksums were tracked, which adopt the current checksum.
+func findMigrationDrift(fsys fs.FS, applied map[string]appliedMigration) (drifted, unrecorded []migrationDrift) {
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	for _, version := range versions {
+		m := applied[version]
+
+		content, err := fs.ReadFile(fsys, version+".up.sql")
+		if err != nil {
+			continue // Missing files are reported by `mlm migrate status`
+		}
+
+		d := migrationDrift{
+			Version:   version,
+			AppliedAt: m.AppliedAt,
+			Recorded:  m.Checksum.String,
+			Current:   migrationChecksum(content),
+		}
+		switch {
+		case !m.Checksum.Valid:
+			unrecorded = append(unrecorded, d)
+		case d.Recorded != d.Current:
+			drifted = append(drifted, d)
+		}
+	}
+
+	return drifted, unrecorded
+}
+
+// printMigrationDrift shows each drifted migration as a checksum diff
+func printMigrationDrift(w io.Writer, drifted []migrationDrift) {
+	fmt.Fprintln(w, "Applied migrations were edited after they ran:")
+	fmt.Fprintln(w)
+	for _, d := range drifted {
+		fmt.Fprintf(w, "--- %s.up.sql (applied %s)\n", d.Version, d.AppliedAt.Format(time.RFC3339))
+		fmt.Fprintf(w, "+++ %s.up.sql (current file)\n", d.Version)
+		fmt.Fprintf(w, "- sha256 %s\n", d.Recorded)
+		fmt.Fprintf(w, "+ sha256 %s\n", d.Current)
+		fmt.Fprintln(w)
+	}
+}
+
+// checkMigrationDrift refuses to continue when an applied migration was
+// edited after it ran, unless --repair is set. Versions applied before
+// checksums were tracked adopt the current one.
+func checkMigrationDrift(db *sql.DB, fsys fs.FS) {
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to read applied migrations: %v", err)
+	}
+
+	drifted, unrecorded := findMigrationDrift(fsys, applied)
+
+	for _, u := range unrecorded {
+		if err := updateMigrationChecksum(db, u.Version, u.Current); err != nil {
+			log.Fatalf("❌ Failed to record checksum for %s: %v", u.Version, err)
+		}
+		log.Printf("📝 Recorded checksum for %s", u.Version)
+	}
+
+	if len(drifted) == 0 {
+		return
+	}
+
+	if migrateRepair {
+		for _, d := range drifted {
+			if err := updateMigrationChecksum(db, d.Version, d.Current); err != nil {
+				log.Fatalf("❌ Failed to repair checksum for %s: %v", d.Version, err)
+			}
+			log.Printf("🔧 Accepted new checksum for %s", d.Version)
+		}
+		return
+	}
+
+	printMigrationDrift(os.Stderr, drifted)
+	log.Fatalf("❌ %d applied migration(s) changed; restore the file(s) or re-run with --repair", len(drifted))
+}
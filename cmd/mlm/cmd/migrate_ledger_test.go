@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMigrationDrift(t *testing.T) {
+	fsys := fstest.MapFS{
+		"01_create_users.up.sql":    {Data: []byte("CREATE TABLE users (id INT);")},
+		"02_create_rooms.up.sql":    {Data: []byte("CREATE TABLE rooms (id INT, name TEXT);")},
+		"03_add_room_topic.up.sql":  {Data: []byte("ALTER TABLE rooms ADD COLUMN topic TEXT;")},
+		"04_create_members.up.sql":  {Data: []byte("CREATE TABLE room_members (id INT);")},
+		"05_not_yet_applied.up.sql": {Data: []byte("DO 0;")},
+	}
+	checksum := func(file string) string {
+		return migrationChecksum(fsys[file].Data)
+	}
+
+	applied := map[string]appliedMigration{
+		"01_create_users":   {Checksum: sql.NullString{String: checksum("01_create_users.up.sql"), Valid: true}},
+		"02_create_rooms":   {Checksum: sql.NullString{String: migrationChecksum([]byte("CREATE TABLE rooms (id INT);")), Valid: true}},
+		"03_add_room_topic": {},
+		"04_create_members": {},
+		"00_deleted_file":   {Checksum: sql.NullString{String: "gone", Valid: true}},
+	}
+
+	drifted, unrecorded := findMigrationDrift(fsys, applied)
+
+	assert.Equal(t, []migrationDrift{{
+		Version:  "02_create_rooms",
+		Recorded: migrationChecksum([]byte("CREATE TABLE rooms (id INT);")),
+		Current:  checksum("02_create_rooms.up.sql"),
+	}}, drifted)
+	assert.Equal(t, []migrationDrift{
+		{Version: "03_add_room_topic", Current: checksum("03_add_room_topic.up.sql")},
+		{Version: "04_create_members", Current: checksum("04_create_members.up.sql")},
+	}, unrecorded)
+}
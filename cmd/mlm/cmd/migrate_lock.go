@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"math"
+	"os"
+	"time"
+)
+
+// migrationLockName is the MySQL named lock shared by every migration runner
+const migrationLockName = "mlm_schema_migrations"
+
+// exitMigrationLocked is the exit status when another runner holds the lock
+const exitMigrationLocked = 3
+
+// acquireMigrationLock takes the migration advisory lock, waiting up to
+// timeout (negative waits forever). If the lock can't be acquired the
+// process exits with exitMigrationLocked.
+//
+// GET_LOCK is held by the session, so a dedicated connection is kept open
+// until the returned release func runs. If the process dies first, MySQL
+// drops the lock along with the connection.
+func acquireMigrationLock(db *sql.DB, timeout time.Duration) (release func()) {
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to open lock connection: %v", err)
+	}
+
+	seconds := -1
+	if timeout >= 0 {
+		seconds = int(math.Ceil(timeout.Seconds()))
+	}
+
+	log.Printf("🔒 Acquiring migration lock %q (timeout: %v)...", migrationLockName, timeout)
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, seconds).Scan(&acquired)
+	if err != nil {
+		conn.Close()
+		log.Fatalf("❌ Failed to acquire migration lock: %v", err)
+	}
+
+	if !acquired.Valid || acquired.Int64 != 1 {
+		conn.Close()
+		log.Printf("⛔ Another migration runner holds %q; giving up after %v", migrationLockName, timeout)
+		os.Exit(exitMigrationLocked)
+	}
+
+	log.Println("✅ Migration lock acquired")
+
+	return func() {
+		var released sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName).Scan(&released); err != nil {
+			log.Printf("⚠️  Failed to release migration lock: %v", err)
+		}
+		conn.Close()
+	}
+}
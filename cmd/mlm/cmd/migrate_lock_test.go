@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/testsuite"
+)
+
+// lockHelperEnv makes the test binary act as a second migration runner;
+// see TestMigrationLockHelper
+const lockHelperEnv = "MLM_MIGRATION_LOCK_HELPER"
+
+// lockTestDB connects to the test database
+func lockTestDB(t *testing.T) *sql.DB {
+	testSuite := testsuite.New(t)
+	t.Cleanup(testSuite.UseBackendDB())
+	return testSuite.BackendAppDb().(*sql.DB)
+}
+
+// runLockHelper runs another migration runner in mode and returns its exit
+// status
+func runLockHelper(t *testing.T, mode string) int {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMigrationLockHelper$")
+	cmd.Env = append(os.Environ(), lockHelperEnv+"="+mode)
+	out, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	require.NoError(t, err, string(out))
+	return 0
+}
+
+// assertLockFree checks no session holds the migration lock
+func assertLockFree(t *testing.T, db *sql.DB) {
+	var free sql.NullInt64
+	require.NoError(t, db.QueryRow("SELECT IS_FREE_LOCK(?)", migrationLockName).Scan(&free))
+	assert.Equal(t, int64(1), free.Int64)
+}
+
+// TestMigrationLockHelper is the second runner started by runLockHelper. It
+// takes the lock without waiting, then either finishes or fails part way.
+func TestMigrationLockHelper(t *testing.T) {
+	switch os.Getenv(lockHelperEnv) {
+	case "":
+		t.Skip("only runs as a subprocess of TestAcquireMigrationLock")
+	case "migrate":
+		release := acquireMigrationLock(lockTestDB(t), 0)
+		release()
+	case "fail":
+		acquireMigrationLock(lockTestDB(t), 0)
+		log.Fatalf("❌ Migration failed")
+	}
+}
+
+func TestAcquireMigrationLock(t *testing.T) {
+	t.Run("error-exits-when-busy", func(t *testing.T) {
+		db := lockTestDB(t)
+		release := acquireMigrationLock(db, 0)
+		defer release()
+
+		assert.Equal(t, exitMigrationLocked, runLockHelper(t, "migrate"))
+	})
+
+	t.Run("success-release-frees-lock", func(t *testing.T) {
+		db := lockTestDB(t)
+		release := acquireMigrationLock(db, 0)
+		release()
+
+		assertLockFree(t, db)
+		assert.Equal(t, 0, runLockHelper(t, "migrate"))
+	})
+
+	t.Run("success-failed-run-frees-lock", func(t *testing.T) {
+		db := lockTestDB(t)
+
+		// The runner dies holding the lock; MySQL drops it with the session
+		assert.Equal(t, 1, runLockHelper(t, "fail"))
+		assertLockFree(t, db)
+	})
+}
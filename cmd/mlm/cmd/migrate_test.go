@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaselineMigrations(t *testing.T) {
+	files := []string{
+		"03_create_room_members.up.sql",
+		"01_create_users.up.sql",
+		"02_create_rooms.up.sql",
+		"10_enforce_single_active_membership.up.sql",
+	}
+
+	tests := []struct {
+		name     string
+		applied  map[string]appliedMigration
+		target   string
+		expected []string
+	}{
+		{
+			name:     "success-full-version",
+			target:   "02_create_rooms",
+			expected: []string{"01_create_users", "02_create_rooms"},
+		},
+		{
+			name:     "success-number-ignores-padding",
+			target:   "3",
+			expected: []string{"01_create_users", "02_create_rooms", "03_create_room_members"},
+		},
+		{
+			name:     "success-skips-recorded-versions",
+			applied:  map[string]appliedMigration{"01_create_users": {}},
+			target:   "02",
+			expected: []string{"02_create_rooms"},
+		},
+		{
+			name:    "success-nothing-left-to-record",
+			applied: map[string]appliedMigration{"01_create_users": {}},
+			target:  "01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, err := baselineMigrations(append([]string(nil), files...), tt.applied, tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, versions)
+		})
+	}
+
+	t.Run("error-unknown-version", func(t *testing.T) {
+		for _, target := range []string{"04", "create_users", ""} {
+			_, err := baselineMigrations(append([]string(nil), files...), nil, target)
+			assert.Error(t, err, target)
+		}
+	})
+}
+
+func TestHasSQLStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected bool
+	}{
+		{name: "success-statement", sql: "ALTER TABLE rooms ADD COLUMN topic VARCHAR(255);", expected: true},
+		{name: "success-scaffold-no-op", sql: "-- 11_add_room_topic.up.sql\n-- Replace DO 0 with this migration's statements\nDO 0;\n", expected: true},
+		{name: "success-statement-after-block-comment", sql: "/* add\ntopic */ ALTER TABLE rooms ADD COLUMN topic VARCHAR(255);", expected: true},
+		{name: "success-comments-only", sql: "-- 11_add_room_topic.up.sql\n# nothing yet\n/* still\nnothing */\n"},
+		{name: "success-blank-and-semicolons", sql: "\r\n  ;\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasSQLStatements(tt.sql))
+		})
+	}
+}
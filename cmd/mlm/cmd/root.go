@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/aarondl/sqlboiler/v4/boil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"mlm/internal"
+	"mlm/internal/config"
 )
 
 var (
 	cfgFile string
 	verbose bool
+
+	// cfg is the effective configuration, loaded before any command runs
+	cfg *config.Config
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -24,6 +31,7 @@ var rootCmd = &cobra.Command{
   - terraform:  Recreate and seed the database
   - di:         Generate dependency injection container
   - db:         Database operations (recreate schema)
+  - users:      User account operations (erase, export)
   - sqlboiler:  Generate SQLBoiler models
 
 Examples:
@@ -31,11 +39,14 @@ Examples:
   mlm migrate
   mlm terraform
   mlm di generate`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadConfig(cmd)
+	},
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -44,8 +55,16 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.mlm.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.imapp.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+
+	// Configuration overrides, highest precedence
+	rootCmd.PersistentFlags().String("db-host", "", "database host")
+	rootCmd.PersistentFlags().Int("db-port", 0, "database port")
+	rootCmd.PersistentFlags().String("db-user", "", "database user")
+	rootCmd.PersistentFlags().String("db-password", "", "database password")
+	rootCmd.PersistentFlags().String("db-name", "", "database name")
+	rootCmd.PersistentFlags().String("log-level", "", "log level (debug, info, warn, error)")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -67,3 +86,21 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
+
+// loadConfig resolves the typed configuration from flags, env, the config
+// file and docker/docker.env, in that order of precedence
+func loadConfig(cmd *cobra.Command) error {
+	loaded, err := config.Load(config.Options{
+		Flags:      cmd.Flags(),
+		ConfigFile: viper.ConfigFileUsed(),
+		DotenvFile: internal.DockerFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	cfg = loaded
+	boil.DebugMode = cfg.Log.Level == config.LogLevelDebug
+
+	return nil
+}
@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/spf13/cobra"
-)
 
-var (
-	port string
-	host string
+	"mlm/internal/musicapp/api"
+	"mlm/internal/musicapp/db/repo"
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/musicapp/lib/room_members"
+	roommemberstore "mlm/internal/musicapp/lib/room_members/store"
+	"mlm/internal/musicapp/lib/rooms"
+	roomstore "mlm/internal/musicapp/lib/rooms/store"
+	"mlm/internal/musicapp/lib/users"
+	userstore "mlm/internal/musicapp/lib/users/store"
 )
 
 // serveCmd represents the serve command
@@ -32,8 +36,12 @@ Environment Variables:
   MUSICAPP_PG_HOST      Database host (default: 127.0.0.1)
   MUSICAPP_PG_PORT      Database port (default: 3306)
   MUSICAPP_PG_USER      Database user (default: user)
-  MUSICAPP_PG_PASS      Database password (default: password)
-  MUSICAPP_PG_DATABASE  Database name (default: mlm)
+  MUSICAPP_PG_PASS      Database password (default: userpass)
+  MUSICAPP_PG_DATABASE  Database name (default: musicapp)
+  MUSICAPP_HTTP_HOST    Host to bind to (default: 0.0.0.0)
+  MUSICAPP_HTTP_PORT    Port to listen on (default: 8080)
+
+Run "mlm config" to see the effective values and where they came from.
 
 Examples:
   mlm serve
@@ -47,28 +55,18 @@ Examples:
 func init() {
 	rootCmd.AddCommand(serveCmd)
 
-	serveCmd.Flags().StringVarP(&port, "port", "p", "8080", "Port to listen on")
-	serveCmd.Flags().StringVarP(&host, "host", "H", "0.0.0.0", "Host to bind to")
+	serveCmd.Flags().IntP("port", "p", 8080, "Port to listen on")
+	serveCmd.Flags().StringP("host", "H", "0.0.0.0", "Host to bind to")
 }
 
 func runServer() {
 	log.Println("🚀 Starting Music Dating App API Server...")
 
-	// Get database configuration from environment
-	dbHost := getEnv("MUSICAPP_PG_HOST", "127.0.0.1")
-	dbPort := getEnv("MUSICAPP_PG_PORT", "3306")
-	dbUser := getEnv("MUSICAPP_PG_USER", "user")
-	dbPass := getEnv("MUSICAPP_PG_PASS", "userpass")
-	dbName := getEnv("MUSICAPP_PG_DATABASE", "musicapp")
-
-	// Build DSN
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		dbUser, dbPass, dbHost, dbPort, dbName)
-
-	log.Printf("📊 Connecting to database: %s@%s:%s/%s", dbUser, dbHost, dbPort, dbName)
+	log.Printf("📊 Connecting to database: %s@%s:%d/%s",
+		cfg.Database.User, cfg.Database.Host, cfg.Database.Port, cfg.Database.Name)
 
 	// Connect to MySQL
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", cfg.Database.DSN())
 	if err != nil {
 		log.Fatalf("❌ Failed to connect to database: %v", err)
 	}
@@ -81,18 +79,51 @@ func runServer() {
 
 	log.Println("✅ Database connected successfully")
 
-	// TODO: Initialize stores and handlers following IMAPP pattern
-	// userStore := userstore.New()
-	// userLogic := users.NewLogic(userStore)
-	// userHandler := userhandler.New(userLogic)
+	// Initialize logic and handlers following IMAPP pattern
+	accounts, err := newUserAccounts()
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize user accounts: %v", err)
+	}
+	userLogic, err := users.NewLogic(userstore.New(), repo.NewUserRepo(), txn.MySQL{})
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize users logic: %v", err)
+	}
+	membershipLogic, err := room_members.NewLogic(roommemberstore.New(), repo.NewRoomMember(), roomstore.New(), userstore.New(), txn.MySQL{})
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize room members logic: %v", err)
+	}
+	roomLogic, err := rooms.NewLogic(roomstore.New(), repo.NewRoomRepo(), userstore.New(), membershipLogic, txn.MySQL{})
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize rooms logic: %v", err)
+	}
+
+	userHandler := api.NewUserHandler(db, userLogic, accounts)
+	roomHandler := api.NewRoomHandler(db, roomLogic)
+	roomMemberHandler := api.NewRoomMemberHandler(db, roomLogic, membershipLogic)
 
 	// Setup routes
 	log.Println("🛣️  Setting up server...")
 	mux := http.NewServeMux()
 
-	// TODO: Register routes when handlers are implemented
-	// mux.HandleFunc("GET /users", userHandler.ListUsers)
-	// mux.HandleFunc("POST /users", userHandler.CreateUser)
+	// User routes
+	mux.HandleFunc("GET /users", userHandler.ListUsers)
+	mux.HandleFunc("GET /users/{id}", userHandler.GetUser)
+	mux.HandleFunc("POST /users", userHandler.CreateUser)
+	mux.HandleFunc("PATCH /users/{id}", userHandler.UpdateUser)
+	mux.HandleFunc("DELETE /users/{id}", userHandler.DeleteUser)
+	mux.HandleFunc("GET /users/{id}/export", userHandler.ExportUser)
+
+	// Room routes
+	mux.HandleFunc("GET /rooms", roomHandler.ListRooms)
+	mux.HandleFunc("GET /rooms/{id}", roomHandler.GetRoom)
+	mux.HandleFunc("POST /rooms", roomHandler.CreateRoom)
+	mux.HandleFunc("PATCH /rooms/{id}", roomHandler.UpdateRoom)
+
+	// Room membership routes
+	mux.HandleFunc("GET /rooms/{id}/members", roomMemberHandler.ListMembers)
+	mux.HandleFunc("GET /rooms/{id}/members/history", roomMemberHandler.MemberHistory)
+	mux.HandleFunc("POST /rooms/{id}/members", roomMemberHandler.JoinRoom)
+	mux.HandleFunc("DELETE /rooms/{id}/members/me", roomMemberHandler.LeaveRoom)
 
 	// Health check endpoint
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
@@ -103,10 +134,24 @@ func runServer() {
 	log.Println("✅ Server configured")
 
 	// Start server
-	addr := fmt.Sprintf("%s:%s", host, port)
+	addr := fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port)
 	log.Printf("🎵 Server listening on http://%s", addr)
 	log.Printf("📝 Available Endpoints:")
 	log.Printf("   - GET /health")
+	log.Printf("   - GET /users")
+	log.Printf("   - GET /users/{id}")
+	log.Printf("   - POST /users")
+	log.Printf("   - PATCH /users/{id}")
+	log.Printf("   - DELETE /users/{id}")
+	log.Printf("   - GET /users/{id}/export")
+	log.Printf("   - GET /rooms")
+	log.Printf("   - GET /rooms/{id}")
+	log.Printf("   - POST /rooms")
+	log.Printf("   - PATCH /rooms/{id}")
+	log.Printf("   - GET /rooms/{id}/members")
+	log.Printf("   - GET /rooms/{id}/members/history")
+	log.Printf("   - POST /rooms/{id}/members")
+	log.Printf("   - DELETE /rooms/{id}/members/me")
 	log.Printf("")
 	log.Printf("Press Ctrl+C to stop")
 
@@ -114,10 +159,3 @@ func runServer() {
 		log.Fatalf("❌ Server failed: %v", err)
 	}
 }
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"log"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -19,8 +20,15 @@ WARNING: This will DELETE ALL DATA!
 
 Use this for quick development reset.
 
+Migrations are embedded in the binary; use --dir to read them from disk.
+
+Like mlm migrate, terraform holds the migration lock throughout, waiting
+up to --lock-timeout for another runner and exiting with status 3 if it
+can't get it.
+
 Examples:
-  mlm terraform`,
+  mlm terraform
+  mlm terraform --dir ./migration`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runTerraform()
 	},
@@ -28,19 +36,30 @@ Examples:
 
 func init() {
 	rootCmd.AddCommand(terraformCmd)
+
+	terraformCmd.Flags().StringVar(&migrateDir, "dir", "", "Read migrations from this directory instead of the embedded files")
+	terraformCmd.Flags().DurationVar(&migrateLockTimeout, "lock-timeout", 60*time.Second, "How long to wait for another migration runner to finish")
 }
 
 func runTerraform() {
 	log.Println("🏗️  Terraforming database (recreate + migrate + seed)...")
 	log.Println("⚠️  WARNING: This will DELETE ALL DATA!")
 
+	// Hold the lock from the drop to the seed so no other runner migrates a
+	// half-built schema
+	db := connectMigrationDB()
+	defer db.Close()
+
+	release := acquireMigrationLock(db, migrateLockTimeout)
+	defer release()
+
 	// Step 1: Recreate (drop tables)
 	log.Println("\n📦 Step 1/3: Dropping tables...")
 	recreateDatabase()
 
 	// Step 2: Run migrations
 	log.Println("\n🔄 Step 2/3: Running migrations...")
-	runUpMigrations(connectDB(), "migration")
+	runUpMigrations(db, migrationFS())
 
 	// Step 3: Seed data
 	log.Println("\n🌱 Step 3/3: Seeding test data...")
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/spf13/cobra"
+
+	"mlm/internal/musicapp/business"
+	"mlm/internal/musicapp/db/repo"
+	"mlm/internal/musicapp/db/txn"
+	roommemberstore "mlm/internal/musicapp/lib/room_members/store"
+	roomstore "mlm/internal/musicapp/lib/rooms/store"
+	userstore "mlm/internal/musicapp/lib/users/store"
+	"mlm/internal/util/exportlib"
+)
+
+// usersCmd represents the users command
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "User account operations",
+	Long: `Administrative operations on user accounts.
+
+Subcommands:
+  erase     Irreversibly anonymize a user's personal data
+  export    Write a user's personal data archive as JSON
+
+Examples:
+  mlm users erase 0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11 --yes
+  mlm users export 0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11 -o alice.json`,
+}
+
+var usersEraseCmd = &cobra.Command{
+	Use:   "erase <user-id>",
+	Short: "Erase a user's personal data",
+	Long: `Anonymize a user for an erasure request.
+
+The username, display name, email and gender are overwritten, active room
+memberships are ended, and each active room the user created is handed to
+its longest-standing member or closed when it is empty. Membership history
+is kept against the anonymous account so room statistics stay accurate.
+
+WARNING: This cannot be undone! Pass --yes to confirm.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		confirmed, _ := cmd.Flags().GetBool("yes")
+		eraseUser(args[0], confirmed)
+	},
+}
+
+var usersExportCmd = &cobra.Command{
+	Use:   "export <user-id>",
+	Short: "Export a user's personal data",
+	Long: `Write everything held about a user as a JSON archive, for data
+subject access requests.
+
+The archive has one section per registered exporter: currently the
+profile, rooms created and room membership history. Soft-deleted and
+erased users can still be exported.
+
+Examples:
+  mlm users export <user-id>               # print to stdout
+  mlm users export <user-id> -o out.json   # write to a file`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		exportUser(args[0], output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+	usersCmd.AddCommand(usersEraseCmd)
+	usersCmd.AddCommand(usersExportCmd)
+
+	usersEraseCmd.Flags().Bool("yes", false, "Confirm the irreversible erasure")
+	usersExportCmd.Flags().StringP("output", "o", "", "File to write the archive to (default: stdout)")
+}
+
+// userAccounts is the part of business.Users the users commands use
+type userAccounts interface {
+	Erase(ctx context.Context, exec boil.ContextExecutor, userID string) (*business.EraseResult, error)
+	Export(ctx context.Context, exec boil.ContextExecutor, userID string) (*exportlib.Archive, error)
+}
+
+// newUserAccounts wires the account deletion and export workflows
+func newUserAccounts() (*business.Users, error) {
+	return business.NewUsers(
+		userstore.New(),
+		repo.NewUserRepo(),
+		roomstore.New(),
+		roommemberstore.New(),
+		repo.NewRoomMember(),
+		txn.MySQL{},
+	)
+}
+
+// connectUserAccounts opens the database and wires userAccounts for a
+// command, exiting on failure
+func connectUserAccounts() (*sql.DB, userAccounts) {
+	db := connectDB()
+
+	accounts, err := newUserAccounts()
+	if err != nil {
+		db.Close()
+		log.Fatalf("❌ Failed to initialize user accounts: %v", err)
+	}
+	return db, accounts
+}
+
+func eraseUser(userID string, confirmed bool) {
+	if !confirmed {
+		log.Fatalf("❌ Refusing to erase user %s without --yes", userID)
+	}
+
+	log.Printf("🧹 Erasing user %s...", userID)
+
+	db, accounts := connectUserAccounts()
+	defer db.Close()
+
+	result, err := accounts.Erase(context.Background(), db, userID)
+	if errors.Is(err, business.ErrUserNotFound) {
+		log.Fatalf("❌ User %s not found", userID)
+	}
+	if err != nil {
+		log.Fatalf("❌ Failed to erase user %s: %v", userID, err)
+	}
+
+	log.Printf("✅ Closed %d active membership(s)", result.MembershipsClosed)
+	log.Printf("✅ Reassigned %d room(s), closed %d room(s)", result.RoomsReassigned, result.RoomsClosed)
+	log.Printf("🎉 User %s erased", result.UserID)
+}
+
+func exportUser(userID, output string) {
+	db, accounts := connectUserAccounts()
+	defer db.Close()
+
+	archive, err := accounts.Export(context.Background(), db, userID)
+	if errors.Is(err, business.ErrUserNotFound) {
+		log.Fatalf("❌ User %s not found", userID)
+	}
+	if err != nil {
+		log.Fatalf("❌ Failed to export user %s: %v", userID, err)
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		log.Fatalf("❌ Failed to encode archive: %v", err)
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	// The archive is personal data, so keep it private to the current user
+	if err := os.WriteFile(output, data, 0o600); err != nil {
+		log.Fatalf("❌ Failed to write %s: %v", output, err)
+	}
+	log.Printf("✅ Exported user %s to %s (%d sections)", archive.UserID, output, len(archive.Sections))
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Config is the typed configuration shared by every mlm command
+type Config struct {
+	Database DatabaseConfig
+	HTTP     HTTPConfig
+	Log      LogConfig
+
+	// origins records where each setting's value came from, keyed by setting key
+	origins map[string]Origin
+}
+
+// DatabaseConfig holds MySQL connection settings
+type DatabaseConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+}
+
+// HTTPConfig holds API server settings
+type HTTPConfig struct {
+	Host string
+	Port int
+}
+
+// LogConfig holds logging settings
+type LogConfig struct {
+	Level string // "debug", "info", "warn", "error"
+}
+
+// Log levels accepted by LogConfig.Level
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// DSN builds a go-sql-driver/mysql DSN. Extra params (e.g.
+// "multiStatements=true") are appended to the query string.
+func (c DatabaseConfig) DSN(params ...string) string {
+	query := append([]string{"parseTime=true"}, params...)
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s",
+		c.User, c.Password, c.Host, c.Port, c.Name, strings.Join(query, "&"))
+}
+
+// Validate checks that every setting holds a usable value
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Database.Host == "" {
+		errs = append(errs, fmt.Errorf("database.host is required"))
+	}
+	if c.Database.Port < 1 || c.Database.Port > 65535 {
+		errs = append(errs, fmt.Errorf("database.port must be between 1 and 65535, got %d", c.Database.Port))
+	}
+	if c.Database.User == "" {
+		errs = append(errs, fmt.Errorf("database.user is required"))
+	}
+	if c.Database.Name == "" {
+		errs = append(errs, fmt.Errorf("database.name is required"))
+	}
+	if c.HTTP.Port < 1 || c.HTTP.Port > 65535 {
+		errs = append(errs, fmt.Errorf("http.port must be between 1 and 65535, got %d", c.HTTP.Port))
+	}
+
+	switch c.Log.Level {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		errs = append(errs, fmt.Errorf("log.level must be one of debug, info, warn, error, got %q", c.Log.Level))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
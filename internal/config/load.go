@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"mlm/internal/util/osenv"
+)
+
+// Source identifies which layer a setting's value came from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceDotenv  Source = "dotenv"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Origin describes where a setting's effective value came from
+type Origin struct {
+	Source Source
+	Detail string // flag, env var, or file (and key) that supplied the value
+}
+
+func (o Origin) String() string {
+	if o.Detail == "" {
+		return string(o.Source)
+	}
+	return fmt.Sprintf("%s %s", o.Source, o.Detail)
+}
+
+// Setting is a resolved setting, as printed by `mlm config`
+type Setting struct {
+	Key    string
+	Value  string
+	Secret bool
+	Origin Origin
+}
+
+// Options selects the layers Load reads, highest precedence first:
+// flags, env (MUSICAPP_*), config file, dotenv file, defaults
+type Options struct {
+	Flags      *pflag.FlagSet // only flags changed on the command line count
+	ConfigFile string         // YAML config file; skipped when empty
+	DotenvFile string         // e.g. docker/docker.env; skipped when missing
+}
+
+// setting maps one Config field onto every layer it can be read from
+type setting struct {
+	key    string   // config file key
+	env    string   // process env var
+	flag   string   // command line flag
+	dotenv []string // dotenv keys, first match wins
+	def    string
+	secret bool
+	set    func(c *Config, v string) error
+	get    func(c *Config) string
+}
+
+var settings = []setting{
+	{
+		key:    "database.host",
+		env:    "MUSICAPP_PG_HOST",
+		flag:   "db-host",
+		dotenv: []string{"MUSICAPP_PG_HOST"},
+		def:    "127.0.0.1",
+		set:    func(c *Config, v string) error { c.Database.Host = v; return nil },
+		get:    func(c *Config) string { return c.Database.Host },
+	},
+	{
+		key:    "database.port",
+		env:    "MUSICAPP_PG_PORT",
+		flag:   "db-port",
+		dotenv: []string{"MUSICAPP_PG_PORT", "MYSQL_PORT"},
+		def:    "3306",
+		set:    func(c *Config, v string) (err error) { c.Database.Port, err = strconv.Atoi(v); return err },
+		get:    func(c *Config) string { return strconv.Itoa(c.Database.Port) },
+	},
+	{
+		key:    "database.user",
+		env:    "MUSICAPP_PG_USER",
+		flag:   "db-user",
+		dotenv: []string{"MUSICAPP_PG_USER", "MYSQL_USER"},
+		def:    "user",
+		set:    func(c *Config, v string) error { c.Database.User = v; return nil },
+		get:    func(c *Config) string { return c.Database.User },
+	},
+	{
+		key:    "database.password",
+		env:    "MUSICAPP_PG_PASS",
+		flag:   "db-password",
+		dotenv: []string{"MUSICAPP_PG_PASS", "MYSQL_PASSWORD"},
+		def:    "userpass",
+		secret: true,
+		set:    func(c *Config, v string) error { c.Database.Password = v; return nil },
+		get:    func(c *Config) string { return c.Database.Password },
+	},
+	{
+		key:    "database.name",
+		env:    "MUSICAPP_PG_DATABASE",
+		flag:   "db-name",
+		dotenv: []string{"MUSICAPP_PG_DATABASE", "MYSQL_DATABASE"},
+		def:    "musicapp",
+		set:    func(c *Config, v string) error { c.Database.Name = v; return nil },
+		get:    func(c *Config) string { return c.Database.Name },
+	},
+	{
+		key:    "http.host",
+		env:    "MUSICAPP_HTTP_HOST",
+		flag:   "host",
+		dotenv: []string{"MUSICAPP_HTTP_HOST"},
+		def:    "0.0.0.0",
+		set:    func(c *Config, v string) error { c.HTTP.Host = v; return nil },
+		get:    func(c *Config) string { return c.HTTP.Host },
+	},
+	{
+		key:    "http.port",
+		env:    "MUSICAPP_HTTP_PORT",
+		flag:   "port",
+		dotenv: []string{"MUSICAPP_HTTP_PORT"},
+		def:    "8080",
+		set:    func(c *Config, v string) (err error) { c.HTTP.Port, err = strconv.Atoi(v); return err },
+		get:    func(c *Config) string { return strconv.Itoa(c.HTTP.Port) },
+	},
+	{
+		key:    "log.level",
+		env:    "MUSICAPP_LOG_LEVEL",
+		flag:   "log-level",
+		dotenv: []string{"MUSICAPP_LOG_LEVEL"},
+		def:    LogLevelInfo,
+		set:    func(c *Config, v string) error { c.Log.Level = v; return nil },
+		get:    func(c *Config) string { return c.Log.Level },
+	},
+}
+
+// Load resolves every setting from the configured layers and validates the result
+func Load(opts Options) (*Config, error) {
+	var file *viper.Viper
+	if opts.ConfigFile != "" {
+		file = viper.New()
+		file.SetConfigFile(opts.ConfigFile)
+		if err := file.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("read config file %s: %w", opts.ConfigFile, err)
+		}
+	}
+
+	var dotenv map[string]string
+	if opts.DotenvFile != "" {
+		vars, err := osenv.Read(opts.DotenvFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		dotenv = vars
+	}
+
+	cfg := &Config{origins: make(map[string]Origin)}
+	for _, s := range settings {
+		value, origin := resolve(s, opts.Flags, file, opts.ConfigFile, dotenv, opts.DotenvFile)
+
+		if err := s.set(cfg, value); err != nil {
+			return nil, fmt.Errorf("invalid %s %q from %s: %w", s.key, value, origin, err)
+		}
+		cfg.origins[s.key] = origin
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// resolve returns the value of a setting from the highest precedence layer that sets it
+func resolve(
+	s setting,
+	flags *pflag.FlagSet,
+	file *viper.Viper,
+	filePath string,
+	dotenv map[string]string,
+	dotenvPath string,
+) (string, Origin) {
+	if flags != nil {
+		if f := flags.Lookup(s.flag); f != nil && f.Changed {
+			return f.Value.String(), Origin{Source: SourceFlag, Detail: "--" + s.flag}
+		}
+	}
+
+	if v := os.Getenv(s.env); v != "" {
+		return v, Origin{Source: SourceEnv, Detail: s.env}
+	}
+
+	if file != nil && file.InConfig(s.key) {
+		return file.GetString(s.key), Origin{Source: SourceFile, Detail: filePath}
+	}
+
+	for _, key := range s.dotenv {
+		if v := dotenv[key]; v != "" {
+			return v, Origin{Source: SourceDotenv, Detail: fmt.Sprintf("%s (%s)", dotenvPath, key)}
+		}
+	}
+
+	return s.def, Origin{Source: SourceDefault}
+}
+
+// Settings returns every setting with its effective value and origin, in a
+// stable order
+func (c *Config) Settings() []Setting {
+	result := make([]Setting, len(settings))
+	for i, s := range settings {
+		result[i] = Setting{
+			Key:    s.key,
+			Value:  s.get(c),
+			Secret: s.secret,
+			Origin: c.origins[s.key],
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dotenv := writeFile(t, "docker.env", "MYSQL_USER=dotenv-user\nMYSQL_DATABASE=dotenv-db\nMYSQL_PORT=3310\n")
+	file := writeFile(t, "mlm.yaml", "database:\n  name: file-db\n  port: 3320\nhttp:\n  port: 9000\n")
+	t.Setenv("MUSICAPP_PG_PORT", "3330")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("log-level", "", "")
+	require.NoError(t, flags.Parse([]string{"--log-level", "debug"}))
+
+	cfg, err := Load(Options{Flags: flags, ConfigFile: file, DotenvFile: dotenv})
+	require.NoError(t, err)
+
+	assert.Equal(t, "127.0.0.1", cfg.Database.Host)
+	assert.Equal(t, "dotenv-user", cfg.Database.User)
+	assert.Equal(t, "file-db", cfg.Database.Name)
+	assert.Equal(t, 3330, cfg.Database.Port)
+	assert.Equal(t, 9000, cfg.HTTP.Port)
+	assert.Equal(t, LogLevelDebug, cfg.Log.Level)
+
+	origins := make(map[string]Source)
+	for _, s := range cfg.Settings() {
+		origins[s.Key] = s.Origin.Source
+	}
+	assert.Equal(t, SourceDefault, origins["database.host"])
+	assert.Equal(t, SourceDotenv, origins["database.user"])
+	assert.Equal(t, SourceFile, origins["database.name"])
+	assert.Equal(t, SourceEnv, origins["database.port"])
+	assert.Equal(t, SourceFlag, origins["log.level"])
+}
+
+func TestLoad_MissingDotenvUsesDefaults(t *testing.T) {
+	cfg, err := Load(Options{DotenvFile: filepath.Join(t.TempDir(), "missing.env")})
+	require.NoError(t, err)
+
+	assert.Equal(t, "user", cfg.Database.User)
+	assert.Equal(t, "userpass", cfg.Database.Password)
+	assert.Equal(t, "musicapp", cfg.Database.Name)
+	assert.Equal(t, 8080, cfg.HTTP.Port)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	t.Setenv("MUSICAPP_HTTP_PORT", "70000")
+	t.Setenv("MUSICAPP_LOG_LEVEL", "loud")
+
+	_, err := Load(Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http.port must be between 1 and 65535")
+	assert.Contains(t, err.Error(), `log.level must be one of debug, info, warn, error, got "loud"`)
+}
+
+func TestDatabaseConfig_DSN(t *testing.T) {
+	db := DatabaseConfig{Host: "db", Port: 3306, User: "u", Password: "p", Name: "n"}
+
+	assert.Equal(t, "u:p@tcp(db:3306)/n?parseTime=true", db.DSN())
+	assert.Equal(t, "u:p@tcp(db:3306)/n?parseTime=true&multiStatements=true", db.DSN("multiStatements=true"))
+}
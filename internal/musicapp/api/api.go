@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/validationlib"
+)
+
+// CallerHeader identifies the user making the request until real auth lands
+const CallerHeader = "X-User-ID"
+
+// errorResponse is the JSON body returned for every failed request. Code is
+// a stable, machine-readable form of the status; Error is for humans.
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// errorCodes names the statuses the API fails with
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusUnprocessableEntity: "unprocessable",
+	http.StatusInternalServerError: "internal",
+}
+
+// writeJSON encodes v as the response body with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("⚠️  Failed to encode response: %v", err)
+	}
+}
+
+// writeError writes a JSON error body with the given status
+func writeError(w http.ResponseWriter, status int, msg string) {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = "error"
+	}
+	writeJSON(w, status, errorResponse{Error: msg, Code: code})
+}
+
+// writeDomainError writes err with the status its kind maps to:
+//   - validationlib.ValidationError: 400
+//   - errlib.ErrNotFound: 404
+//   - errlib.ErrConflict: 409
+//   - errlib.ErrForeignKey, errlib.ErrInvalidID: 422
+//
+// Errors raised by the database (duplicate keys, foreign keys) get a generic
+// message so SQL never reaches the client. Anything else, including
+// errlib.ErrMultipleResults, is logged and written as a 500 with
+// "failed to <action>".
+func writeDomainError(w http.ResponseWriter, err error, action string) {
+	var status int
+	switch {
+	case validationlib.IsValidationError(err):
+		status = http.StatusBadRequest
+	case errors.Is(err, errlib.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errlib.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, errlib.ErrForeignKey), errors.Is(err, errlib.ErrInvalidID):
+		status = http.StatusUnprocessableEntity
+	default:
+		log.Printf("❌ Failed to %s: %v", action, err)
+		writeError(w, http.StatusInternalServerError, "failed to "+action)
+		return
+	}
+
+	var kindErr *errlib.Error
+	if errors.As(err, &kindErr) && kindErr.Err != nil {
+		writeError(w, status, fmt.Sprintf("failed to %s: %s", action, dbErrorMessages[kindErr.Kind]))
+		return
+	}
+	writeError(w, status, err.Error())
+}
+
+// dbErrorMessages replace database error text in responses
+var dbErrorMessages = map[error]string{
+	errlib.ErrNotFound:   "not found",
+	errlib.ErrConflict:   "conflicts with an existing record",
+	errlib.ErrForeignKey: "references a record that does not exist or is still in use",
+}
+
+// decodeJSON decodes the request body into v, rejecting unknown fields
+func decodeJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// queryString returns a query parameter as a null.String (invalid when absent)
+func queryString(r *http.Request, key string) null.String {
+	val := r.URL.Query().Get(key)
+	if val == "" {
+		return null.String{}
+	}
+	return null.StringFrom(val)
+}
+
+// queryInt returns a query parameter as a null.Int (invalid when absent)
+func queryInt(r *http.Request, key string) (null.Int, error) {
+	val := r.URL.Query().Get(key)
+	if val == "" {
+		return null.Int{}, nil
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return null.Int{}, fmt.Errorf("invalid %s: %q", key, val)
+	}
+	return null.IntFrom(n), nil
+}
+
+// queryBool returns a query parameter as a null.Bool (invalid when absent)
+func queryBool(r *http.Request, key string) (null.Bool, error) {
+	val := r.URL.Query().Get(key)
+	if val == "" {
+		return null.Bool{}, nil
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return null.Bool{}, fmt.Errorf("invalid %s: %q", key, val)
+	}
+	return null.BoolFrom(b), nil
+}
+
+// queryTime returns an RFC 3339 query parameter as a null.Time (invalid when absent)
+func queryTime(r *http.Request, key string) (null.Time, error) {
+	val := r.URL.Query().Get(key)
+	if val == "" {
+		return null.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return null.Time{}, fmt.Errorf("invalid %s: %q, expected RFC 3339 e.g. 2024-01-02T15:04:05Z", key, val)
+	}
+	return null.TimeFrom(t), nil
+}
+
+// queryList returns a comma separated (or repeated) query parameter as a slice
+func queryList(r *http.Request, key string) []string {
+	var result []string
+	for _, val := range r.URL.Query()[key] {
+		for _, part := range strings.Split(val, ",") {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// callerID returns the ID of the user making the request, writing a 401 when absent
+func callerID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := strings.TrimSpace(r.Header.Get(CallerHeader))
+	if id == "" {
+		writeError(w, http.StatusUnauthorized, fmt.Sprintf("missing %s header", CallerHeader))
+		return "", false
+	}
+	return id, true
+}
+
+// isCaller reports whether the request is made by the user with id, without
+// requiring a caller
+func isCaller(r *http.Request, id string) bool {
+	caller := strings.TrimSpace(r.Header.Get(CallerHeader))
+	return caller != "" && strings.EqualFold(caller, id)
+}
+
+// requireCaller checks the request is made by owner, the user the resource
+// belongs to. It writes a 401 when the caller is absent or a 403 with msg
+// when it is someone else.
+func requireCaller(w http.ResponseWriter, r *http.Request, owner string, msg string) bool {
+	caller, ok := callerID(w, r)
+	if !ok {
+		return false
+	}
+	if !strings.EqualFold(caller, owner) {
+		writeError(w, http.StatusForbidden, msg)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+)
+
+// RoomMemberLogic is the part of room_members.Logic used by
+// RoomMemberHandler
+type RoomMemberLogic interface {
+	Members(ctx context.Context, exec boil.ContextExecutor, roomID string, limit null.Int, after null.String) (*room_members.RoomMemberPage, error)
+	Timeline(ctx context.Context, exec boil.ContextExecutor, roomID string, limit null.Int, after null.String) (*room_members.RoomMemberPage, error)
+	CountMembers(ctx context.Context, exec boil.ContextExecutor, roomID string, includeFormer bool) (int64, error)
+	Join(ctx context.Context, exec boil.ContextExecutor, roomID string, userID string) (*room_members.RoomMembers, error)
+	Leave(ctx context.Context, exec boil.ContextExecutor, roomID string, userID string) error
+}
+
+// RoomMemberHandler serves the /rooms/{id}/members routes
+type RoomMemberHandler struct {
+	db      *sql.DB
+	rooms   RoomLogic
+	members RoomMemberLogic
+}
+
+// NewRoomMemberHandler creates a new room member handler
+func NewRoomMemberHandler(db *sql.DB, rooms RoomLogic, members RoomMemberLogic) *RoomMemberHandler {
+	return &RoomMemberHandler{
+		db:      db,
+		rooms:   rooms,
+		members: members,
+	}
+}
+
+// roomMemberResponse is the JSON representation of a room membership
+type roomMemberResponse struct {
+	ID       string     `json:"id"`
+	RoomID   string     `json:"room_id"`
+	UserID   string     `json:"user_id"`
+	JoinedAt time.Time  `json:"joined_at"`
+	LeftAt   *time.Time `json:"left_at"`
+}
+
+// listRoomMembersResponse is one page of a room's members
+type listRoomMembersResponse struct {
+	Members    []roomMemberResponse `json:"members"`
+	Total      int64                `json:"total"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// ListMembers handles GET /rooms/{id}/members, listing the room's current
+// members oldest first
+func (h *RoomMemberHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.findRoom(w, r, roomID); !ok {
+		return
+	}
+
+	page, err := h.members.Members(r.Context(), h.db, roomID, limit, queryString(r, "cursor"))
+	if err != nil {
+		writeDomainError(w, err, "list room members")
+		return
+	}
+
+	total, err := h.members.CountMembers(r.Context(), h.db, roomID, false)
+	if err != nil {
+		writeDomainError(w, err, "list room members")
+		return
+	}
+
+	resp := listRoomMembersResponse{
+		Members:    make([]roomMemberResponse, len(page.RoomMembers)),
+		Total:      total,
+		NextCursor: page.NextCursor,
+	}
+	for i, m := range page.RoomMembers {
+		resp.Members[i] = toRoomMemberResponse(m)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// MemberHistory handles GET /rooms/{id}/members/history, listing every
+// membership of the room, current and former, in the order they joined
+func (h *RoomMemberHandler) MemberHistory(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.findRoom(w, r, roomID); !ok {
+		return
+	}
+
+	page, err := h.members.Timeline(r.Context(), h.db, roomID, limit, queryString(r, "cursor"))
+	if err != nil {
+		writeDomainError(w, err, "list room member history")
+		return
+	}
+
+	total, err := h.members.CountMembers(r.Context(), h.db, roomID, true)
+	if err != nil {
+		writeDomainError(w, err, "list room member history")
+		return
+	}
+
+	resp := listRoomMembersResponse{
+		Members:    make([]roomMemberResponse, len(page.RoomMembers)),
+		Total:      total,
+		NextCursor: page.NextCursor,
+	}
+	for i, m := range page.RoomMembers {
+		resp.Members[i] = toRoomMemberResponse(m)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// JoinRoom handles POST /rooms/{id}/members, adding the caller to the room.
+// The room must be active and the caller an existing, non-deleted user.
+func (h *RoomMemberHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+	roomID := r.PathValue("id")
+
+	// Join checks the room exists and is active in the same transaction
+	member, err := h.members.Join(r.Context(), h.db, roomID, caller)
+	if err != nil {
+		writeDomainError(w, err, "join room")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toRoomMemberResponse(member))
+}
+
+// LeaveRoom handles DELETE /rooms/{id}/members/me, ending the caller's membership
+func (h *RoomMemberHandler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+	roomID := r.PathValue("id")
+
+	if _, ok := h.findRoom(w, r, roomID); !ok {
+		return
+	}
+
+	err := h.members.Leave(r.Context(), h.db, roomID, caller)
+	if err != nil {
+		writeDomainError(w, err, "leave room")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findRoom loads a single room by ID, writing a 404 when it does not exist
+func (h *RoomMemberHandler) findRoom(w http.ResponseWriter, r *http.Request, id string) (*rooms.Room, bool) {
+	room, err := h.rooms.Get(r.Context(), h.db, id)
+	if err != nil {
+		writeDomainError(w, err, "get room")
+		return nil, false
+	}
+	return room, true
+}
+
+func toRoomMemberResponse(m *room_members.RoomMembers) roomMemberResponse {
+	resp := roomMemberResponse{
+		ID:       m.ID,
+		RoomID:   m.RoomID,
+		UserID:   m.UserID,
+		JoinedAt: m.JoinedAt,
+	}
+	if m.LeftAt.Valid {
+		leftAt := m.LeftAt.Time
+		resp.LeftAt = &leftAt
+	}
+	return resp
+}
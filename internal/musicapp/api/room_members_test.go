@@ -0,0 +1,71 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/api"
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+)
+
+// newRoomMemberServer serves the /rooms/{id}/members routes over a fresh
+// in-memory database
+func newRoomMemberServer(t *testing.T) (http.Handler, *memdb.DB) {
+	db := memdb.New()
+	memberships, err := room_members.NewLogic(memdb.NewRoomMemberStore(db), memdb.NewRoomMemberRepo(db), memdb.NewRoomStore(db), memdb.NewUserStore(db), db)
+	require.NoError(t, err)
+	logic, err := rooms.NewLogic(memdb.NewRoomStore(db), memdb.NewRoomRepo(db), memdb.NewUserStore(db), memberships, db)
+	require.NoError(t, err)
+
+	handler := api.NewRoomMemberHandler(nil, logic, memberships)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rooms/{id}/members", handler.JoinRoom)
+	return mux, db
+}
+
+func TestRoomMemberHandler_JoinRoom(t *testing.T) {
+	t.Run("success-joins-room", func(t *testing.T) {
+		handler, db := newRoomMemberServer(t)
+		room := factory.MemRoom(t, db, nil)
+		user := factory.MemUser(t, db, nil)
+
+		w := serve(handler, http.MethodPost, "/rooms/"+room.ID+"/members", user.ID, "")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"user_id":"`+user.ID+`"`)
+	})
+
+	t.Run("error-statuses", func(t *testing.T) {
+		handler, db := newRoomMemberServer(t)
+		room := factory.MemRoom(t, db, nil)
+		inactive := factory.MemRoom(t, db, &factory.RoomMods{IsActive: null.BoolFrom(false)})
+		user := factory.MemUser(t, db, nil)
+		deleted := factory.MemUser(t, db, &factory.UserMods{DeletedAt: null.TimeFrom(time.Now())})
+
+		requests := []struct {
+			name     string
+			roomID   string
+			caller   string
+			expected int
+		}{
+			{name: "missing-caller", roomID: room.ID, caller: "", expected: http.StatusUnauthorized},
+			{name: "room-not-found", roomID: "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11", caller: user.ID, expected: http.StatusNotFound},
+			{name: "inactive-room", roomID: inactive.ID, caller: user.ID, expected: http.StatusConflict},
+			{name: "deleted-caller", roomID: room.ID, caller: deleted.ID, expected: http.StatusUnprocessableEntity},
+		}
+
+		for _, req := range requests {
+			t.Run(req.name, func(t *testing.T) {
+				w := serve(handler, http.MethodPost, "/rooms/"+req.roomID+"/members", req.caller, "")
+				assert.Equal(t, req.expected, w.Code, w.Body.String())
+			})
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/util/querylib"
+)
+
+// RoomLogic is the part of rooms.Logic used by RoomHandler and
+// RoomMemberHandler
+type RoomLogic interface {
+	Get(ctx context.Context, exec boil.ContextExecutor, id string) (*rooms.Room, error)
+	Page(ctx context.Context, exec boil.ContextExecutor, filter rooms.RoomQueryFilter) (*rooms.RoomPage, error)
+	Count(ctx context.Context, exec boil.ContextExecutor, filter rooms.RoomQueryFilter) (int64, error)
+	Create(ctx context.Context, exec boil.ContextExecutor, name string, createdBy string) (*rooms.Room, error)
+	Update(ctx context.Context, exec boil.ContextExecutor, update rooms.UpdateRoom) (int, error)
+}
+
+// RoomHandler serves the /rooms routes
+type RoomHandler struct {
+	db    *sql.DB
+	logic RoomLogic
+}
+
+// NewRoomHandler creates a new room handler
+func NewRoomHandler(db *sql.DB, logic RoomLogic) *RoomHandler {
+	return &RoomHandler{
+		db:    db,
+		logic: logic,
+	}
+}
+
+// roomResponse is the JSON representation of a room
+type roomResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// listRoomsResponse is one page of rooms; pass next_cursor back as ?cursor= to
+// fetch the next page. It is omitted on the last page.
+type listRoomsResponse struct {
+	Rooms      []roomResponse `json:"rooms"`
+	Total      int64          `json:"total"` // matching rooms across all pages
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type createRoomRequest struct {
+	Name string `json:"name"`
+}
+
+// updateRoomRequest uses null types so omitted fields are left untouched.
+// Rooms are deactivated with is_active=false rather than deleted so their
+// history is kept.
+type updateRoomRequest struct {
+	Name     null.String `json:"name"`
+	IsActive null.Bool   `json:"is_active"`
+}
+
+// ListRooms handles GET /rooms
+func (h *RoomHandler) ListRooms(w http.ResponseWriter, r *http.Request) {
+	filter, err := roomFilterFromQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.logic.Page(r.Context(), h.db, filter)
+	if err != nil {
+		writeDomainError(w, err, "list rooms")
+		return
+	}
+
+	total, err := h.logic.Count(r.Context(), h.db, filter)
+	if err != nil {
+		writeDomainError(w, err, "list rooms")
+		return
+	}
+
+	resp := listRoomsResponse{
+		Rooms:      make([]roomResponse, len(page.Rooms)),
+		Total:      total,
+		NextCursor: page.NextCursor,
+	}
+	for i, room := range page.Rooms {
+		resp.Rooms[i] = toRoomResponse(room)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetRoom handles GET /rooms/{id}
+func (h *RoomHandler) GetRoom(w http.ResponseWriter, r *http.Request) {
+	room, err := h.logic.Get(r.Context(), h.db, r.PathValue("id"))
+	if err != nil {
+		writeDomainError(w, err, "get room")
+		return
+	}
+	writeJSON(w, http.StatusOK, toRoomResponse(room))
+}
+
+// CreateRoom handles POST /rooms, recording the caller as the creator and
+// first member
+func (h *RoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	var req createRoomRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	room, err := h.logic.Create(r.Context(), h.db, req.Name, caller)
+	if err != nil {
+		writeDomainError(w, err, "create room")
+		return
+	}
+	writeJSON(w, http.StatusCreated, toRoomResponse(room))
+}
+
+// UpdateRoom handles PATCH /rooms/{id} (rename and activate/deactivate).
+// Only the room's creator may update it. Deactivating a room also ends all
+// of its active memberships.
+func (h *RoomHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	caller, ok := callerID(w, r)
+	if !ok {
+		return
+	}
+
+	existing, err := h.logic.Get(r.Context(), h.db, id)
+	if err != nil {
+		writeDomainError(w, err, "update room")
+		return
+	}
+	if !strings.EqualFold(existing.CreatedBy, caller) {
+		writeError(w, http.StatusForbidden, "only the room's creator can update it")
+		return
+	}
+
+	var req updateRoomRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	closed, err := h.logic.Update(r.Context(), h.db, rooms.UpdateRoom{
+		IDs:      []string{id},
+		Name:     req.Name,
+		IsActive: req.IsActive,
+	})
+	if err != nil {
+		writeDomainError(w, err, "update room")
+		return
+	}
+	if closed > 0 {
+		log.Printf("🚪 Closed %d membership(s) of deactivated room %s", closed, id)
+	}
+
+	room, err := h.logic.Get(r.Context(), h.db, id)
+	if err != nil {
+		writeDomainError(w, err, "get room")
+		return
+	}
+	writeJSON(w, http.StatusOK, toRoomResponse(room))
+}
+
+// roomFilterFromQuery maps query-string parameters onto a RoomQueryFilter
+func roomFilterFromQuery(r *http.Request) (rooms.RoomQueryFilter, error) {
+	isActive, err := queryBool(r, "is_active")
+	if err != nil {
+		return rooms.RoomQueryFilter{}, err
+	}
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		return rooms.RoomQueryFilter{}, err
+	}
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		return rooms.RoomQueryFilter{}, err
+	}
+	createdAfter, err := queryTime(r, "created_after")
+	if err != nil {
+		return rooms.RoomQueryFilter{}, err
+	}
+	createdBefore, err := queryTime(r, "created_before")
+	if err != nil {
+		return rooms.RoomQueryFilter{}, err
+	}
+
+	return rooms.RoomQueryFilter{
+		IDs:           queryList(r, "ids"),
+		Name:          queryString(r, "name"),
+		NamePrefix:    queryString(r, "name_prefix"),
+		NameContains:  queryString(r, "name_contains"),
+		CreatedByIDs:  queryList(r, "created_by"),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		IsActive:      isActive,
+		Sorts:         querylib.ParseSorts[rooms.RoomSortField](queryList(r, "sort")),
+		Limit:         limit,
+		Offset:        offset,
+		After:         queryString(r, "cursor"),
+	}, nil
+}
+
+func toRoomResponse(room *rooms.Room) roomResponse {
+	return roomResponse{
+		ID:        room.ID,
+		Name:      room.Name,
+		CreatedBy: room.CreatedBy,
+		IsActive:  room.IsActive,
+		CreatedAt: room.CreatedAt,
+	}
+}
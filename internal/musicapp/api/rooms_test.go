@@ -0,0 +1,167 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/api"
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+)
+
+// newRoomServer serves the /rooms routes over a fresh in-memory database
+func newRoomServer(t *testing.T) (http.Handler, *memdb.DB) {
+	db := memdb.New()
+	memberships, err := room_members.NewLogic(memdb.NewRoomMemberStore(db), memdb.NewRoomMemberRepo(db), memdb.NewRoomStore(db), memdb.NewUserStore(db), db)
+	require.NoError(t, err)
+	logic, err := rooms.NewLogic(memdb.NewRoomStore(db), memdb.NewRoomRepo(db), memdb.NewUserStore(db), memberships, db)
+	require.NoError(t, err)
+
+	handler := api.NewRoomHandler(nil, logic)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rooms", handler.ListRooms)
+	mux.HandleFunc("GET /rooms/{id}", handler.GetRoom)
+	mux.HandleFunc("POST /rooms", handler.CreateRoom)
+	mux.HandleFunc("PATCH /rooms/{id}", handler.UpdateRoom)
+	return mux, db
+}
+
+// serve sends one request to handler as caller, when set
+func serve(handler http.Handler, method, target, caller, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if caller != "" {
+		r.Header.Set(api.CallerHeader, caller)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestRoomHandler_CreateRoom(t *testing.T) {
+	t.Run("success-creates-room", func(t *testing.T) {
+		handler, db := newRoomServer(t)
+		creator := factory.MemUser(t, db, nil)
+
+		w := serve(handler, http.MethodPost, "/rooms", creator.ID, `{"name": "Jazz Lounge"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var created map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.Equal(t, "Jazz Lounge", created["name"])
+		assert.Equal(t, creator.ID, created["created_by"])
+		assert.Equal(t, true, created["is_active"])
+
+		w = serve(handler, http.MethodGet, "/rooms/"+created["id"].(string), "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("error-statuses", func(t *testing.T) {
+		handler, db := newRoomServer(t)
+		creator := factory.MemUser(t, db, nil)
+
+		requests := []struct {
+			name     string
+			caller   string
+			body     string
+			expected int
+		}{
+			{name: "missing-caller", caller: "", body: `{"name": "Lobby"}`, expected: http.StatusUnauthorized},
+			{name: "unknown-field", caller: creator.ID, body: `{"title": "Lobby"}`, expected: http.StatusBadRequest},
+			{name: "blank-name", caller: creator.ID, body: `{"name": " "}`, expected: http.StatusBadRequest},
+			{name: "invalid-caller", caller: "42", body: `{"name": "Lobby"}`, expected: http.StatusUnprocessableEntity},
+			{name: "unknown-caller", caller: "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11", body: `{"name": "Lobby"}`, expected: http.StatusUnprocessableEntity},
+		}
+
+		for _, req := range requests {
+			t.Run(req.name, func(t *testing.T) {
+				w := serve(handler, http.MethodPost, "/rooms", req.caller, req.body)
+				assert.Equal(t, req.expected, w.Code, w.Body.String())
+			})
+		}
+	})
+}
+
+func TestRoomHandler_ListRooms(t *testing.T) {
+	handler, db := newRoomServer(t)
+	for _, name := range []string{"b", "c", "a"} {
+		factory.MemRoom(t, db, &factory.RoomMods{Name: name})
+	}
+
+	var names []string
+	target := "/rooms?sort=name&limit=2"
+	for {
+		w := serve(handler, http.MethodGet, target, "", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var page struct {
+			Rooms []struct {
+				Name string `json:"name"`
+			} `json:"rooms"`
+			Total      int64  `json:"total"`
+			NextCursor string `json:"next_cursor"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, int64(3), page.Total)
+		for _, room := range page.Rooms {
+			names = append(names, room.Name)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		target = "/rooms?sort=name&limit=2&cursor=" + url.QueryEscape(page.NextCursor)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+func TestRoomHandler_UpdateRoom(t *testing.T) {
+	t.Run("success-creator-deactivates", func(t *testing.T) {
+		handler, db := newRoomServer(t)
+		room := factory.MemRoom(t, db, nil)
+		factory.MemRoomMember(t, db, &factory.RoomMemberMods{RoomID: &room.ID})
+
+		w := serve(handler, http.MethodPatch, "/rooms/"+room.ID, room.CreatedBy, `{"is_active": false}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"is_active":false`)
+	})
+
+	t.Run("error-statuses", func(t *testing.T) {
+		handler, db := newRoomServer(t)
+		room := factory.MemRoom(t, db, &factory.RoomMods{Name: "Lobby"})
+		other := factory.MemUser(t, db, nil)
+
+		requests := []struct {
+			name     string
+			target   string
+			caller   string
+			expected int
+		}{
+			{name: "missing-caller", target: "/rooms/" + room.ID, caller: "", expected: http.StatusUnauthorized},
+			{name: "not-creator", target: "/rooms/" + room.ID, caller: other.ID, expected: http.StatusForbidden},
+			{name: "room-not-found", target: "/rooms/0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11", caller: other.ID, expected: http.StatusNotFound},
+		}
+
+		for _, req := range requests {
+			t.Run(req.name, func(t *testing.T) {
+				w := serve(handler, http.MethodPatch, req.target, req.caller, `{"name": "Renamed", "is_active": false}`)
+				assert.Equal(t, req.expected, w.Code, w.Body.String())
+			})
+		}
+
+		// Neither renamed nor deactivated
+		w := serve(handler, http.MethodGet, "/rooms/"+room.ID, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"Lobby"`)
+		assert.Contains(t, w.Body.String(), `"is_active":true`)
+	})
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/business"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/exportlib"
+	"mlm/internal/util/querylib"
+)
+
+// UserLogic is the part of users.Logic used by UserHandler
+type UserLogic interface {
+	Get(ctx context.Context, exec boil.ContextExecutor, id string) (*users.User, error)
+	Page(ctx context.Context, exec boil.ContextExecutor, filter users.UserQueryFilter) (*users.UserPage, error)
+	Count(ctx context.Context, exec boil.ContextExecutor, filter users.UserQueryFilter) (int64, error)
+	Create(ctx context.Context, exec boil.ContextExecutor, newUser users.NewUser) (*users.User, error)
+	Update(ctx context.Context, exec boil.ContextExecutor, update users.UpdateUser) error
+}
+
+// UserAccounts is the part of business.Users used by UserHandler
+type UserAccounts interface {
+	Delete(ctx context.Context, exec boil.ContextExecutor, userID string) error
+	Erase(ctx context.Context, exec boil.ContextExecutor, userID string) (*business.EraseResult, error)
+	Export(ctx context.Context, exec boil.ContextExecutor, userID string) (*exportlib.Archive, error)
+}
+
+// UserHandler serves the /users routes
+type UserHandler struct {
+	db       *sql.DB
+	logic    UserLogic
+	accounts UserAccounts
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(db *sql.DB, logic UserLogic, accounts UserAccounts) *UserHandler {
+	return &UserHandler{
+		db:       db,
+		logic:    logic,
+		accounts: accounts,
+	}
+}
+
+// userResponse is the JSON representation of a user
+type userResponse struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email,omitempty"` // only shown to the user themselves
+	DisplayName string    `json:"display_name"`
+	Gender      string    `json:"gender"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// listUsersResponse is one page of users; pass next_cursor back as ?cursor= to
+// fetch the next page. It is omitted on the last page.
+type listUsersResponse struct {
+	Users      []userResponse `json:"users"`
+	Total      int64          `json:"total"` // matching users across all pages
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type createUserRequest struct {
+	Username    string `json:"username"`
+	Email       string `json:"email"` // optional
+	DisplayName string `json:"display_name"`
+	Gender      string `json:"gender"`
+}
+
+// updateUserRequest uses null types so omitted fields are left untouched
+type updateUserRequest struct {
+	Username    null.String `json:"username"`
+	Email       null.String `json:"email"`
+	DisplayName null.String `json:"display_name"`
+	Gender      null.String `json:"gender"`
+}
+
+// ListUsers handles GET /users
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := userFilterFromQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.logic.Page(r.Context(), h.db, filter)
+	if err != nil {
+		writeDomainError(w, err, "list users")
+		return
+	}
+
+	total, err := h.logic.Count(r.Context(), h.db, filter)
+	if err != nil {
+		writeDomainError(w, err, "list users")
+		return
+	}
+
+	resp := listUsersResponse{
+		Users:      make([]userResponse, len(page.Users)),
+		Total:      total,
+		NextCursor: page.NextCursor,
+	}
+	for i, u := range page.Users {
+		resp.Users[i] = toUserResponse(u, isCaller(r, u.ID))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetUser handles GET /users/{id}
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.logic.Get(r.Context(), h.db, r.PathValue("id"))
+	if err != nil {
+		writeDomainError(w, err, "get user")
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResponse(user, isCaller(r, user.ID)))
+}
+
+// CreateUser handles POST /users
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req createUserRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.logic.Create(r.Context(), h.db, users.NewUser{
+		Username:    req.Username,
+		Email:       req.Email,
+		DisplayName: req.DisplayName,
+		Gender:      users.Gender(req.Gender),
+	})
+	if err != nil {
+		writeDomainError(w, err, "create user")
+		return
+	}
+	// The creator just sent the email, so echoing it back leaks nothing
+	writeJSON(w, http.StatusCreated, toUserResponse(user, true))
+}
+
+// UpdateUser handles PATCH /users/{id}. Users can only update their own
+// account.
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !requireCaller(w, r, id, "you can only update your own account") {
+		return
+	}
+
+	var req updateUserRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.logic.Update(r.Context(), h.db, users.UpdateUser{
+		IDs:         []string{id},
+		Username:    req.Username,
+		Email:       req.Email,
+		DisplayName: req.DisplayName,
+		Gender:      req.Gender,
+	})
+	if err != nil {
+		writeDomainError(w, err, "update user")
+		return
+	}
+
+	user, err := h.logic.Get(r.Context(), h.db, id)
+	if err != nil {
+		writeDomainError(w, err, "get user")
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResponse(user, true))
+}
+
+// eraseUserResponse reports what erasing a user changed
+type eraseUserResponse struct {
+	ID                string `json:"id"`
+	MembershipsClosed int    `json:"memberships_closed"`
+	RoomsReassigned   int    `json:"rooms_reassigned"`
+	RoomsClosed       int    `json:"rooms_closed"`
+}
+
+// DeleteUser handles DELETE /users/{id}. By default the user is soft-deleted;
+// with ?erase=true their personal data is irreversibly anonymized instead.
+// Users can only delete their own account.
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !requireCaller(w, r, id, "you can only delete your own account") {
+		return
+	}
+
+	erase, err := queryBool(r, "erase")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if erase.Bool {
+		result, err := h.accounts.Erase(r.Context(), h.db, id)
+		if err != nil {
+			writeDomainError(w, err, "erase user")
+			return
+		}
+
+		log.Printf("🧹 Erased user %s", result.UserID)
+		writeJSON(w, http.StatusOK, eraseUserResponse{
+			ID:                result.UserID,
+			MembershipsClosed: result.MembershipsClosed,
+			RoomsReassigned:   result.RoomsReassigned,
+			RoomsClosed:       result.RoomsClosed,
+		})
+		return
+	}
+
+	err = h.accounts.Delete(r.Context(), h.db, id)
+	if err != nil {
+		writeDomainError(w, err, "delete user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportUser handles GET /users/{id}/export, returning the user's personal
+// data archive as a JSON download. Users can only export their own data.
+func (h *UserHandler) ExportUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !requireCaller(w, r, id, "you can only export your own data") {
+		return
+	}
+
+	archive, err := h.accounts.Export(r.Context(), h.db, id)
+	if err != nil {
+		writeDomainError(w, err, "export user")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s-export.json"`, archive.UserID))
+	writeJSON(w, http.StatusOK, archive)
+}
+
+// userFilterFromQuery maps query-string parameters onto a UserQueryFilter.
+// The list is public, so it offers no email or soft-deleted user filters that
+// would reveal who has an account.
+func userFilterFromQuery(r *http.Request) (users.UserQueryFilter, error) {
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		return users.UserQueryFilter{}, err
+	}
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		return users.UserQueryFilter{}, err
+	}
+	createdAfter, err := queryTime(r, "created_after")
+	if err != nil {
+		return users.UserQueryFilter{}, err
+	}
+	createdBefore, err := queryTime(r, "created_before")
+	if err != nil {
+		return users.UserQueryFilter{}, err
+	}
+
+	return users.UserQueryFilter{
+		IDs:                 queryList(r, "ids"),
+		Username:            queryString(r, "username"),
+		Genders:             queryList(r, "gender"),
+		UsernamePrefix:      queryString(r, "username_prefix"),
+		UsernameContains:    queryString(r, "username_contains"),
+		DisplayNamePrefix:   queryString(r, "display_name_prefix"),
+		DisplayNameContains: queryString(r, "display_name_contains"),
+		CreatedAfter:        createdAfter,
+		CreatedBefore:       createdBefore,
+		Sorts:               querylib.ParseSorts[users.UserSortField](queryList(r, "sort")),
+		Limit:               limit,
+		Offset:              offset,
+		After:               queryString(r, "cursor"),
+	}, nil
+}
+
+// toUserResponse maps u for the API, including the email only when showEmail
+// is set because the caller is u
+func toUserResponse(u *users.User, showEmail bool) userResponse {
+	resp := userResponse{
+		ID:          u.ID,
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		Gender:      string(u.Gender),
+		CreatedAt:   u.CreatedAt,
+	}
+	if showEmail {
+		resp.Email = u.Email
+	}
+	return resp
+}
@@ -0,0 +1,227 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/api"
+	"mlm/internal/musicapp/business"
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/exportlib"
+)
+
+// The real exporters live in the MySQL store packages; these stand in so
+// exports over memdb find every section they require
+func init() {
+	for _, section := range []string{users.ExportSection, rooms.ExportSection, room_members.ExportSection} {
+		exportlib.Register(section, func(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error) {
+			return map[string]string{"user_id": userID}, nil
+		})
+	}
+}
+
+// newUserServer serves the /users routes over a fresh in-memory database
+func newUserServer(t *testing.T) (http.Handler, *memdb.DB) {
+	db := memdb.New()
+	logic, err := users.NewLogic(memdb.NewUserStore(db), memdb.NewUserRepo(db), db)
+	require.NoError(t, err)
+	accounts, err := business.NewUsers(
+		memdb.NewUserStore(db),
+		memdb.NewUserRepo(db),
+		memdb.NewRoomStore(db),
+		memdb.NewRoomMemberStore(db),
+		memdb.NewRoomMemberRepo(db),
+		db,
+	)
+	require.NoError(t, err)
+
+	handler := api.NewUserHandler(nil, logic, accounts)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users", handler.ListUsers)
+	mux.HandleFunc("GET /users/{id}", handler.GetUser)
+	mux.HandleFunc("PATCH /users/{id}", handler.UpdateUser)
+	mux.HandleFunc("DELETE /users/{id}", handler.DeleteUser)
+	mux.HandleFunc("GET /users/{id}/export", handler.ExportUser)
+	return mux, db
+}
+
+func TestUserHandler_GetUser(t *testing.T) {
+	handler, db := newUserServer(t)
+	user := factory.MemUser(t, db, &factory.UserMods{Email: "alice@example.com"})
+	other := factory.MemUser(t, db, nil)
+
+	requests := []struct {
+		name      string
+		caller    string
+		showEmail bool
+	}{
+		{name: "success-owner-sees-email", caller: user.ID, showEmail: true},
+		{name: "success-anonymous-hides-email", caller: ""},
+		{name: "success-other-caller-hides-email", caller: other.ID},
+	}
+
+	for _, req := range requests {
+		t.Run(req.name, func(t *testing.T) {
+			w := serve(handler, http.MethodGet, "/users/"+user.ID, req.caller, "")
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, req.showEmail, strings.Contains(w.Body.String(), "alice@example.com"))
+		})
+	}
+}
+
+func TestUserHandler_ListUsers(t *testing.T) {
+	handler, db := newUserServer(t)
+	alice := factory.MemUser(t, db, &factory.UserMods{Username: "alice", Email: "alice@example.com"})
+	factory.MemUser(t, db, &factory.UserMods{Username: "bob", Email: "bob@example.com"})
+	factory.MemUser(t, db, &factory.UserMods{Username: "carol", DeletedAt: null.TimeFrom(time.Now())})
+
+	// Email and soft-delete filters aren't offered publicly, so they're ignored
+	w := serve(handler, http.MethodGet, "/users?sort=username&email=bob@example.com&include_deleted=true", alice.ID, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var page struct {
+		Users []struct {
+			Username string `json:"username"`
+			Email    string `json:"email"`
+		} `json:"users"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Users, 2)
+	assert.Equal(t, "alice", page.Users[0].Username)
+	assert.Equal(t, "alice@example.com", page.Users[0].Email)
+	assert.Equal(t, "bob", page.Users[1].Username)
+	assert.Empty(t, page.Users[1].Email)
+}
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	t.Run("success-updates-own-account", func(t *testing.T) {
+		handler, db := newUserServer(t)
+		user := factory.MemUser(t, db, nil)
+
+		w := serve(handler, http.MethodPatch, "/users/"+user.ID, user.ID, `{"username": "renamed"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"username":"renamed"`)
+	})
+
+	t.Run("error-statuses", func(t *testing.T) {
+		handler, db := newUserServer(t)
+		user := factory.MemUser(t, db, &factory.UserMods{Username: "alice"})
+		other := factory.MemUser(t, db, nil)
+
+		requests := []struct {
+			name     string
+			caller   string
+			expected int
+		}{
+			{name: "missing-caller", caller: "", expected: http.StatusUnauthorized},
+			{name: "other-caller", caller: other.ID, expected: http.StatusForbidden},
+		}
+
+		for _, req := range requests {
+			t.Run(req.name, func(t *testing.T) {
+				w := serve(handler, http.MethodPatch, "/users/"+user.ID, req.caller, `{"username": "mallory"}`)
+				assert.Equal(t, req.expected, w.Code, w.Body.String())
+			})
+		}
+
+		// Not renamed
+		w := serve(handler, http.MethodGet, "/users/"+user.ID, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"username":"alice"`)
+	})
+}
+
+func TestUserHandler_DeleteUser(t *testing.T) {
+	t.Run("success-deletes-own-account", func(t *testing.T) {
+		handler, db := newUserServer(t)
+		user := factory.MemUser(t, db, nil)
+
+		w := serve(handler, http.MethodDelete, "/users/"+user.ID, user.ID, "")
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		w = serve(handler, http.MethodGet, "/users/"+user.ID, "", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("error-statuses", func(t *testing.T) {
+		handler, db := newUserServer(t)
+		user := factory.MemUser(t, db, &factory.UserMods{Username: "alice"})
+		other := factory.MemUser(t, db, nil)
+
+		requests := []struct {
+			name     string
+			target   string
+			caller   string
+			expected int
+		}{
+			{name: "missing-caller", target: "/users/" + user.ID, caller: "", expected: http.StatusUnauthorized},
+			{name: "other-caller", target: "/users/" + user.ID, caller: other.ID, expected: http.StatusForbidden},
+			{name: "other-caller-erase", target: "/users/" + user.ID + "?erase=true", caller: other.ID, expected: http.StatusForbidden},
+		}
+
+		for _, req := range requests {
+			t.Run(req.name, func(t *testing.T) {
+				w := serve(handler, http.MethodDelete, req.target, req.caller, "")
+				assert.Equal(t, req.expected, w.Code, w.Body.String())
+			})
+		}
+
+		// Neither deleted nor erased
+		w := serve(handler, http.MethodGet, "/users/"+user.ID, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"username":"alice"`)
+	})
+}
+
+func TestUserHandler_ExportUser(t *testing.T) {
+	t.Run("success-exports-own-data", func(t *testing.T) {
+		handler, db := newUserServer(t)
+		user := factory.MemUser(t, db, nil)
+
+		w := serve(handler, http.MethodGet, "/users/"+user.ID+"/export", user.ID, "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Disposition"), user.ID)
+
+		var archive exportlib.Archive
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &archive))
+		assert.ElementsMatch(t, []string{"profile", "rooms_created", "memberships"}, slices.Collect(maps.Keys(archive.Sections)))
+	})
+
+	t.Run("error-statuses", func(t *testing.T) {
+		handler, db := newUserServer(t)
+		user := factory.MemUser(t, db, nil)
+		other := factory.MemUser(t, db, nil)
+
+		requests := []struct {
+			name     string
+			caller   string
+			expected int
+		}{
+			{name: "missing-caller", caller: "", expected: http.StatusUnauthorized},
+			{name: "other-caller", caller: other.ID, expected: http.StatusForbidden},
+		}
+
+		for _, req := range requests {
+			t.Run(req.name, func(t *testing.T) {
+				w := serve(handler, http.MethodGet, "/users/"+user.ID+"/export", req.caller, "")
+				assert.Equal(t, req.expected, w.Code, w.Body.String())
+				assert.NotContains(t, w.Body.String(), user.Username)
+			})
+		}
+	})
+}
@@ -0,0 +1,305 @@
+// Package business orchestrates workflows that span several domains. Each
+// workflow runs in a single transaction so a failure leaves nothing half done.
+package business
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/exportlib"
+	"mlm/internal/util/querylib"
+	"mlm/models"
+)
+
+// ErrUserNotFound is returned when the user does not exist (or, for Delete,
+// is already deleted); it is an errlib.ErrNotFound
+var ErrUserNotFound = errlib.New(errlib.ErrNotFound, "user not found")
+
+// UserStore is the read side of users/store used by Users
+type UserStore interface {
+	Users(ctx context.Context, exec boil.ContextExecutor, filter users.UserQueryFilter) ([]*users.User, error)
+}
+
+// UserRepo is the write side of db/repo used by Users
+type UserRepo interface {
+	Update(ctx context.Context, exec boil.ContextExecutor, user *models.User, columns boil.Columns) (*models.User, error)
+}
+
+// RoomStore is the part of rooms/store used by Users
+type RoomStore interface {
+	Rooms(ctx context.Context, exec boil.ContextExecutor, filter rooms.RoomQueryFilter) ([]*rooms.Room, error)
+	Update(ctx context.Context, exec boil.ContextExecutor, update rooms.UpdateRoom) error
+}
+
+// RoomMemberStore is the read side of room_members/store used by Users
+type RoomMemberStore interface {
+	RoomMembers(ctx context.Context, exec boil.ContextExecutor, filter room_members.RoomMemberQueryFilter) ([]*room_members.RoomMembers, error)
+}
+
+// RoomMemberRepo is the write side of db/repo used by Users
+type RoomMemberRepo interface {
+	Update(ctx context.Context, exec boil.ContextExecutor, roomMember *models.RoomMember, columns boil.Columns) (*models.RoomMember, error)
+}
+
+// exportSections are the archive sections every export must contain: one
+// per table holding personal data
+var exportSections = []string{
+	users.ExportSection,
+	rooms.ExportSection,
+	room_members.ExportSection,
+}
+
+// Users implements account-wide workflows: soft delete, erasure and export
+type Users struct {
+	userStore   UserStore
+	userRepo    UserRepo
+	roomStore   RoomStore
+	memberStore RoomMemberStore
+	memberRepo  RoomMemberRepo
+	tx          txn.Transactor
+}
+
+// NewUsers creates the account workflows, failing fast on missing dependencies
+func NewUsers(
+	userStore UserStore,
+	userRepo UserRepo,
+	roomStore RoomStore,
+	memberStore RoomMemberStore,
+	memberRepo RoomMemberRepo,
+	tx txn.Transactor,
+) (*Users, error) {
+	if userStore == nil {
+		return nil, fmt.Errorf("users business: user store is required")
+	}
+	if userRepo == nil {
+		return nil, fmt.Errorf("users business: user repo is required")
+	}
+	if roomStore == nil {
+		return nil, fmt.Errorf("users business: room store is required")
+	}
+	if memberStore == nil {
+		return nil, fmt.Errorf("users business: room member store is required")
+	}
+	if memberRepo == nil {
+		return nil, fmt.Errorf("users business: room member repo is required")
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("users business: transactor is required")
+	}
+
+	return &Users{
+		userStore:   userStore,
+		userRepo:    userRepo,
+		roomStore:   roomStore,
+		memberStore: memberStore,
+		memberRepo:  memberRepo,
+		tx:          tx,
+	}, nil
+}
+
+// EraseResult summarises what Erase changed
+type EraseResult struct {
+	UserID            string
+	MembershipsClosed int
+	RoomsReassigned   int
+	RoomsClosed       int
+}
+
+// Delete soft-deletes the user: the account is hidden from the user store and
+// its active memberships are ended. Rooms the user created are left alone so
+// the deletion can still be undone by clearing deleted_at.
+func (b *Users) Delete(ctx context.Context, exec boil.ContextExecutor, userID string) error {
+	return b.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		user, err := b.findUser(ctx, tx, userID, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		_, err = b.userRepo.Update(ctx, tx, &models.User{
+			ID:        user.ID,
+			DeletedAt: null.TimeFrom(now),
+		}, boil.Whitelist(models.UserColumns.DeletedAt))
+		if err != nil {
+			return err
+		}
+
+		_, err = b.closeMemberships(ctx, tx, user.ID, now)
+		return err
+	})
+}
+
+// Erase permanently anonymizes the user. Username, display name, email and
+// gender are overwritten, active memberships are ended and each active room
+// the user created is handed to its longest-standing remaining member, or
+// closed when nobody is left. Membership rows are kept, now pointing at the
+// anonymous account, so room statistics stay intact. Erasing an already
+// soft-deleted user is allowed.
+func (b *Users) Erase(ctx context.Context, exec boil.ContextExecutor, userID string) (*EraseResult, error) {
+	var result *EraseResult
+	err := b.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		user, err := b.findUser(ctx, tx, userID, true)
+		if err != nil {
+			return err
+		}
+		result = &EraseResult{UserID: user.ID}
+
+		now := time.Now()
+		deletedAt := user.DeletedAt
+		if !deletedAt.Valid {
+			deletedAt = null.TimeFrom(now)
+		}
+
+		_, err = b.userRepo.Update(ctx, tx, &models.User{
+			ID:        user.ID,
+			Username:  erasedUsername(user.ID),
+			Gender:    null.StringFrom(string(users.GenderOther)),
+			DeletedAt: deletedAt,
+			ErasedAt:  null.TimeFrom(now),
+		}, boil.Whitelist(
+			models.UserColumns.Username,
+			models.UserColumns.DisplayName,
+			models.UserColumns.Email,
+			models.UserColumns.Gender,
+			models.UserColumns.DeletedAt,
+			models.UserColumns.ErasedAt,
+		))
+		if err != nil {
+			return err
+		}
+
+		if result.MembershipsClosed, err = b.closeMemberships(ctx, tx, user.ID, now); err != nil {
+			return err
+		}
+
+		return b.handOverRooms(ctx, tx, user.ID, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Export collects everything held about the user, soft-deleted or not, into
+// a personal data archive. Sections come from the exporters registered with
+// exportlib, and all reads share one transaction so the archive is a
+// consistent snapshot. It fails rather than return an archive missing one of
+// exportSections.
+func (b *Users) Export(ctx context.Context, exec boil.ContextExecutor, userID string) (*exportlib.Archive, error) {
+	if err := exportlib.Require(exportSections...); err != nil {
+		return nil, fmt.Errorf("export user: %w", err)
+	}
+
+	var archive *exportlib.Archive
+	err := b.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		user, err := b.findUser(ctx, tx, userID, true)
+		if err != nil {
+			return err
+		}
+
+		archive, err = exportlib.Export(ctx, tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// findUser loads one user by ID, returning ErrUserNotFound when absent
+func (b *Users) findUser(ctx context.Context, exec boil.ContextExecutor, userID string, includeDeleted bool) (*users.User, error) {
+	result, err := b.userStore.Users(ctx, exec, users.UserQueryFilter{
+		IDs:            []string{userID},
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return result[0], nil
+}
+
+// closeMemberships ends every active membership of the user
+func (b *Users) closeMemberships(ctx context.Context, exec boil.ContextExecutor, userID string, at time.Time) (int, error) {
+	active, err := b.memberStore.RoomMembers(ctx, exec, room_members.RoomMemberQueryFilter{
+		UserID: null.StringFrom(userID),
+		Active: null.BoolFrom(true),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, member := range active {
+		_, err := b.memberRepo.Update(ctx, exec, &models.RoomMember{
+			ID:     member.ID,
+			LeftAt: null.TimeFrom(at),
+		}, boil.Whitelist(models.RoomMemberColumns.LeftAt))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(active), nil
+}
+
+// handOverRooms reassigns or closes the active rooms the user created. It
+// must run after the user's own memberships are closed so they can't be
+// picked as their own successor.
+func (b *Users) handOverRooms(ctx context.Context, exec boil.ContextExecutor, userID string, result *EraseResult) error {
+	created, err := b.roomStore.Rooms(ctx, exec, rooms.RoomQueryFilter{
+		CreatedBy: null.StringFrom(userID),
+		IsActive:  null.BoolFrom(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, room := range created {
+		successors, err := b.memberStore.RoomMembers(ctx, exec, room_members.RoomMemberQueryFilter{
+			RoomID: null.StringFrom(room.ID),
+			Active: null.BoolFrom(true),
+			Sorts: []room_members.RoomMemberSort{
+				{Field: room_members.RoomMemberSortJoinedAt, Direction: querylib.SortAsc},
+			},
+			Limit: null.IntFrom(1),
+		})
+		if err != nil {
+			return err
+		}
+
+		update := rooms.UpdateRoom{IDs: []string{room.ID}}
+		if len(successors) > 0 {
+			update.CreatedBy = null.StringFrom(successors[0].UserID)
+			result.RoomsReassigned++
+		} else {
+			update.IsActive = null.BoolFrom(false)
+			result.RoomsClosed++
+		}
+		if err := b.roomStore.Update(ctx, exec, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// erasedUsername derives a unique placeholder username from the user's ID,
+// keeping the username column's uniqueness without revealing anything
+func erasedUsername(userID string) string {
+	return "erased_" + strings.ReplaceAll(userID, "-", "")
+}
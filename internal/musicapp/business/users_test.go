@@ -0,0 +1,238 @@
+package business_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/business"
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/repo"
+	"mlm/internal/musicapp/db/txn"
+	roommemberstore "mlm/internal/musicapp/lib/room_members/store"
+	roomstore "mlm/internal/musicapp/lib/rooms/store"
+	userstore "mlm/internal/musicapp/lib/users/store"
+	"mlm/internal/testsuite"
+	"mlm/models"
+)
+
+func newUsers(t *testing.T) *business.Users {
+	accounts, err := business.NewUsers(
+		userstore.New(),
+		repo.NewUserRepo(),
+		roomstore.New(),
+		roommemberstore.New(),
+		repo.NewRoomMember(),
+		txn.MySQL{},
+	)
+	require.NoError(t, err)
+	return accounts
+}
+
+func TestNewUsers(t *testing.T) {
+	_, err := business.NewUsers(nil, repo.NewUserRepo(), roomstore.New(), roommemberstore.New(), repo.NewRoomMember(), txn.MySQL{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "user store is required")
+}
+
+func TestUsers_Delete(t *testing.T) {
+	t.Run("success-soft-deletes-and-leaves-rooms", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		user := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+		member := factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMemberMods{
+			UserID: &user.ID,
+		})
+
+		err := newUsers(t).Delete(testSuite.Ctx, testSuite.BackendAppDb(), user.ID)
+		require.NoError(testSuite.T, err)
+
+		dbUser, err := models.FindUser(testSuite.Ctx, testSuite.BackendAppDb(), user.ID)
+		require.NoError(testSuite.T, err)
+		assert.True(testSuite.T, dbUser.DeletedAt.Valid)
+		assert.Equal(testSuite.T, user.Username, dbUser.Username)
+
+		dbMember, err := models.FindRoomMember(testSuite.Ctx, testSuite.BackendAppDb(), member.ID)
+		require.NoError(testSuite.T, err)
+		assert.True(testSuite.T, dbMember.LeftAt.Valid)
+	})
+
+	t.Run("error-already-deleted", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		user := factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			DeletedAt: null.TimeFrom(time.Now()),
+		})
+
+		err := newUsers(t).Delete(testSuite.Ctx, testSuite.BackendAppDb(), user.ID)
+		assert.ErrorIs(testSuite.T, err, business.ErrUserNotFound)
+	})
+}
+
+func TestUsers_Erase(t *testing.T) {
+	t.Run("success-anonymizes-user", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		user := factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			Username:    "alice",
+			Email:       "alice@example.com",
+			DisplayName: "Alice Smith",
+		})
+
+		result, err := newUsers(t).Erase(testSuite.Ctx, testSuite.BackendAppDb(), user.ID)
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, user.ID, result.UserID)
+
+		dbUser, err := models.FindUser(testSuite.Ctx, testSuite.BackendAppDb(), user.ID)
+		require.NoError(testSuite.T, err)
+		assert.NotContains(testSuite.T, dbUser.Username, "alice")
+		assert.False(testSuite.T, dbUser.Email.Valid)
+		assert.False(testSuite.T, dbUser.DisplayName.Valid)
+		assert.True(testSuite.T, dbUser.DeletedAt.Valid)
+		assert.True(testSuite.T, dbUser.ErasedAt.Valid)
+	})
+
+	t.Run("success-reassigns-room-to-longest-member", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		creator := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+		room := factory.Room(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMods{
+			CreatedBy: &creator.ID,
+		})
+		factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMemberMods{
+			RoomID: &room.ID,
+			UserID: &creator.ID,
+		})
+		veteran := factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMemberMods{
+			RoomID:   &room.ID,
+			JoinedAt: null.TimeFrom(time.Now().Add(-2 * time.Hour)),
+		})
+		factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMemberMods{
+			RoomID:   &room.ID,
+			JoinedAt: null.TimeFrom(time.Now().Add(-1 * time.Hour)),
+		})
+
+		result, err := newUsers(t).Erase(testSuite.Ctx, testSuite.BackendAppDb(), creator.ID)
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, 1, result.MembershipsClosed)
+		assert.Equal(testSuite.T, 1, result.RoomsReassigned)
+		assert.Equal(testSuite.T, 0, result.RoomsClosed)
+
+		dbRoom, err := models.FindRoom(testSuite.Ctx, testSuite.BackendAppDb(), room.ID)
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, veteran.UserID, dbRoom.CreatedBy)
+		assert.True(testSuite.T, dbRoom.IsActive.Bool)
+
+		// Membership history is kept for room statistics
+		count, err := models.RoomMembers(models.RoomMemberWhere.RoomID.EQ(room.ID)).Count(testSuite.Ctx, testSuite.BackendAppDb())
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, int64(3), count)
+	})
+
+	t.Run("success-closes-empty-room", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		creator := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+		room := factory.Room(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMods{
+			CreatedBy: &creator.ID,
+		})
+
+		result, err := newUsers(t).Erase(testSuite.Ctx, testSuite.BackendAppDb(), creator.ID)
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, 1, result.RoomsClosed)
+
+		dbRoom, err := models.FindRoom(testSuite.Ctx, testSuite.BackendAppDb(), room.ID)
+		require.NoError(testSuite.T, err)
+		assert.False(testSuite.T, dbRoom.IsActive.Bool)
+	})
+
+	t.Run("error-user-not-found", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		_, err := newUsers(t).Erase(testSuite.Ctx, testSuite.BackendAppDb(), "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11")
+		assert.ErrorIs(testSuite.T, err, business.ErrUserNotFound)
+	})
+}
+
+func TestUsers_Export(t *testing.T) {
+	t.Run("success-includes-every-domain", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		user := factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			Username: "alice",
+		})
+		room := factory.Room(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMods{
+			CreatedBy: &user.ID,
+		})
+		factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMemberMods{
+			RoomID: &room.ID,
+			UserID: &user.ID,
+			LeftAt: null.TimeFrom(time.Now()),
+		})
+
+		archive, err := newUsers(t).Export(testSuite.Ctx, testSuite.BackendAppDb(), user.ID)
+		require.NoError(testSuite.T, err)
+
+		data, err := json.Marshal(archive.Sections)
+		require.NoError(testSuite.T, err)
+
+		var sections struct {
+			Profile struct {
+				Username string `json:"username"`
+			} `json:"profile"`
+			RoomsCreated []struct {
+				ID string `json:"id"`
+			} `json:"rooms_created"`
+			Memberships []struct {
+				LeftAt *time.Time `json:"left_at"`
+			} `json:"memberships"`
+		}
+		require.NoError(testSuite.T, json.Unmarshal(data, &sections))
+		assert.Equal(testSuite.T, "alice", sections.Profile.Username)
+		require.Len(testSuite.T, sections.RoomsCreated, 1)
+		assert.Equal(testSuite.T, room.ID, sections.RoomsCreated[0].ID)
+		require.Len(testSuite.T, sections.Memberships, 1)
+		assert.NotNil(testSuite.T, sections.Memberships[0].LeftAt)
+	})
+
+	t.Run("success-soft-deleted-user", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		user := factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			DeletedAt: null.TimeFrom(time.Now()),
+		})
+
+		archive, err := newUsers(t).Export(testSuite.Ctx, testSuite.BackendAppDb(), user.ID)
+		require.NoError(testSuite.T, err)
+		assert.NotNil(testSuite.T, archive.Sections["profile"])
+	})
+
+	t.Run("error-user-not-found", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		_, err := newUsers(t).Export(testSuite.Ctx, testSuite.BackendAppDb(), "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11")
+		assert.ErrorIs(testSuite.T, err, business.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,273 @@
+// Package bulk writes many rows with multi-row INSERT statements, split into
+// chunks that stay under MySQL's limits.
+package bulk
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/util/errlib"
+)
+
+// MaxPlaceholders is the most ? placeholders MySQL accepts in one prepared
+// statement
+const MaxPlaceholders = 65535
+
+// DefaultMaxBytes bounds the estimated size of one statement. It is MySQL
+// 5.7's default max_allowed_packet, well under 8.0's 64 MiB, so chunks fit a
+// server left at either default.
+const DefaultMaxBytes = 4 << 20
+
+// argOverhead approximates the per-value cost beyond its data: the "?, " in
+// the query text plus the type and length bytes of the binary protocol
+const argOverhead = 8
+
+// Writer writes rows of Columns into Table. The zero value of MaxRows and
+// MaxBytes means "as many as the limits allow".
+type Writer struct {
+	Table   string
+	Columns []string
+
+	// MaxRows caps rows per statement; it is lowered further if the
+	// placeholder limit requires
+	MaxRows int
+	// MaxBytes caps the estimated statement size, DefaultMaxBytes when 0
+	MaxBytes int
+}
+
+// Result counts what a write did.
+//
+// MySQL reports affected rows for INSERT ... ON DUPLICATE KEY UPDATE as 1 per
+// inserted row, 2 per updated row and 0 per duplicate whose values didn't
+// change, and nothing else. Inserted and Updated are derived from that:
+//   - Insert, and Upsert without update columns, are exact: duplicates are
+//     left alone and counted in Unchanged.
+//   - Upsert with update columns assumes every duplicate changed. A
+//     duplicate written with identical values is indistinguishable from an
+//     extra insert, so treat Inserted and Updated as approximate there and
+//     Unchanged is always 0.
+type Result struct {
+	Rows      int64
+	Inserted  int64
+	Updated   int64
+	Unchanged int64
+}
+
+func (r *Result) add(o Result) {
+	r.Rows += o.Rows
+	r.Inserted += o.Inserted
+	r.Updated += o.Updated
+	r.Unchanged += o.Unchanged
+}
+
+// Statement is one chunk of a bulk write, ready to execute
+type Statement struct {
+	Query string
+	Args  []interface{}
+	Rows  int
+}
+
+// Insert writes rows with plain INSERTs, failing on any duplicate key
+func (w Writer) Insert(ctx context.Context, exec boil.ContextExecutor, rows [][]interface{}) (Result, error) {
+	statements, err := w.statements(rows, nil, false)
+	if err != nil {
+		return Result{}, err
+	}
+	return w.exec(ctx, exec, statements, false)
+}
+
+// Upsert writes rows with INSERT ... ON DUPLICATE KEY UPDATE. On a duplicate
+// key the updateColumns are overwritten with the new values; with no
+// updateColumns the existing row is kept as it is, which makes Upsert a
+// safe way to re-run an import.
+func (w Writer) Upsert(ctx context.Context, exec boil.ContextExecutor, rows [][]interface{}, updateColumns ...string) (Result, error) {
+	statements, err := w.Statements(rows, updateColumns...)
+	if err != nil {
+		return Result{}, err
+	}
+	return w.exec(ctx, exec, statements, len(updateColumns) > 0)
+}
+
+// Statements builds the chunked upsert statements Upsert would run, without
+// running them
+func (w Writer) Statements(rows [][]interface{}, updateColumns ...string) ([]Statement, error) {
+	return w.statements(rows, updateColumns, true)
+}
+
+// exec runs every statement in one transaction so a failed chunk leaves
+// nothing behind
+func (w Writer) exec(ctx context.Context, exec boil.ContextExecutor, statements []Statement, updates bool) (Result, error) {
+	var total Result
+	if len(statements) == 0 {
+		return total, nil
+	}
+
+	err := txn.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		total = Result{}
+		for _, stmt := range statements {
+			res, err := tx.ExecContext(ctx, stmt.Query, stmt.Args...)
+			if err != nil {
+				return fmt.Errorf("bulk write %s: %w", w.Table, errlib.FromDB(err))
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("bulk write %s: rows affected: %w", w.Table, err)
+			}
+			total.add(countRows(int64(stmt.Rows), affected, updates))
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return total, nil
+}
+
+// countRows splits a statement's affected rows into inserts and updates; see
+// Result for when this is exact
+func countRows(rows, affected int64, updates bool) Result {
+	if !updates {
+		return Result{Rows: rows, Inserted: affected, Unchanged: rows - affected}
+	}
+
+	updated := affected - rows
+	if updated < 0 {
+		updated = 0
+	}
+	return Result{Rows: rows, Inserted: rows - updated, Updated: updated}
+}
+
+func (w Writer) statements(rows [][]interface{}, updateColumns []string, upsert bool) ([]Statement, error) {
+	if w.Table == "" {
+		return nil, fmt.Errorf("bulk: table is required")
+	}
+	if len(w.Columns) == 0 {
+		return nil, fmt.Errorf("bulk %s: columns are required", w.Table)
+	}
+	for _, column := range updateColumns {
+		if !slices.Contains(w.Columns, column) {
+			return nil, fmt.Errorf("bulk %s: update column %q is not written", w.Table, column)
+		}
+	}
+	for i, row := range rows {
+		if len(row) != len(w.Columns) {
+			return nil, fmt.Errorf("bulk %s: row %d has %d values, want %d", w.Table, i, len(row), len(w.Columns))
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	prefix := w.insertPrefix()
+	suffix := ""
+	if upsert {
+		suffix = w.upsertSuffix(updateColumns)
+	}
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(w.Columns)), ", ") + ")"
+
+	maxRows := MaxPlaceholders / len(w.Columns)
+	if w.MaxRows > 0 && w.MaxRows < maxRows {
+		maxRows = w.MaxRows
+	}
+	maxBytes := w.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	var statements []Statement
+	var chunk [][]interface{}
+	size := len(prefix) + len(suffix)
+
+	flush := func() {
+		placeholders := make([]string, len(chunk))
+		args := make([]interface{}, 0, len(chunk)*len(w.Columns))
+		for i, row := range chunk {
+			placeholders[i] = rowPlaceholder
+			args = append(args, row...)
+		}
+		statements = append(statements, Statement{
+			Query: prefix + strings.Join(placeholders, ", ") + suffix,
+			Args:  args,
+			Rows:  len(chunk),
+		})
+		chunk = nil
+		size = len(prefix) + len(suffix)
+	}
+
+	for _, row := range rows {
+		rowSize := rowBytes(row)
+		// A single row larger than maxBytes still gets its own statement;
+		// the server is the one to refuse it
+		if len(chunk) > 0 && (len(chunk) == maxRows || size+rowSize > maxBytes) {
+			flush()
+		}
+		chunk = append(chunk, row)
+		size += rowSize
+	}
+	flush()
+
+	return statements, nil
+}
+
+func (w Writer) insertPrefix() string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES ", quote(w.Table), quoteAll(w.Columns))
+}
+
+// upsertSuffix builds the ON DUPLICATE KEY UPDATE clause. Without update
+// columns the first column is assigned to itself: a no-op that MySQL reports
+// as 0 affected rows, which keeps the counts exact.
+func (w Writer) upsertSuffix(updateColumns []string) string {
+	if len(updateColumns) == 0 {
+		column := quote(w.Columns[0])
+		return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", column, column)
+	}
+
+	sets := make([]string, len(updateColumns))
+	for i, column := range updateColumns {
+		sets[i] = fmt.Sprintf("%s = VALUES(%s)", quote(column), quote(column))
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+// rowBytes estimates how much a row adds to the statement
+func rowBytes(row []interface{}) int {
+	size := 0
+	for _, v := range row {
+		size += argBytes(v) + argOverhead
+	}
+	return size
+}
+
+func argBytes(v interface{}) int {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil {
+			v = value
+		}
+	}
+	switch value := v.(type) {
+	case string:
+		return len(value)
+	case []byte:
+		return len(value)
+	}
+	return 8
+}
+
+func quote(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func quoteAll(identifiers []string) string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = quote(identifier)
+	}
+	return strings.Join(quoted, ", ")
+}
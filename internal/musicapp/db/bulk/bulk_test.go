@@ -0,0 +1,208 @@
+package bulk_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/bulk"
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/testsuite"
+	"mlm/models"
+)
+
+func rowsOf(n, columns int, value interface{}) [][]interface{} {
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		rows[i] = make([]interface{}, columns)
+		for j := range rows[i] {
+			rows[i][j] = value
+		}
+	}
+	return rows
+}
+
+func TestWriter_Statements(t *testing.T) {
+	testCases := []struct {
+		name          string
+		writer        bulk.Writer
+		rows          [][]interface{}
+		updateColumns []string
+		expectedRows  []int
+		expectedQuery string
+		expectedError string
+	}{
+		{
+			name:          "success-single-statement",
+			writer:        bulk.Writer{Table: "users", Columns: []string{"id", "username"}},
+			rows:          rowsOf(2, 2, "x"),
+			expectedRows:  []int{2},
+			expectedQuery: "INSERT INTO `users` (`id`, `username`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `id` = `id`",
+		},
+		{
+			name:          "success-update-columns",
+			writer:        bulk.Writer{Table: "users", Columns: []string{"id", "username", "email"}},
+			rows:          rowsOf(1, 3, "x"),
+			updateColumns: []string{"username", "email"},
+			expectedRows:  []int{1},
+			expectedQuery: "INSERT INTO `users` (`id`, `username`, `email`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `username` = VALUES(`username`), `email` = VALUES(`email`)",
+		},
+		{
+			name:         "success-chunks-by-max-rows",
+			writer:       bulk.Writer{Table: "users", Columns: []string{"id"}, MaxRows: 2},
+			rows:         rowsOf(5, 1, "x"),
+			expectedRows: []int{2, 2, 1},
+		},
+		{
+			name:         "success-chunks-by-placeholder-limit",
+			writer:       bulk.Writer{Table: "users", Columns: []string{"a", "b", "c", "d", "e"}},
+			rows:         rowsOf(bulk.MaxPlaceholders/5+1, 5, 1),
+			expectedRows: []int{bulk.MaxPlaceholders / 5, 1},
+		},
+		{
+			name:         "success-chunks-by-bytes",
+			writer:       bulk.Writer{Table: "users", Columns: []string{"id"}, MaxBytes: 2500},
+			rows:         rowsOf(5, 1, strings.Repeat("x", 1000)),
+			expectedRows: []int{2, 2, 1},
+		},
+		{
+			name:         "success-oversized-row-gets-own-statement",
+			writer:       bulk.Writer{Table: "users", Columns: []string{"id"}, MaxBytes: 10},
+			rows:         rowsOf(2, 1, null.StringFrom(strings.Repeat("x", 100))),
+			expectedRows: []int{1, 1},
+		},
+		{
+			name:         "success-no-rows",
+			writer:       bulk.Writer{Table: "users", Columns: []string{"id"}},
+			rows:         nil,
+			expectedRows: nil,
+		},
+		{
+			name:          "error-missing-table",
+			writer:        bulk.Writer{Columns: []string{"id"}},
+			rows:          rowsOf(1, 1, "x"),
+			expectedError: "table is required",
+		},
+		{
+			name:          "error-missing-columns",
+			writer:        bulk.Writer{Table: "users"},
+			rows:          rowsOf(1, 1, "x"),
+			expectedError: "columns are required",
+		},
+		{
+			name:          "error-row-width",
+			writer:        bulk.Writer{Table: "users", Columns: []string{"id", "username"}},
+			rows:          [][]interface{}{{"a", "b"}, {"c"}},
+			expectedError: "row 1 has 1 values, want 2",
+		},
+		{
+			name:          "error-unknown-update-column",
+			writer:        bulk.Writer{Table: "users", Columns: []string{"id"}},
+			rows:          rowsOf(1, 1, "x"),
+			updateColumns: []string{"email"},
+			expectedError: `update column "email" is not written`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			statements, err := tc.writer.Statements(tc.rows, tc.updateColumns...)
+
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			var rows []int
+			for _, stmt := range statements {
+				rows = append(rows, stmt.Rows)
+				assert.Len(t, stmt.Args, stmt.Rows*len(tc.writer.Columns))
+				assert.Equal(t, len(stmt.Args), strings.Count(stmt.Query, "?"))
+			}
+			assert.Equal(t, tc.expectedRows, rows)
+
+			if tc.expectedQuery != "" {
+				require.Len(t, statements, 1)
+				assert.Equal(t, tc.expectedQuery, statements[0].Query)
+			}
+		})
+	}
+}
+
+var userWriter = bulk.Writer{
+	Table:   models.TableNames.Users,
+	Columns: []string{models.UserColumns.ID, models.UserColumns.Username, models.UserColumns.DisplayName},
+	MaxRows: 2,
+}
+
+func TestWriter_Upsert(t *testing.T) {
+	t.Run("success-counts-inserts-and-updates", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		existing := factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			Username:    "alice",
+			DisplayName: "Alice",
+		})
+
+		result, err := userWriter.Upsert(testSuite.Ctx, testSuite.BackendAppDb(), [][]interface{}{
+			{existing.ID, "alice", "Alice Smith"},
+			{dbid.New(), "bob", "Bob"},
+			{dbid.New(), "charlie", "Charlie"},
+		}, models.UserColumns.DisplayName)
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, bulk.Result{Rows: 3, Inserted: 2, Updated: 1}, result)
+
+		dbUser, err := models.FindUser(testSuite.Ctx, testSuite.BackendAppDb(), existing.ID)
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, "Alice Smith", dbUser.DisplayName.String)
+	})
+
+	t.Run("success-keeps-existing-without-update-columns", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		existing := factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			Username:    "alice",
+			DisplayName: "Alice",
+		})
+
+		result, err := userWriter.Upsert(testSuite.Ctx, testSuite.BackendAppDb(), [][]interface{}{
+			{existing.ID, "alice", "Alice Smith"},
+			{dbid.New(), "bob", "Bob"},
+		})
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, bulk.Result{Rows: 2, Inserted: 1, Unchanged: 1}, result)
+
+		dbUser, err := models.FindUser(testSuite.Ctx, testSuite.BackendAppDb(), existing.ID)
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, "Alice", dbUser.DisplayName.String)
+	})
+
+	t.Run("error-rolls-back-every-chunk", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		// The third row lands in the second chunk and breaks the NOT NULL
+		// username, so the first chunk must not survive either
+		_, err := userWriter.Insert(testSuite.Ctx, testSuite.BackendAppDb(), [][]interface{}{
+			{dbid.New(), "bob", "Bob"},
+			{dbid.New(), "charlie", "Charlie"},
+			{dbid.New(), nil, "Nobody"},
+		})
+		require.Error(testSuite.T, err)
+
+		count, err := models.Users().Count(testSuite.Ctx, testSuite.BackendAppDb())
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, int64(0), count)
+	})
+}
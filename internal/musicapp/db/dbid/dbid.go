@@ -0,0 +1,38 @@
+// Package dbid generates and validates the UUID primary keys used by every
+// table. IDs are stored as their canonical 36-character string form.
+package dbid
+
+import (
+	"github.com/gofrs/uuid"
+
+	"mlm/internal/util/errlib"
+)
+
+// New returns a fresh random (version 4) UUID in canonical form.
+func New() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// Parse validates id and returns its canonical lower-case form, so lookups
+// match regardless of how the caller spelled the UUID. Invalid IDs are
+// reported as errlib.ErrInvalidID.
+func Parse(id string) (string, error) {
+	u, err := uuid.FromString(id)
+	if err != nil {
+		return "", errlib.New(errlib.ErrInvalidID, "not a valid UUID: %q", id)
+	}
+	return u.String(), nil
+}
+
+// ParseAll applies Parse to every id, stopping at the first invalid one.
+func ParseAll(ids []string) ([]string, error) {
+	parsed := make([]string, len(ids))
+	for i, id := range ids {
+		p, err := Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = p
+	}
+	return parsed, nil
+}
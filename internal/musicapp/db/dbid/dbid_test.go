@@ -0,0 +1,46 @@
+package dbid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/util/errlib"
+)
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	assert.Len(t, a, 36)
+	assert.NotEqual(t, a, b)
+
+	parsed, err := Parse(a)
+	require.NoError(t, err)
+	assert.Equal(t, a, parsed)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{name: "canonical", input: "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11", expected: "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11"},
+		{name: "upper-case", input: "0B6F4A3E-5D1C-4A5E-9A8B-2F1E7C9D0A11", expected: "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11"},
+		{name: "legacy-integer", input: "42", err: true},
+		{name: "empty", input: "", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.err {
+				assert.ErrorIs(t, err, errlib.ErrInvalidID)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package factory
+
+import (
+	"context"
+	"testing"
+
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/models"
+)
+
+// MemUser creates a test user in an in-memory database; see User
+func MemUser(
+	t *testing.T,
+	db *memdb.DB,
+	mods *UserMods,
+) *models.User {
+	user, err := memdb.NewUserRepo(db).Insert(context.Background(), nil, newUser(mods))
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return user
+}
+
+// MemRoom creates a test room in an in-memory database; see Room
+func MemRoom(
+	t *testing.T,
+	db *memdb.DB,
+	mods *RoomMods,
+) *models.Room {
+	if mods == nil {
+		mods = &RoomMods{}
+	}
+
+	// Creator
+	if mods.CreatedBy == nil {
+		creator := MemUser(t, db, nil)
+		mods.CreatedBy = &creator.ID
+	}
+
+	room, err := memdb.NewRoomRepo(db).Insert(context.Background(), nil, newRoom(mods))
+	if err != nil {
+		t.Fatalf("failed to create room: %v", err)
+	}
+
+	return room
+}
+
+// MemRoomMember creates a test room membership in an in-memory database;
+// see RoomMember
+func MemRoomMember(
+	t *testing.T,
+	db *memdb.DB,
+	mods *RoomMemberMods,
+) *models.RoomMember {
+	if mods == nil {
+		mods = &RoomMemberMods{}
+	}
+
+	// Room
+	if mods.RoomID == nil {
+		room := MemRoom(t, db, nil)
+		mods.RoomID = &room.ID
+	}
+
+	// User
+	if mods.UserID == nil {
+		user := MemUser(t, db, nil)
+		mods.UserID = &user.ID
+	}
+
+	roomMember, err := memdb.NewRoomMemberRepo(db).Insert(context.Background(), nil, newRoomMember(mods))
+	if err != nil {
+		t.Fatalf("failed to create room member: %v", err)
+	}
+
+	return roomMember
+}
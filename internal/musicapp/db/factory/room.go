@@ -0,0 +1,69 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/models"
+)
+
+// RoomMods - optional overrides for room creation
+type RoomMods struct {
+	Name      string
+	CreatedBy *string
+	IsActive  null.Bool
+}
+
+// Room creates a test room with optional overrides.
+// A creator user is created when CreatedBy is not provided.
+func Room(
+	t *testing.T,
+	exec boil.ContextExecutor,
+	mods *RoomMods,
+) *models.Room {
+	if mods == nil {
+		mods = &RoomMods{}
+	}
+
+	// Creator
+	if mods.CreatedBy == nil {
+		creator := User(t, exec, nil)
+		mods.CreatedBy = &creator.ID
+	}
+
+	room := newRoom(mods)
+
+	err := room.Insert(context.Background(), exec, boil.Infer())
+	if err != nil {
+		t.Fatalf("failed to create room: %v", err)
+	}
+
+	return room
+}
+
+// newRoom builds the room Room and MemRoom insert; mods.CreatedBy must be set
+func newRoom(mods *RoomMods) *models.Room {
+	// Name
+	if mods.Name == "" {
+		mods.Name = fmt.Sprintf("room_%d", time.Now().UnixNano())
+	}
+
+	// Active by default
+	if !mods.IsActive.Valid {
+		mods.IsActive = null.BoolFrom(true)
+	}
+
+	return &models.Room{
+		ID:        dbid.New(),
+		Name:      mods.Name,
+		CreatedBy: *mods.CreatedBy,
+		IsActive:  mods.IsActive,
+		CreatedAt: null.TimeFrom(time.Now()),
+	}
+}
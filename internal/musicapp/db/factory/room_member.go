@@ -0,0 +1,72 @@
+package factory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/models"
+)
+
+// RoomMemberMods - optional overrides for room member creation
+type RoomMemberMods struct {
+	RoomID   *string
+	UserID   *string
+	JoinedAt null.Time
+	LeftAt   null.Time
+}
+
+// RoomMember creates a test room membership with optional overrides.
+// A room and a user are created when their IDs are not provided.
+// Memberships are active unless LeftAt is set.
+func RoomMember(
+	t *testing.T,
+	exec boil.ContextExecutor,
+	mods *RoomMemberMods,
+) *models.RoomMember {
+	if mods == nil {
+		mods = &RoomMemberMods{}
+	}
+
+	// Room
+	if mods.RoomID == nil {
+		room := Room(t, exec, nil)
+		mods.RoomID = &room.ID
+	}
+
+	// User
+	if mods.UserID == nil {
+		user := User(t, exec, nil)
+		mods.UserID = &user.ID
+	}
+
+	roomMember := newRoomMember(mods)
+
+	err := roomMember.Insert(context.Background(), exec, boil.Infer())
+	if err != nil {
+		t.Fatalf("failed to create room member: %v", err)
+	}
+
+	return roomMember
+}
+
+// newRoomMember builds the membership RoomMember and MemRoomMember insert;
+// mods.RoomID and mods.UserID must be set
+func newRoomMember(mods *RoomMemberMods) *models.RoomMember {
+	// Joined at
+	if !mods.JoinedAt.Valid {
+		mods.JoinedAt = null.TimeFrom(time.Now())
+	}
+
+	return &models.RoomMember{
+		ID:       dbid.New(),
+		RoomID:   *mods.RoomID,
+		UserID:   *mods.UserID,
+		JoinedAt: mods.JoinedAt,
+		LeftAt:   mods.LeftAt,
+	}
+}
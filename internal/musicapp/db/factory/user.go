@@ -3,22 +3,25 @@ package factory
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aarondl/null/v8"
 	"github.com/aarondl/sqlboiler/v4/boil"
 
+	"mlm/internal/musicapp/db/dbid"
 	"mlm/models"
 )
 
 // UserMods - optional overrides for user creation
 type UserMods struct {
-	ID          *uint64
+	ID          *string
 	Username    string
 	Email       string
 	DisplayName string
 	Gender      string
+	DeletedAt   null.Time // soft-deleted when set
 }
 
 // User creates a test user with optional overrides
@@ -27,6 +30,18 @@ func User(
 	exec boil.ContextExecutor,
 	mods *UserMods,
 ) *models.User {
+	user := newUser(mods)
+
+	err := user.Insert(context.Background(), exec, boil.Infer())
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return user
+}
+
+// newUser builds the user User and MemUser insert
+func newUser(mods *UserMods) *models.User {
 	if mods == nil {
 		mods = &UserMods{}
 	}
@@ -41,7 +56,7 @@ func User(
 
 	// Email
 	if mods.Email == "" {
-		mods.Email = fmt.Sprintf("%s@example.com", mods.Username)
+		mods.Email = fmt.Sprintf("%s@example.com", strings.ToLower(mods.Username))
 	}
 
 	// Display name
@@ -55,10 +70,13 @@ func User(
 	}
 
 	user := &models.User{
+		ID:          dbid.New(),
 		Username:    mods.Username,
+		Email:       null.StringFrom(mods.Email),
 		DisplayName: null.StringFrom(mods.DisplayName),
 		Gender:      null.StringFrom(mods.Gender),
 		CreatedAt:   null.TimeFrom(time.Now()),
+		DeletedAt:   mods.DeletedAt,
 	}
 
 	// If ID is provided, set it (for specific test cases)
@@ -66,11 +84,6 @@ func User(
 		user.ID = *mods.ID
 	}
 
-	err := user.Insert(context.Background(), exec, boil.Infer())
-	if err != nil {
-		t.Fatalf("failed to create user: %v", err)
-	}
-
 	return user
 }
 
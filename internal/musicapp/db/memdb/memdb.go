@@ -0,0 +1,323 @@
+// Package memdb is an in-memory backend for the users, rooms and room_members
+// stores and repos, for tests that should not need MySQL.
+//
+// It follows the schema closely enough to pass the db/storetest conformance
+// suite, which also runs against MySQL:
+//   - unique and foreign keys are enforced, failing with the same MySQL
+//     errors, so errlib.FromDB gives them the same kinds;
+//   - TIMESTAMP columns are rounded to the second and read back in UTC;
+//   - text compares case-insensitively, like the default _ci collation.
+//     Accent folding and the collation's order of punctuation are not
+//     modelled, so tests should not sort on them.
+//
+// Executors passed to its methods are ignored. DB.WithTx undoes a failed
+// unit of work but does not isolate concurrent writers.
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/go-sql-driver/mysql"
+
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/util/querylib"
+	"mlm/internal/util/validationlib"
+	"mlm/models"
+)
+
+// MySQL errors raised for constraint violations
+const (
+	errBadNull         uint16 = 1048
+	errBadField        uint16 = 1054
+	errDuplicateEntry  uint16 = 1062
+	errDataTruncated   uint16 = 1265
+	errDataTooLong     uint16 = 1406
+	errNoReferencedRow uint16 = 1452
+)
+
+var _ txn.Transactor = (*DB)(nil)
+
+// DB holds the users, rooms and room_members tables. Rows are keyed by their
+// lower-cased ID, as the primary keys compare case-insensitively.
+type DB struct {
+	mu          sync.Mutex
+	users       map[string]*models.User
+	rooms       map[string]*models.Room
+	roomMembers map[string]*models.RoomMember
+}
+
+// New creates an empty database
+func New() *DB {
+	return &DB{
+		users:       map[string]*models.User{},
+		rooms:       map[string]*models.Room{},
+		roomMembers: map[string]*models.RoomMember{},
+	}
+}
+
+// WithTx runs fn and undoes every change it made if it fails or panics,
+// like txn.WithTx does with a transaction or savepoint. exec is passed
+// through to fn.
+func (db *DB) WithTx(ctx context.Context, exec boil.ContextExecutor, fn func(tx boil.ContextExecutor) error) (err error) {
+	snapshot := db.snapshot()
+
+	defer func() {
+		if p := recover(); p != nil {
+			db.restore(snapshot)
+			panic(p)
+		}
+	}()
+
+	if err := fn(exec); err != nil {
+		db.restore(snapshot)
+		return err
+	}
+	return nil
+}
+
+// tables is a copy of every table, taken by WithTx
+type tables struct {
+	users       map[string]*models.User
+	rooms       map[string]*models.Room
+	roomMembers map[string]*models.RoomMember
+}
+
+func (db *DB) snapshot() tables {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return tables{
+		users:       cloneTable(db.users),
+		rooms:       cloneTable(db.rooms),
+		roomMembers: cloneTable(db.roomMembers),
+	}
+}
+
+func (db *DB) restore(t tables) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.users = t.users
+	db.rooms = t.rooms
+	db.roomMembers = t.roomMembers
+}
+
+// cloneTable copies a table, row by row, so later writes don't reach it
+func cloneTable[T any](table map[string]*T) map[string]*T {
+	clone := make(map[string]*T, len(table))
+	for key, row := range table {
+		copied := *row
+		clone[key] = &copied
+	}
+	return clone
+}
+
+// key is the map key of a row ID
+func key(id string) string {
+	return strings.ToLower(id)
+}
+
+// timestamp rounds t the way a TIMESTAMP column stores it
+func timestamp(t null.Time) null.Time {
+	if !t.Valid {
+		return t
+	}
+	return null.TimeFrom(t.Time.Round(time.Second).UTC())
+}
+
+// inRange reports whether t falls in [from, to). A NULL t matches no bound,
+// as a NULL comparison is never true.
+func inRange(t null.Time, from, to null.Time) bool {
+	if from.Valid && (!t.Valid || t.Time.Before(from.Time)) {
+		return false
+	}
+	if to.Valid && (!t.Valid || !t.Time.Before(to.Time)) {
+		return false
+	}
+	return true
+}
+
+// sameTime compares a TIMESTAMP column with a parameter; NULL never matches
+func sameTime(t null.Time, value time.Time) bool {
+	return t.Valid && t.Time.Equal(value)
+}
+
+// hasPrefix and contains match like querylib.WhereStartsWith and
+// querylib.WhereContains: case-insensitive, with no wildcards
+func hasPrefix(s, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+}
+
+func contains(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// inFold reports whether s equals any of values, ignoring case
+func inFold(s string, values []string) bool {
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectRows orders rows that passed the filter by keyset, then applies the
+// cursor, offset and limit, matching the SQL stores' query mods. value
+// returns a row's value for a keyset column.
+func selectRows[T any](
+	rows []T,
+	value func(row T, column string) interface{},
+	keyset *querylib.Keyset,
+	after null.String,
+	offset null.Int,
+	limit null.Int,
+) ([]T, error) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return keyset.Compare(sortValues(rows[i], value, keyset), sortValues(rows[j], value, keyset)) < 0
+	})
+
+	if after.Valid {
+		if offset.Valid {
+			return nil, validationlib.NewValidationError("offset", fmt.Sprint(offset.Int),
+				"can't be combined with a cursor")
+		}
+		cursor, err := keyset.Values(after.String)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := rows[:0]
+		for _, row := range rows {
+			if keyset.Compare(sortValues(row, value, keyset), cursor) > 0 {
+				remaining = append(remaining, row)
+			}
+		}
+		rows = remaining
+	}
+
+	if offset.Valid && offset.Int > 0 {
+		if offset.Int >= len(rows) {
+			return nil, nil
+		}
+		rows = rows[offset.Int:]
+	}
+	if limit.Valid && limit.Int >= 0 && limit.Int < len(rows) {
+		rows = rows[:limit.Int]
+	}
+
+	return rows, nil
+}
+
+// selectPage is selectRows for a page query: it returns at most
+// querylib.PageSize(limit) rows and the cursor for the next page, empty on
+// the last one
+func selectPage[T any](
+	rows []T,
+	value func(row T, column string) interface{},
+	keyset *querylib.Keyset,
+	after null.String,
+	offset null.Int,
+	limit null.Int,
+) ([]T, string, error) {
+	pageSize := querylib.PageSize(limit)
+
+	rows, err := selectRows(rows, value, keyset, after, offset, null.IntFrom(pageSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(rows) <= pageSize {
+		return rows, "", nil
+	}
+
+	rows = rows[:pageSize]
+	cursor, err := keyset.Cursor(sortValues(rows[pageSize-1], value, keyset)...)
+	if err != nil {
+		return nil, "", err
+	}
+	return rows, cursor, nil
+}
+
+// sortValues returns a row's values for every keyset column, in order
+func sortValues[T any](row T, value func(row T, column string) interface{}, keyset *querylib.Keyset) []interface{} {
+	columns := keyset.Columns()
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = value(row, column)
+	}
+	return values
+}
+
+// updateColumns resolves the columns a repo Update writes: the whitelist, or
+// every column but the primary key for boil.Infer()
+func updateColumns(columns boil.Columns, all []string, table string) ([]string, error) {
+	var cols []string
+	switch {
+	case columns.IsWhitelist():
+		cols = columns.Cols
+	case columns.IsInfer():
+		cols = all[1:]
+	default:
+		return nil, fmt.Errorf("memdb: only boil.Whitelist and boil.Infer columns are supported")
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("models: unable to update %s, could not build whitelist", table)
+	}
+	return cols, nil
+}
+
+// MySQL errors, built the way the server reports them
+
+func duplicateEntry(value, index string) error {
+	return &mysql.MySQLError{
+		Number:  errDuplicateEntry,
+		Message: fmt.Sprintf("Duplicate entry '%s' for key '%s'", value, index),
+	}
+}
+
+func noReferencedRow(table, constraint, column, parent string) error {
+	return &mysql.MySQLError{
+		Number: errNoReferencedRow,
+		Message: fmt.Sprintf("Cannot add or update a child row: a foreign key constraint fails "+
+			"(`%s`, CONSTRAINT `%s` FOREIGN KEY (`%s`) REFERENCES `%s` (`id`))", table, constraint, column, parent),
+	}
+}
+
+func badNull(column string) error {
+	return &mysql.MySQLError{
+		Number:  errBadNull,
+		Message: fmt.Sprintf("Column '%s' cannot be null", column),
+	}
+}
+
+func badField(column string) error {
+	return &mysql.MySQLError{
+		Number:  errBadField,
+		Message: fmt.Sprintf("Unknown column '%s' in 'field list'", column),
+	}
+}
+
+func dataTruncated(column string) error {
+	return &mysql.MySQLError{
+		Number:  errDataTruncated,
+		Message: fmt.Sprintf("Data truncated for column '%s' at row 1", column),
+	}
+}
+
+// checkLength enforces a VARCHAR/CHAR length, counted in characters
+func checkLength(column, value string, max int) error {
+	if len([]rune(value)) > max {
+		return &mysql.MySQLError{
+			Number:  errDataTooLong,
+			Message: fmt.Sprintf("Data too long for column '%s' at row 1", column),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,472 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/models"
+)
+
+var (
+	_ room_members.Store = (*RoomMemberStore)(nil)
+	_ room_members.Repo  = (*RoomMemberRepo)(nil)
+)
+
+// roomMemberColumns lists the room_members table's columns, primary key first
+var roomMemberColumns = []string{
+	models.RoomMemberColumns.ID,
+	models.RoomMemberColumns.RoomID,
+	models.RoomMemberColumns.UserID,
+	models.RoomMemberColumns.JoinedAt,
+	models.RoomMemberColumns.LeftAt,
+}
+
+// roomMemberSortColumns whitelists the columns memberships can be sorted by,
+// as in room_members/store
+var roomMemberSortColumns = map[room_members.RoomMemberSortField]string{
+	room_members.RoomMemberSortID:       models.RoomMemberColumns.ID,
+	room_members.RoomMemberSortJoinedAt: models.RoomMemberColumns.JoinedAt,
+}
+
+// RoomMemberStore is the in-memory room_members.Store
+type RoomMemberStore struct {
+	db *DB
+}
+
+// NewRoomMemberStore creates a membership store reading from db
+func NewRoomMemberStore(db *DB) *RoomMemberStore {
+	return &RoomMemberStore{db: db}
+}
+
+// RoomMembers returns 0 or more memberships matching the filter
+func (s *RoomMemberStore) RoomMembers(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) ([]*room_members.RoomMembers, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, keyset, err := s.query(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = selectRows(rows, roomMemberValue, keyset, filter.After, filter.Offset, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbRoomMembersToRoomMembers(rows), nil
+}
+
+// RoomMembersPage returns one page of memberships and a cursor for the next
+// page
+func (s *RoomMemberStore) RoomMembersPage(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (*room_members.RoomMemberPage, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, keyset, err := s.query(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cursor, err := selectPage(rows, roomMemberValue, keyset, filter.After, filter.Offset, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &room_members.RoomMemberPage{
+		RoomMembers: dbRoomMembersToRoomMembers(rows),
+		NextCursor:  cursor,
+	}, nil
+}
+
+// CountRoomMembers returns how many memberships match the filter. Sorts and
+// pagination fields are ignored.
+func (s *RoomMemberStore) CountRoomMembers(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, err := s.filter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+// RoomMembersExist reports whether any memberships match the filter
+func (s *RoomMemberStore) RoomMembersExist(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (bool, error) {
+	count, err := s.CountRoomMembers(ctx, exec, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RoomMember returns exactly 1 membership, errors if 0 or >1 found
+func (s *RoomMemberStore) RoomMember(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (*room_members.RoomMembers, error) {
+	results, err := s.RoomMembers(ctx, exec, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, errlib.NotFound("room member")
+	}
+	if len(results) > 1 {
+		return nil, errlib.MultipleResults("room member", len(results))
+	}
+
+	return results[0], nil
+}
+
+// Update performs generic update with nullable fields; all memberships or
+// none are updated
+func (s *RoomMemberStore) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	update room_members.UpdateRoomMember,
+) error {
+	if len(update.IDs) == 0 {
+		return fmt.Errorf("no room member IDs provided")
+	}
+
+	if update.RoomID.Valid {
+		roomID, err := dbid.Parse(update.RoomID.String)
+		if err != nil {
+			return fmt.Errorf("invalid room ID %s: %w", update.RoomID.String, err)
+		}
+		update.RoomID.String = roomID
+	}
+	if update.UserID.Valid {
+		userID, err := dbid.Parse(update.UserID.String)
+		if err != nil {
+			return fmt.Errorf("invalid user ID %s: %w", update.UserID.String, err)
+		}
+		update.UserID.String = userID
+	}
+
+	if !update.RoomID.Valid && !update.UserID.Valid && !update.JoinedAt.Valid && !update.LeftAt.Valid {
+		return nil // Nothing to update
+	}
+
+	ids, err := parseIDs(update.IDs, "room member ID")
+	if err != nil {
+		return err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	next := maps.Clone(s.db.roomMembers)
+	var changed []*models.RoomMember
+	for _, id := range ids {
+		existing, ok := next[key(id)]
+		if !ok {
+			continue
+		}
+
+		member := *existing
+		if update.RoomID.Valid {
+			member.RoomID = update.RoomID.String
+		}
+		if update.UserID.Valid {
+			member.UserID = update.UserID.String
+		}
+		if update.JoinedAt.Valid {
+			member.JoinedAt = update.JoinedAt
+		}
+		if update.LeftAt.Valid {
+			member.LeftAt = update.LeftAt
+		}
+
+		row := storedRoomMember(&member)
+		next[key(id)] = row
+		changed = append(changed, row)
+	}
+
+	for _, row := range changed {
+		if err := s.db.checkRoomMember(next, row); err != nil {
+			return errlib.FromDB(err)
+		}
+	}
+
+	s.db.roomMembers = next
+	return nil
+}
+
+// query returns the memberships matching filter and the keyset to order them by
+func (s *RoomMemberStore) query(filter room_members.RoomMemberQueryFilter) ([]*models.RoomMember, *querylib.Keyset, error) {
+	rows, err := s.filter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyset, err := querylib.NewKeyset(filter.Sorts, roomMemberSortColumns, models.RoomMemberColumns.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, keyset, nil
+}
+
+// filter returns the memberships matching the filter's conditions, in no
+// order. It mirrors room_members/store's roomMemberFilterMods.
+func (s *RoomMemberStore) filter(filter room_members.RoomMemberQueryFilter) ([]*models.RoomMember, error) {
+	ids, err := parseIDs(filter.IDs, "room member ID")
+	if err != nil {
+		return nil, err
+	}
+	if filter.RoomID.Valid {
+		roomID, err := dbid.Parse(filter.RoomID.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid room ID %s: %w", filter.RoomID.String, err)
+		}
+		filter.RoomID.String = roomID
+	}
+	if filter.UserID.Valid {
+		userID, err := dbid.Parse(filter.UserID.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %s: %w", filter.UserID.String, err)
+		}
+		filter.UserID.String = userID
+	}
+
+	var rows []*models.RoomMember
+	for _, member := range s.db.roomMembers {
+		switch {
+		case len(ids) > 0 && !inFold(member.ID, ids),
+			filter.RoomID.Valid && !strings.EqualFold(member.RoomID, filter.RoomID.String),
+			filter.UserID.Valid && !strings.EqualFold(member.UserID, filter.UserID.String),
+			filter.JoinedAt.Valid && !sameTime(member.JoinedAt, filter.JoinedAt.Time),
+			filter.LeftAt.Valid && !sameTime(member.LeftAt, filter.LeftAt.Time),
+			!inRange(member.JoinedAt, filter.JoinedAfter, filter.JoinedBefore),
+			!inRange(member.LeftAt, filter.LeftAfter, filter.LeftBefore),
+			filter.Active.Valid && member.LeftAt.Valid == filter.Active.Bool:
+			continue
+		}
+		rows = append(rows, member)
+	}
+
+	return rows, nil
+}
+
+// roomMemberValue returns a membership's value for a sort column
+func roomMemberValue(member *models.RoomMember, column string) interface{} {
+	if column == models.RoomMemberColumns.JoinedAt {
+		return member.JoinedAt
+	}
+	return member.ID
+}
+
+// RoomMemberRepo is the in-memory room_members.Repo
+type RoomMemberRepo struct {
+	db *DB
+}
+
+// NewRoomMemberRepo creates a membership repository writing to db
+func NewRoomMemberRepo(db *DB) *RoomMemberRepo {
+	return &RoomMemberRepo{db: db}
+}
+
+// Insert creates a new membership, assigning a UUID when ID is empty.
+// joined_at defaults to now, as in the table.
+func (r *RoomMemberRepo) Insert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomMember *models.RoomMember,
+) (*models.RoomMember, error) {
+	if roomMember.ID == "" {
+		roomMember.ID = dbid.New()
+	}
+	if !roomMember.JoinedAt.Valid {
+		roomMember.JoinedAt = timestamp(null.TimeFrom(time.Now()))
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.insertRoomMember(r.db.roomMembers, roomMember); err != nil {
+		return nil, fmt.Errorf("insert room member: %w", errlib.FromDB(err))
+	}
+
+	return roomMember, nil
+}
+
+// BulkInsert inserts multiple memberships. The whole batch fails on any
+// error.
+func (r *RoomMemberRepo) BulkInsert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomMembers []*models.RoomMember,
+) error {
+	now := time.Now()
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	next := maps.Clone(r.db.roomMembers)
+	for _, roomMember := range roomMembers {
+		if roomMember.ID == "" {
+			roomMember.ID = dbid.New()
+		}
+		if !roomMember.JoinedAt.Valid {
+			roomMember.JoinedAt = null.TimeFrom(now)
+		}
+		if err := r.db.insertRoomMember(next, roomMember); err != nil {
+			return fmt.Errorf("bulk insert room members: %w", errlib.FromDB(err))
+		}
+	}
+
+	r.db.roomMembers = next
+	return nil
+}
+
+// Update writes the given columns of an existing membership
+func (r *RoomMemberRepo) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomMember *models.RoomMember,
+	columns boil.Columns,
+) (*models.RoomMember, error) {
+	cols, err := updateColumns(columns, roomMemberColumns, models.TableNames.RoomMembers)
+	if err != nil {
+		return nil, fmt.Errorf("update room member: %w", err)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.roomMembers[key(roomMember.ID)]
+	if !ok {
+		return roomMember, nil // no rows affected is not an error
+	}
+
+	updated := *existing
+	for _, col := range cols {
+		switch col {
+		case models.RoomMemberColumns.RoomID:
+			updated.RoomID = roomMember.RoomID
+		case models.RoomMemberColumns.UserID:
+			updated.UserID = roomMember.UserID
+		case models.RoomMemberColumns.JoinedAt:
+			updated.JoinedAt = roomMember.JoinedAt
+		case models.RoomMemberColumns.LeftAt:
+			updated.LeftAt = roomMember.LeftAt
+		default:
+			return nil, fmt.Errorf("update room member: %w", badField(col))
+		}
+	}
+
+	row := storedRoomMember(&updated)
+	if err := r.db.checkRoomMember(r.db.roomMembers, row); err != nil {
+		return nil, fmt.Errorf("update room member: %w", errlib.FromDB(err))
+	}
+	r.db.roomMembers[key(row.ID)] = row
+
+	return roomMember, nil
+}
+
+// insertRoomMember adds a copy of roomMember to table
+func (db *DB) insertRoomMember(table map[string]*models.RoomMember, roomMember *models.RoomMember) error {
+	if _, ok := table[key(roomMember.ID)]; ok {
+		return duplicateEntry(roomMember.ID, "room_members.PRIMARY")
+	}
+
+	row := storedRoomMember(roomMember)
+	if err := db.checkRoomMember(table, row); err != nil {
+		return err
+	}
+	table[key(row.ID)] = row
+
+	return nil
+}
+
+// storedRoomMember copies roomMember as the table would store it
+func storedRoomMember(roomMember *models.RoomMember) *models.RoomMember {
+	return &models.RoomMember{
+		ID:       roomMember.ID,
+		RoomID:   roomMember.RoomID,
+		UserID:   roomMember.UserID,
+		JoinedAt: timestamp(roomMember.JoinedAt),
+		LeftAt:   timestamp(roomMember.LeftAt),
+	}
+}
+
+// checkRoomMember enforces the room_members table's foreign keys and
+// uniq_active_membership against the other rows of table. The key is on the
+// generated active_key, 1 while left_at is NULL and NULL after, so it only
+// rejects a second active membership of the same room and user.
+func (db *DB) checkRoomMember(table map[string]*models.RoomMember, member *models.RoomMember) error {
+	if err := checkLength(models.RoomMemberColumns.ID, member.ID, 36); err != nil {
+		return err
+	}
+	if _, ok := db.rooms[key(member.RoomID)]; !ok {
+		return noReferencedRow(models.TableNames.RoomMembers, "fk_room_members_room",
+			models.RoomMemberColumns.RoomID, models.TableNames.Rooms)
+	}
+	if _, ok := db.users[key(member.UserID)]; !ok {
+		return noReferencedRow(models.TableNames.RoomMembers, "fk_room_members_user",
+			models.RoomMemberColumns.UserID, models.TableNames.Users)
+	}
+
+	if member.LeftAt.Valid {
+		return nil
+	}
+	for k, other := range table {
+		if k == key(member.ID) || other.LeftAt.Valid {
+			continue
+		}
+		if strings.EqualFold(other.RoomID, member.RoomID) &&
+			strings.EqualFold(other.UserID, member.UserID) {
+			return duplicateEntry(fmt.Sprintf("%s-%s-1", member.RoomID, member.UserID),
+				"room_members.uniq_active_membership")
+		}
+	}
+
+	return nil
+}
+
+// dbRoomMembersToRoomMembers converts rows to domain models
+func dbRoomMembersToRoomMembers(dbRoomMembers []*models.RoomMember) []*room_members.RoomMembers {
+	result := make([]*room_members.RoomMembers, len(dbRoomMembers))
+	for i, db := range dbRoomMembers {
+		result[i] = &room_members.RoomMembers{
+			ID:       db.ID,
+			RoomID:   db.RoomID,
+			UserID:   db.UserID,
+			JoinedAt: db.JoinedAt.Time,
+			LeftAt:   db.LeftAt,
+		}
+	}
+	return result
+}
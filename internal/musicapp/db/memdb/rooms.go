@@ -0,0 +1,427 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/models"
+)
+
+var (
+	_ rooms.Store            = (*RoomStore)(nil)
+	_ rooms.Repo             = (*RoomRepo)(nil)
+	_ room_members.RoomStore = (*RoomStore)(nil)
+)
+
+// roomSortColumns whitelists the columns rooms can be sorted by, as in
+// rooms/store
+var roomSortColumns = map[rooms.RoomSortField]string{
+	rooms.RoomSortID:        models.RoomColumns.ID,
+	rooms.RoomSortName:      models.RoomColumns.Name,
+	rooms.RoomSortCreatedAt: models.RoomColumns.CreatedAt,
+}
+
+// RoomStore is the in-memory rooms.Store
+type RoomStore struct {
+	db *DB
+}
+
+// NewRoomStore creates a room store reading from db
+func NewRoomStore(db *DB) *RoomStore {
+	return &RoomStore{db: db}
+}
+
+// Rooms returns 0 or more rooms matching the filter
+func (s *RoomStore) Rooms(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) ([]*rooms.Room, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, keyset, err := s.query(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = selectRows(rows, roomValue, keyset, filter.After, filter.Offset, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbRoomsToRooms(rows), nil
+}
+
+// RoomsPage returns one page of rooms matching the filter, and a cursor for
+// the next page
+func (s *RoomStore) RoomsPage(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) (*rooms.RoomPage, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, keyset, err := s.query(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cursor, err := selectPage(rows, roomValue, keyset, filter.After, filter.Offset, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rooms.RoomPage{
+		Rooms:      dbRoomsToRooms(rows),
+		NextCursor: cursor,
+	}, nil
+}
+
+// CountRooms returns how many rooms match the filter. Sorts and
+// pagination fields are ignored.
+func (s *RoomStore) CountRooms(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, err := s.filter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+// RoomsExist reports whether any rooms match the filter
+func (s *RoomStore) RoomsExist(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) (bool, error) {
+	count, err := s.CountRooms(ctx, exec, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Room returns exactly 1 room, errors if 0 or >1 found
+func (s *RoomStore) Room(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) (*rooms.Room, error) {
+	results, err := s.Rooms(ctx, exec, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, errlib.NotFound("room")
+	}
+	if len(results) > 1 {
+		return nil, errlib.MultipleResults("room", len(results))
+	}
+
+	return results[0], nil
+}
+
+// LockRoom returns the room with the given ID. Transactions here never
+// overlap, so unlike rooms/store there is no row to lock.
+func (s *RoomStore) LockRoom(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	id string,
+) (*models.Room, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, err := s.filter(rooms.RoomQueryFilter{IDs: []string{id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errlib.NotFound("room")
+	}
+
+	return storedRoom(rows[0]), nil
+}
+
+// Update performs generic update with nullable fields; all rooms or none
+// are updated
+func (s *RoomStore) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	update rooms.UpdateRoom,
+) error {
+	if len(update.IDs) == 0 {
+		return fmt.Errorf("no room IDs provided")
+	}
+
+	if update.CreatedBy.Valid {
+		createdBy, err := dbid.Parse(update.CreatedBy.String)
+		if err != nil {
+			return fmt.Errorf("invalid created_by ID %s: %w", update.CreatedBy.String, err)
+		}
+		update.CreatedBy.String = createdBy
+	}
+
+	if !update.Name.Valid && !update.IsActive.Valid && !update.CreatedAt.Valid && !update.CreatedBy.Valid {
+		return nil // Nothing to update
+	}
+
+	ids, err := parseIDs(update.IDs, "room ID")
+	if err != nil {
+		return err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	next := maps.Clone(s.db.rooms)
+	var changed []*models.Room
+	for _, id := range ids {
+		existing, ok := next[key(id)]
+		if !ok {
+			continue
+		}
+
+		room := *existing
+		if update.Name.Valid {
+			room.Name = update.Name.String
+		}
+		if update.IsActive.Valid {
+			room.IsActive = update.IsActive
+		}
+		if update.CreatedAt.Valid {
+			room.CreatedAt = update.CreatedAt
+		}
+		if update.CreatedBy.Valid {
+			room.CreatedBy = update.CreatedBy.String
+		}
+
+		row := storedRoom(&room)
+		next[key(id)] = row
+		changed = append(changed, row)
+	}
+
+	for _, row := range changed {
+		if err := s.db.checkRoom(row); err != nil {
+			return errlib.FromDB(err)
+		}
+	}
+
+	s.db.rooms = next
+	return nil
+}
+
+// query returns the rooms matching filter and the keyset to order them by
+func (s *RoomStore) query(filter rooms.RoomQueryFilter) ([]*models.Room, *querylib.Keyset, error) {
+	rows, err := s.filter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyset, err := querylib.NewKeyset(filter.Sorts, roomSortColumns, models.RoomColumns.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, keyset, nil
+}
+
+// filter returns the rooms matching the filter's conditions, in no order.
+// It mirrors rooms/store's roomFilterMods.
+func (s *RoomStore) filter(filter rooms.RoomQueryFilter) ([]*models.Room, error) {
+	ids, err := parseIDs(filter.IDs, "room ID")
+	if err != nil {
+		return nil, err
+	}
+	if filter.CreatedBy.Valid {
+		createdBy, err := dbid.Parse(filter.CreatedBy.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_by ID %s: %w", filter.CreatedBy.String, err)
+		}
+		filter.CreatedBy.String = createdBy
+	}
+	createdByIDs, err := parseIDs(filter.CreatedByIDs, "created_by ID")
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*models.Room
+	for _, room := range s.db.rooms {
+		switch {
+		case len(ids) > 0 && !inFold(room.ID, ids),
+			filter.Name.Valid && !strings.EqualFold(room.Name, filter.Name.String),
+			filter.CreatedBy.Valid && !strings.EqualFold(room.CreatedBy, filter.CreatedBy.String),
+			len(createdByIDs) > 0 && !inFold(room.CreatedBy, createdByIDs),
+			filter.IsActive.Valid && room.IsActive.Bool != filter.IsActive.Bool,
+			filter.CreatedAt.Valid && !sameTime(room.CreatedAt, filter.CreatedAt.Time),
+			!inRange(room.CreatedAt, filter.CreatedAfter, filter.CreatedBefore),
+			filter.NamePrefix.Valid && !hasPrefix(room.Name, filter.NamePrefix.String),
+			filter.NameContains.Valid && !contains(room.Name, filter.NameContains.String):
+			continue
+		}
+		rows = append(rows, room)
+	}
+
+	return rows, nil
+}
+
+// roomValue returns a room's value for a sort column
+func roomValue(room *models.Room, column string) interface{} {
+	switch column {
+	case models.RoomColumns.Name:
+		return room.Name
+	case models.RoomColumns.CreatedAt:
+		return room.CreatedAt
+	}
+	return room.ID
+}
+
+// RoomRepo is the in-memory rooms.Repo
+type RoomRepo struct {
+	db *DB
+}
+
+// NewRoomRepo creates a room repository writing to db
+func NewRoomRepo(db *DB) *RoomRepo {
+	return &RoomRepo{db: db}
+}
+
+// Insert creates a new room, assigning a UUID when ID is empty. is_active
+// defaults to true, as in the table.
+func (r *RoomRepo) Insert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	room *models.Room,
+) (*models.Room, error) {
+	if room.ID == "" {
+		room.ID = dbid.New()
+	}
+	if room.CreatedAt.Time.IsZero() {
+		room.CreatedAt = null.TimeFrom(time.Now())
+	}
+	if !room.IsActive.Valid {
+		room.IsActive = null.BoolFrom(true)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := r.db.insertRoom(r.db.rooms, room); err != nil {
+		return nil, fmt.Errorf("insert Room: %w", errlib.FromDB(err))
+	}
+
+	return room, nil
+}
+
+// BulkInsert inserts multiple rooms. The whole batch fails on any error.
+func (r *RoomRepo) BulkInsert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	rooms []*models.Room,
+) error {
+	now := time.Now()
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	next := maps.Clone(r.db.rooms)
+	for _, room := range rooms {
+		if room.ID == "" {
+			room.ID = dbid.New()
+		}
+		if !room.IsActive.Valid {
+			room.IsActive = null.BoolFrom(true)
+		}
+		if !room.CreatedAt.Valid {
+			room.CreatedAt = null.TimeFrom(now)
+		}
+		if err := r.db.insertRoom(next, room); err != nil {
+			return fmt.Errorf("bulk insert rooms: %w", errlib.FromDB(err))
+		}
+	}
+
+	r.db.rooms = next
+	return nil
+}
+
+// insertRoom adds a copy of room to table
+func (db *DB) insertRoom(table map[string]*models.Room, room *models.Room) error {
+	if _, ok := table[key(room.ID)]; ok {
+		return duplicateEntry(room.ID, "rooms.PRIMARY")
+	}
+
+	row := storedRoom(room)
+	if err := db.checkRoom(row); err != nil {
+		return err
+	}
+	table[key(row.ID)] = row
+
+	return nil
+}
+
+// storedRoom copies room as the table would store it
+func storedRoom(room *models.Room) *models.Room {
+	return &models.Room{
+		ID:        room.ID,
+		Name:      room.Name,
+		CreatedBy: room.CreatedBy,
+		IsActive:  room.IsActive,
+		CreatedAt: timestamp(room.CreatedAt),
+	}
+}
+
+// checkRoom enforces the rooms table's column types and its foreign key on
+// created_by
+func (db *DB) checkRoom(room *models.Room) error {
+	if err := checkLength(models.RoomColumns.ID, room.ID, 36); err != nil {
+		return err
+	}
+	if err := checkLength(models.RoomColumns.Name, room.Name, 100); err != nil {
+		return err
+	}
+	if !room.IsActive.Valid {
+		return badNull(models.RoomColumns.IsActive)
+	}
+	if _, ok := db.users[key(room.CreatedBy)]; !ok {
+		return noReferencedRow(models.TableNames.Rooms, "fk_rooms_created_by",
+			models.RoomColumns.CreatedBy, models.TableNames.Users)
+	}
+
+	return nil
+}
+
+// dbRoomsToRooms converts rows to domain models
+func dbRoomsToRooms(dbRooms []*models.Room) []*rooms.Room {
+	result := make([]*rooms.Room, len(dbRooms))
+	for i, db := range dbRooms {
+		result[i] = &rooms.Room{
+			ID:        db.ID,
+			Name:      db.Name,
+			CreatedBy: db.CreatedBy,
+			IsActive:  db.IsActive.Bool,
+			CreatedAt: db.CreatedAt.Time,
+		}
+	}
+	return result
+}
@@ -0,0 +1,528 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/internal/util/validationlib"
+	"mlm/models"
+)
+
+var (
+	_ users.Store = (*UserStore)(nil)
+	_ users.Repo  = (*UserRepo)(nil)
+)
+
+// userColumns lists the users table's columns, primary key first
+var userColumns = []string{
+	models.UserColumns.ID,
+	models.UserColumns.Username,
+	models.UserColumns.DisplayName,
+	models.UserColumns.Gender,
+	models.UserColumns.CreatedAt,
+	models.UserColumns.Email,
+	models.UserColumns.DeletedAt,
+	models.UserColumns.ErasedAt,
+}
+
+// userGenders are the values of the gender ENUM
+var userGenders = []string{
+	string(users.GenderMale),
+	string(users.GenderFemale),
+	string(users.GenderOther),
+}
+
+// userSortColumns whitelists the columns users can be sorted by, as in
+// users/store
+var userSortColumns = map[users.UserSortField]string{
+	users.UserSortID:        models.UserColumns.ID,
+	users.UserSortUsername:  models.UserColumns.Username,
+	users.UserSortCreatedAt: models.UserColumns.CreatedAt,
+}
+
+// UserStore is the in-memory users.Store
+type UserStore struct {
+	db *DB
+}
+
+// NewUserStore creates a user store reading from db
+func NewUserStore(db *DB) *UserStore {
+	return &UserStore{db: db}
+}
+
+// Users returns 0 or more users matching the filter
+func (s *UserStore) Users(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) ([]*users.User, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, keyset, err := s.query(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = selectRows(rows, userValue, keyset, filter.After, filter.Offset, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbUsersToUsers(rows), nil
+}
+
+// UsersPage returns one page of users matching the filter, and a cursor for
+// the next page
+func (s *UserStore) UsersPage(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) (*users.UserPage, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, keyset, err := s.query(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cursor, err := selectPage(rows, userValue, keyset, filter.After, filter.Offset, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.UserPage{
+		Users:      dbUsersToUsers(rows),
+		NextCursor: cursor,
+	}, nil
+}
+
+// CountUsers returns how many users match the filter. Sorts and
+// pagination fields are ignored.
+func (s *UserStore) CountUsers(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows, err := s.filter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(rows)), nil
+}
+
+// UsersExist reports whether any users match the filter
+func (s *UserStore) UsersExist(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) (bool, error) {
+	count, err := s.CountUsers(ctx, exec, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// User returns exactly 1 user, errors if 0 or >1 found
+func (s *UserStore) User(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) (*users.User, error) {
+	results, err := s.Users(ctx, exec, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, errlib.NotFound("user")
+	}
+	if len(results) > 1 {
+		return nil, errlib.MultipleResults("user", len(results))
+	}
+
+	return results[0], nil
+}
+
+// Update performs generic update with nullable fields. Like a single UPDATE
+// statement, either every user is updated or, on a duplicate key, none is.
+func (s *UserStore) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	update users.UpdateUser,
+) error {
+	if len(update.IDs) == 0 {
+		return fmt.Errorf("no user IDs provided")
+	}
+
+	if update.Email.Valid {
+		// Emails are unique, so one can't be given to several users at once
+		if len(update.IDs) > 1 {
+			return validationlib.NewValidationError("email", update.Email.String, "cannot be set on more than one user")
+		}
+		email, err := users.NormalizeEmail(update.Email.String)
+		if err != nil {
+			return err
+		}
+		update.Email.String = email
+	}
+
+	if !update.Username.Valid && !update.Email.Valid && !update.DisplayName.Valid && !update.Gender.Valid {
+		return nil // Nothing to update
+	}
+
+	ids, err := parseIDs(update.IDs, "user ID")
+	if err != nil {
+		return err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	next := maps.Clone(s.db.users)
+	var changed []*models.User
+	for _, id := range ids {
+		existing, ok := next[key(id)]
+		if !ok {
+			continue
+		}
+
+		user := *existing
+		if update.Username.Valid {
+			user.Username = update.Username.String
+		}
+		if update.Email.Valid {
+			user.Email = null.StringFrom(update.Email.String)
+		}
+		if update.DisplayName.Valid {
+			user.DisplayName = null.StringFrom(update.DisplayName.String)
+		}
+		if update.Gender.Valid {
+			user.Gender = null.StringFrom(update.Gender.String)
+		}
+
+		row := storedUser(&user)
+		next[key(id)] = row
+		changed = append(changed, row)
+	}
+
+	for _, row := range changed {
+		if err := checkUser(next, row); err != nil {
+			return errlib.FromDB(err)
+		}
+	}
+
+	s.db.users = next
+	return nil
+}
+
+// query returns the users matching filter and the keyset to order them by
+func (s *UserStore) query(filter users.UserQueryFilter) ([]*models.User, *querylib.Keyset, error) {
+	rows, err := s.filter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyset, err := querylib.NewKeyset(filter.Sorts, userSortColumns, models.UserColumns.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, keyset, nil
+}
+
+// filter returns the users matching the filter's conditions, in no order.
+// It mirrors users/store's userFilterMods.
+func (s *UserStore) filter(filter users.UserQueryFilter) ([]*models.User, error) {
+	ids, err := parseIDs(filter.IDs, "user ID")
+	if err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(filter.Email.String))
+
+	var rows []*models.User
+	for _, user := range s.db.users {
+		switch {
+		case len(ids) > 0 && !inFold(user.ID, ids),
+			filter.Username.Valid && !strings.EqualFold(user.Username, filter.Username.String),
+			!filter.IncludeDeleted && user.DeletedAt.Valid,
+			filter.Email.Valid && !(user.Email.Valid && strings.EqualFold(user.Email.String, email)),
+			filter.Gender.Valid && !strings.EqualFold(user.Gender.String, filter.Gender.String),
+			len(filter.Genders) > 0 && !inFold(user.Gender.String, filter.Genders),
+			filter.UsernamePrefix.Valid && !hasPrefix(user.Username, filter.UsernamePrefix.String),
+			filter.UsernameContains.Valid && !contains(user.Username, filter.UsernameContains.String),
+			filter.DisplayNamePrefix.Valid && !(user.DisplayName.Valid && hasPrefix(user.DisplayName.String, filter.DisplayNamePrefix.String)),
+			filter.DisplayNameContains.Valid && !(user.DisplayName.Valid && contains(user.DisplayName.String, filter.DisplayNameContains.String)),
+			!inRange(user.CreatedAt, filter.CreatedAfter, filter.CreatedBefore):
+			continue
+		}
+		rows = append(rows, user)
+	}
+
+	return rows, nil
+}
+
+// userValue returns a user's value for a sort column
+func userValue(user *models.User, column string) interface{} {
+	switch column {
+	case models.UserColumns.Username:
+		return user.Username
+	case models.UserColumns.CreatedAt:
+		return user.CreatedAt
+	}
+	return user.ID
+}
+
+// UserRepo is the in-memory users.Repo
+type UserRepo struct {
+	db *DB
+}
+
+// NewUserRepo creates a user repository writing to db
+func NewUserRepo(db *DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+// Insert creates a new user, assigning a UUID when ID is empty. The email is
+// validated and lower-cased first.
+func (r *UserRepo) Insert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	user *models.User,
+) (*models.User, error) {
+	if user.ID == "" {
+		user.ID = dbid.New()
+	}
+	if err := normalizeUserEmail(user); err != nil {
+		return nil, fmt.Errorf("insert user: %w", err)
+	}
+	if user.CreatedAt.Time.IsZero() {
+		user.CreatedAt = null.TimeFrom(time.Now())
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if err := insertUser(r.db.users, user); err != nil {
+		return nil, fmt.Errorf("insert user: %w", errlib.FromDB(err))
+	}
+
+	return user, nil
+}
+
+// BulkInsert inserts multiple users. The whole batch fails on any duplicate
+// or invalid email.
+func (r *UserRepo) BulkInsert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	users []*models.User,
+) error {
+	now := time.Now()
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	next := maps.Clone(r.db.users)
+	for i, user := range users {
+		if user.ID == "" {
+			user.ID = dbid.New()
+		}
+		if !user.CreatedAt.Valid {
+			user.CreatedAt = null.TimeFrom(now)
+		}
+		if err := normalizeUserEmail(user); err != nil {
+			return fmt.Errorf("bulk insert users: user %d: %w", i, err)
+		}
+		if err := insertUser(next, user); err != nil {
+			return fmt.Errorf("bulk insert users: %w", errlib.FromDB(err))
+		}
+	}
+
+	r.db.users = next
+	return nil
+}
+
+// Update writes the given columns of an existing user
+func (r *UserRepo) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	user *models.User,
+	columns boil.Columns,
+) (*models.User, error) {
+	cols, err := updateColumns(columns, userColumns, models.TableNames.Users)
+	if err != nil {
+		return nil, fmt.Errorf("update user: %w", err)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.users[key(user.ID)]
+	if !ok {
+		return user, nil // no rows affected is not an error
+	}
+
+	updated := *existing
+	for _, col := range cols {
+		switch col {
+		case models.UserColumns.Username:
+			updated.Username = user.Username
+		case models.UserColumns.DisplayName:
+			updated.DisplayName = user.DisplayName
+		case models.UserColumns.Gender:
+			updated.Gender = user.Gender
+		case models.UserColumns.CreatedAt:
+			updated.CreatedAt = user.CreatedAt
+		case models.UserColumns.Email:
+			updated.Email = user.Email
+		case models.UserColumns.DeletedAt:
+			updated.DeletedAt = user.DeletedAt
+		case models.UserColumns.ErasedAt:
+			updated.ErasedAt = user.ErasedAt
+		default:
+			return nil, fmt.Errorf("update user: %w", badField(col))
+		}
+	}
+
+	row := storedUser(&updated)
+	if err := checkUser(r.db.users, row); err != nil {
+		return nil, fmt.Errorf("update user: %w", errlib.FromDB(err))
+	}
+	r.db.users[key(row.ID)] = row
+
+	return user, nil
+}
+
+// normalizeUserEmail validates user's email and lower-cases it in place, as
+// db/repo does. NULL is left alone.
+func normalizeUserEmail(user *models.User) error {
+	if !user.Email.Valid {
+		return nil
+	}
+
+	email, err := users.NormalizeEmail(user.Email.String)
+	if err != nil {
+		return err
+	}
+	user.Email.String = email
+
+	return nil
+}
+
+// insertUser adds a copy of user to table
+func insertUser(table map[string]*models.User, user *models.User) error {
+	if _, ok := table[key(user.ID)]; ok {
+		return duplicateEntry(user.ID, "users.PRIMARY")
+	}
+
+	row := storedUser(user)
+	if err := checkUser(table, row); err != nil {
+		return err
+	}
+	table[key(row.ID)] = row
+
+	return nil
+}
+
+// storedUser copies user as the table would store it
+func storedUser(user *models.User) *models.User {
+	row := &models.User{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Gender:      user.Gender,
+		CreatedAt:   timestamp(user.CreatedAt),
+		Email:       user.Email,
+		DeletedAt:   timestamp(user.DeletedAt),
+		ErasedAt:    timestamp(user.ErasedAt),
+	}
+	if row.Gender.Valid {
+		row.Gender.String = strings.ToLower(row.Gender.String) // ENUM values are stored as declared
+	}
+	return row
+}
+
+// checkUser enforces the users table's column types and its unique keys on
+// username and email against the other rows of table
+func checkUser(table map[string]*models.User, user *models.User) error {
+	if err := checkLength(models.UserColumns.ID, user.ID, 36); err != nil {
+		return err
+	}
+	if err := checkLength(models.UserColumns.Username, user.Username, 50); err != nil {
+		return err
+	}
+	if err := checkLength(models.UserColumns.DisplayName, user.DisplayName.String, 100); err != nil {
+		return err
+	}
+	if err := checkLength(models.UserColumns.Email, user.Email.String, 255); err != nil {
+		return err
+	}
+	if !user.Gender.Valid {
+		return badNull(models.UserColumns.Gender)
+	}
+	if !inFold(user.Gender.String, userGenders) {
+		return dataTruncated(models.UserColumns.Gender)
+	}
+
+	for k, other := range table {
+		if k == key(user.ID) {
+			continue
+		}
+		if strings.EqualFold(other.Username, user.Username) {
+			return duplicateEntry(user.Username, "users.username")
+		}
+		if user.Email.Valid && other.Email.Valid && strings.EqualFold(other.Email.String, user.Email.String) {
+			return duplicateEntry(user.Email.String, "users.email")
+		}
+	}
+
+	return nil
+}
+
+// dbUsersToUsers converts rows to domain models
+func dbUsersToUsers(dbUsers []*models.User) []*users.User {
+	result := make([]*users.User, len(dbUsers))
+	for i, db := range dbUsers {
+		result[i] = &users.User{
+			ID:          db.ID,
+			Username:    db.Username,
+			Email:       db.Email.String,
+			DisplayName: db.DisplayName.String,
+			Gender:      users.Gender(db.Gender.String),
+			CreatedAt:   db.CreatedAt.Time,
+			DeletedAt:   db.DeletedAt,
+		}
+	}
+	return result
+}
+
+// parseIDs validates ids the way the SQL stores do before querying; what
+// names the ID in errors, e.g. "user ID"
+func parseIDs(ids []string, what string) ([]string, error) {
+	parsed := make([]string, len(ids))
+	for i, id := range ids {
+		p, err := dbid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %w", what, id, err)
+		}
+		parsed[i] = p
+	}
+	return parsed, nil
+}
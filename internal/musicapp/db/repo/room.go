@@ -3,9 +3,13 @@ package repo
 import (
 	"context"
 	"fmt"
+	"mlm/internal/musicapp/db/bulk"
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/util/errlib"
 	"mlm/models"
-	"strings"
+	"time"
 
+	"github.com/aarondl/null/v8"
 	"github.com/aarondl/sqlboiler/v4/boil"
 )
 
@@ -20,39 +24,76 @@ func (r *RoomRepo) Insert(
 	exec boil.ContextExecutor,
 	room *models.Room,
 ) (*models.Room, error) {
+	if room.ID == "" {
+		room.ID = dbid.New()
+	}
+
 	err := room.Insert(ctx, exec, boil.Infer())
 	if err != nil {
-		return nil, fmt.Errorf("insert Room: %w", err)
+		return nil, fmt.Errorf("insert Room: %w", errlib.FromDB(err))
 	}
 
 	return room, nil
 }
 
+var roomWriter = bulk.Writer{
+	Table: models.TableNames.Rooms,
+	Columns: []string{
+		models.RoomColumns.ID,
+		models.RoomColumns.Name,
+		models.RoomColumns.CreatedBy,
+		models.RoomColumns.IsActive,
+		models.RoomColumns.CreatedAt,
+	},
+}
+
 func (r *RoomRepo) BulkInsert(
 	ctx context.Context,
 	exec boil.ContextExecutor,
 	rooms []*models.Room,
 ) error {
-	if len(rooms) == 0 {
-		return nil
+	_, err := roomWriter.Insert(ctx, exec, roomRows(rooms))
+	if err != nil {
+		return fmt.Errorf("bulk insert rooms: %w", errlib.FromDB(err))
 	}
 
-	placeholders := make([]string, len(rooms))
-	args := make([]interface{}, 0, len(rooms)*5)
+	return nil
+}
 
-	for i, room := range rooms {
-		placeholders[i] = "(?, ?, ?, ?, ?)"
-		args = append(args, room.ID, room.Name, room.CreatedBy, room.IsActive, room.CreatedAt)
+// BulkUpsert inserts multiple rooms, overwriting updateColumns of rooms whose
+// id already exists. With no updateColumns existing rooms are left untouched
+func (r *RoomRepo) BulkUpsert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	rooms []*models.Room,
+	updateColumns ...string,
+) (bulk.Result, error) {
+	result, err := roomWriter.Upsert(ctx, exec, roomRows(rooms), updateColumns...)
+	if err != nil {
+		return bulk.Result{}, fmt.Errorf("bulk upsert rooms: %w", errlib.FromDB(err))
 	}
 
-	query := fmt.Sprintf(`INSERT INTO rooms (id, name, created_by, is_active, created_at) VALUES %s`, strings.Join(placeholders, ", "))
+	return result, nil
+}
 
-	_, err := exec.ExecContext(ctx, query, args...)
-	if err != nil {
-		return fmt.Errorf("bulk insert rooms: %w", err)
+// roomRows fills the defaults the table would apply and flattens rooms into
+// roomWriter rows
+func roomRows(rooms []*models.Room) [][]interface{} {
+	now := time.Now()
+	rows := make([][]interface{}, len(rooms))
+	for i, room := range rooms {
+		if room.ID == "" {
+			room.ID = dbid.New()
+		}
+		if !room.IsActive.Valid {
+			room.IsActive = null.BoolFrom(true)
+		}
+		if !room.CreatedAt.Valid {
+			room.CreatedAt = null.TimeFrom(now)
+		}
+		rows[i] = []interface{}{room.ID, room.Name, room.CreatedBy, room.IsActive, room.CreatedAt}
 	}
-
-	return nil
+	return rows
 }
 
 func (r *RoomRepo) Upsert(
@@ -60,6 +101,10 @@ func (r *RoomRepo) Upsert(
 	exec boil.ContextExecutor,
 	room *models.Room,
 ) (*models.Room, error) {
+	if room.ID == "" {
+		room.ID = dbid.New()
+	}
+
 	err := room.Upsert(
 		ctx,
 		exec,
@@ -67,7 +112,7 @@ func (r *RoomRepo) Upsert(
 		boil.Infer(),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("upsert room: %w", err)
+		return nil, fmt.Errorf("upsert room: %w", errlib.FromDB(err))
 	}
 
 	return room, nil
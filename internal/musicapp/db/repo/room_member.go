@@ -3,9 +3,13 @@ package repo
 import (
 	"context"
 	"fmt"
+	"mlm/internal/musicapp/db/bulk"
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/util/errlib"
 	"mlm/models"
-	"strings"
+	"time"
 
+	"github.com/aarondl/null/v8"
 	"github.com/aarondl/sqlboiler/v4/boil"
 )
 
@@ -19,38 +23,73 @@ func (r *RoomMemberRepo) Insert(
 	ctx context.Context,
 	exec boil.ContextExecutor,
 	roomMember *models.RoomMember) (*models.RoomMember, error) {
+	if roomMember.ID == "" {
+		roomMember.ID = dbid.New()
+	}
+
 	err := roomMember.Insert(ctx, exec, boil.Infer())
 	if err != nil {
-		return nil, fmt.Errorf("insert room member: %w", err)
+		return nil, fmt.Errorf("insert room member: %w", errlib.FromDB(err))
 	}
 
 	return roomMember, nil
 }
 
+var roomMemberWriter = bulk.Writer{
+	Table: models.TableNames.RoomMembers,
+	Columns: []string{
+		models.RoomMemberColumns.ID,
+		models.RoomMemberColumns.RoomID,
+		models.RoomMemberColumns.UserID,
+		models.RoomMemberColumns.JoinedAt,
+		models.RoomMemberColumns.LeftAt,
+	},
+}
+
 func (r *RoomMemberRepo) BulkInsert(
 	ctx context.Context,
 	exec boil.ContextExecutor,
 	roomMembers []*models.RoomMember) error {
-	if len(roomMembers) == 0 {
-		return nil
+	_, err := roomMemberWriter.Insert(ctx, exec, roomMemberRows(roomMembers))
+	if err != nil {
+		return fmt.Errorf("bulk insert room members: %w", errlib.FromDB(err))
 	}
 
-	placeholders := make([]string, len(roomMembers))
-	args := make([]interface{}, 0, len(roomMembers)*5)
+	return nil
+}
 
-	for i, roomMember := range roomMembers {
-		placeholders[i] = "(?, ?, ?, ?, ?, ?)"
-		args = append(args, roomMember.ID, roomMember.RoomID, roomMember.UserID, roomMember.JoinedAt, roomMember.LeftAt)
+// BulkUpsert inserts multiple room members, overwriting updateColumns of
+// memberships that already exist. With no updateColumns existing memberships
+// are left untouched
+func (r *RoomMemberRepo) BulkUpsert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomMembers []*models.RoomMember,
+	updateColumns ...string,
+) (bulk.Result, error) {
+	result, err := roomMemberWriter.Upsert(ctx, exec, roomMemberRows(roomMembers), updateColumns...)
+	if err != nil {
+		return bulk.Result{}, fmt.Errorf("bulk upsert room members: %w", errlib.FromDB(err))
 	}
 
-	query := fmt.Sprintf(`INSERT INTO room_member (id, room_id, user_id, joined_at, left_at) VALUES %s`, strings.Join(placeholders, ","))
+	return result, nil
+}
 
-	_, err := exec.ExecContext(ctx, query, args...)
-	if err != nil {
-		return fmt.Errorf("insert room member: %w", err)
+// roomMemberRows fills the defaults the table would apply and flattens room
+// members into roomMemberWriter rows
+func roomMemberRows(roomMembers []*models.RoomMember) [][]interface{} {
+	now := time.Now()
+	rows := make([][]interface{}, len(roomMembers))
+	for i, roomMember := range roomMembers {
+		if roomMember.ID == "" {
+			roomMember.ID = dbid.New()
+		}
+		if !roomMember.JoinedAt.Valid {
+			roomMember.JoinedAt = null.TimeFrom(now)
+		}
+		rows[i] = []interface{}{roomMember.ID, roomMember.RoomID, roomMember.UserID, roomMember.JoinedAt, roomMember.LeftAt}
 	}
-
-	return nil
+	return rows
 }
 
 func (r *RoomMemberRepo) Upsert(
@@ -58,6 +97,10 @@ func (r *RoomMemberRepo) Upsert(
 	exec boil.ContextExecutor,
 	roomMember *models.RoomMember,
 ) (*models.RoomMember, error) {
+	if roomMember.ID == "" {
+		roomMember.ID = dbid.New()
+	}
+
 	err := roomMember.Upsert(
 		ctx,
 		exec,
@@ -65,7 +108,22 @@ func (r *RoomMemberRepo) Upsert(
 		boil.Infer(),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("upsert room member: %w", err)
+		return nil, fmt.Errorf("upsert room member: %w", errlib.FromDB(err))
+	}
+
+	return roomMember, nil
+}
+
+// Update writes the given columns of an existing room member
+func (r *RoomMemberRepo) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomMember *models.RoomMember,
+	columns boil.Columns,
+) (*models.RoomMember, error) {
+	_, err := roomMember.Update(ctx, exec, columns)
+	if err != nil {
+		return nil, fmt.Errorf("update room member: %w", errlib.FromDB(err))
 	}
 
 	return roomMember, nil
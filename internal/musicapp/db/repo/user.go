@@ -3,10 +3,15 @@ package repo
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
+	"mlm/internal/musicapp/db/bulk"
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
 	"mlm/models"
 
+	"github.com/aarondl/null/v8"
 	"github.com/aarondl/sqlboiler/v4/boil"
 )
 
@@ -20,66 +25,156 @@ func NewUserRepo() *UserRepo {
 	return &UserRepo{}
 }
 
-// Insert creates a new user in the database
+// Insert creates a new user in the database, assigning a UUID when ID is
+// empty. The email is validated and lower-cased first.
 func (r *UserRepo) Insert(
 	ctx context.Context,
 	exec boil.ContextExecutor,
 	user *models.User,
 ) (*models.User, error) {
+	if user.ID == "" {
+		user.ID = dbid.New()
+	}
+	if err := normalizeUserEmail(user); err != nil {
+		return nil, fmt.Errorf("insert user: %w", err)
+	}
+
 	err := user.Insert(ctx, exec, boil.Infer())
 	if err != nil {
-		return nil, fmt.Errorf("insert user: %w", err)
+		return nil, fmt.Errorf("insert user: %w", errlib.FromDB(err))
 	}
 
 	return user, nil
 }
 
+// userWriter writes the columns a user is created with; deleted_at and
+// erased_at are only ever set through Update
+var userWriter = bulk.Writer{
+	Table: models.TableNames.Users,
+	Columns: []string{
+		models.UserColumns.ID,
+		models.UserColumns.Username,
+		models.UserColumns.Email,
+		models.UserColumns.DisplayName,
+		models.UserColumns.Gender,
+		models.UserColumns.CreatedAt,
+	},
+}
+
 // BulkInsert - REQUIRED for multiple records (NO LOOPS)
-// Inserts multiple users in a single query for performance
+// Inserts multiple users in as few queries as MySQL's limits allow. The whole
+// batch fails on any duplicate or invalid email
 func (r *UserRepo) BulkInsert(
 	ctx context.Context,
 	exec boil.ContextExecutor,
 	users []*models.User,
 ) error {
-	if len(users) == 0 {
-		return nil
+	rows, err := userRows(users)
+	if err != nil {
+		return fmt.Errorf("bulk insert users: %w", err)
 	}
 
-	// Build placeholders for values
-	// For each user: (?, ?, ?, ?, ?)
-	placeholders := make([]string, len(users))
-	args := make([]interface{}, 0, len(users)*5)
+	_, err = userWriter.Insert(ctx, exec, rows)
+	if err != nil {
+		return fmt.Errorf("bulk insert users: %w", errlib.FromDB(err))
+	}
 
+	return nil
+}
+
+// BulkUpsert inserts multiple users, overwriting updateColumns of users that
+// already exist by id, username or email. With no updateColumns existing
+// users are left untouched. The whole batch fails on any invalid email
+func (r *UserRepo) BulkUpsert(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	users []*models.User,
+	updateColumns ...string,
+) (bulk.Result, error) {
+	rows, err := userRows(users)
+	if err != nil {
+		return bulk.Result{}, fmt.Errorf("bulk upsert users: %w", err)
+	}
+
+	result, err := userWriter.Upsert(ctx, exec, rows, updateColumns...)
+	if err != nil {
+		return bulk.Result{}, fmt.Errorf("bulk upsert users: %w", errlib.FromDB(err))
+	}
+
+	return result, nil
+}
+
+// userRows assigns missing IDs and creation times, normalizes emails and
+// flattens users into userWriter rows. It fails on the first invalid email.
+func userRows(users []*models.User) ([][]interface{}, error) {
+	now := time.Now()
+	rows := make([][]interface{}, len(users))
 	for i, user := range users {
-		placeholders[i] = "(?, ?, ?, ?, ?)"
-		args = append(args,
+		if user.ID == "" {
+			user.ID = dbid.New()
+		}
+		if !user.CreatedAt.Valid {
+			user.CreatedAt = null.TimeFrom(now)
+		}
+		if err := normalizeUserEmail(user); err != nil {
+			return nil, fmt.Errorf("user %d: %w", i, err)
+		}
+		rows[i] = []interface{}{
 			user.ID,
 			user.Username,
+			user.Email,
 			user.DisplayName,
 			user.Gender,
 			user.CreatedAt,
-		)
+		}
 	}
+	return rows, nil
+}
 
-	query := fmt.Sprintf(`
-		INSERT INTO users (id, username, display_name, gender, created_at)
-		VALUES %s
-	`, strings.Join(placeholders, ", "))
+// normalizeUserEmail validates user's email and lower-cases it in place with
+// users.NormalizeEmail, as every other write path does. NULL is left alone.
+func normalizeUserEmail(user *models.User) error {
+	if !user.Email.Valid {
+		return nil
+	}
 
-	_, err := exec.ExecContext(ctx, query, args...)
+	email, err := users.NormalizeEmail(user.Email.String)
 	if err != nil {
-		return fmt.Errorf("bulk insert users: %w", err)
+		return err
 	}
+	user.Email.String = email
 
 	return nil
 }
 
+// Update writes the given columns of an existing user
+func (r *UserRepo) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	user *models.User,
+	columns boil.Columns,
+) (*models.User, error) {
+	_, err := user.Update(ctx, exec, columns)
+	if err != nil {
+		return nil, fmt.Errorf("update user: %w", errlib.FromDB(err))
+	}
+
+	return user, nil
+}
+
 // Upsert inserts or updates a user
 func (r *UserRepo) Upsert(
 	ctx context.Context,
 	exec boil.ContextExecutor,
 	user *models.User,
 ) (*models.User, error) {
+	if user.ID == "" {
+		user.ID = dbid.New()
+	}
+	if err := normalizeUserEmail(user); err != nil {
+		return nil, fmt.Errorf("upsert user: %w", err)
+	}
+
 	err := user.Upsert(
 		ctx,
 		exec,
@@ -87,7 +182,7 @@ func (r *UserRepo) Upsert(
 		boil.Infer(), // insert columns
 	)
 	if err != nil {
-		return nil, fmt.Errorf("upsert user: %w", err)
+		return nil, fmt.Errorf("upsert user: %w", errlib.FromDB(err))
 	}
 
 	return user, nil
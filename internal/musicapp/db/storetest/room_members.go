@@ -0,0 +1,289 @@
+package storetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/models"
+)
+
+// memberIDs returns the IDs of found, in order
+func memberIDs(found []*room_members.RoomMembers) []string {
+	ids := make([]string, len(found))
+	for i, member := range found {
+		ids[i] = member.ID
+	}
+	return ids
+}
+
+var roomMemberCases = []testCase{
+	{
+		name: "success-insert-fills-joined-at",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{})
+			user := b.insertUser(t, &models.User{})
+
+			member, err := b.RoomMemberRepo.Insert(ctx, b.Exec, &models.RoomMember{RoomID: room.ID, UserID: user.ID})
+			require.NoError(t, err)
+
+			found, err := b.RoomMembers.RoomMember(ctx, b.Exec, room_members.RoomMemberQueryFilter{IDs: []string{member.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, room.ID, found.RoomID)
+			assert.Equal(t, user.ID, found.UserID)
+			assert.True(t, found.IsActive())
+			assert.WithinDuration(t, time.Now(), found.JoinedAt, 5*time.Second)
+		},
+	},
+	{
+		name: "error-insert-unknown-room-or-user",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{})
+			user := b.insertUser(t, &models.User{})
+
+			_, err := b.RoomMemberRepo.Insert(ctx, b.Exec, &models.RoomMember{RoomID: id(99), UserID: user.ID})
+			assert.ErrorIs(t, err, errlib.ErrForeignKey)
+
+			_, err = b.RoomMemberRepo.Insert(ctx, b.Exec, &models.RoomMember{RoomID: room.ID, UserID: id(99)})
+			assert.ErrorIs(t, err, errlib.ErrForeignKey)
+		},
+	},
+	{
+		name: "error-second-active-membership-conflicts",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{})
+			user := b.insertUser(t, &models.User{})
+			b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID, UserID: user.ID})
+
+			_, err := b.RoomMemberRepo.Insert(ctx, b.Exec, &models.RoomMember{RoomID: room.ID, UserID: user.ID})
+			assert.ErrorIs(t, err, errlib.ErrConflict)
+
+			count, err := b.RoomMembers.CountRoomMembers(ctx, b.Exec, room_members.RoomMemberQueryFilter{
+				RoomID: null.StringFrom(room.ID),
+				Active: null.BoolFrom(true),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+		},
+	},
+	{
+		name: "success-former-memberships-do-not-conflict",
+		run: func(t *testing.T, b Backend) {
+			// Rejoining is fine however many times the user left, even within
+			// the same second
+			room := b.insertRoom(t, &models.Room{})
+			user := b.insertUser(t, &models.User{})
+			b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID, UserID: user.ID, LeftAt: at(time.Hour)})
+			b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID, UserID: user.ID, LeftAt: at(time.Hour)})
+			b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID, UserID: user.ID})
+
+			count, err := b.RoomMembers.CountRoomMembers(context.Background(), b.Exec, room_members.RoomMemberQueryFilter{
+				RoomID: null.StringFrom(room.ID),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), count)
+		},
+	},
+	{
+		name: "error-reopening-membership-conflicts",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{})
+			user := b.insertUser(t, &models.User{})
+			former := b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID, UserID: user.ID, LeftAt: at(time.Hour)})
+			b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID, UserID: user.ID})
+
+			_, err := b.RoomMemberRepo.Update(ctx, b.Exec, &models.RoomMember{ID: former.ID},
+				boil.Whitelist(models.RoomMemberColumns.LeftAt))
+			assert.ErrorIs(t, err, errlib.ErrConflict)
+		},
+	},
+	{
+		name: "error-bulk-insert-is-all-or-nothing",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{})
+			user := b.insertUser(t, &models.User{})
+
+			err := b.RoomMemberRepo.BulkInsert(ctx, b.Exec, []*models.RoomMember{
+				{RoomID: room.ID, UserID: user.ID},
+				{RoomID: room.ID, UserID: id(99)},
+			})
+			assert.ErrorIs(t, err, errlib.ErrForeignKey)
+
+			exists, err := b.RoomMembers.RoomMembersExist(ctx, b.Exec, room_members.RoomMemberQueryFilter{})
+			require.NoError(t, err)
+			assert.False(t, exists)
+		},
+	},
+	{
+		name: "success-filters",
+		run: func(t *testing.T, b Backend) {
+			room := b.insertRoom(t, &models.Room{ID: id(11)})
+			other := b.insertRoom(t, &models.Room{ID: id(12)})
+			alice := b.insertUser(t, &models.User{ID: id(1)})
+			bob := b.insertUser(t, &models.User{ID: id(2)})
+			b.insertRoomMember(t, &models.RoomMember{ID: id(21), RoomID: room.ID, UserID: alice.ID, JoinedAt: at(0)})
+			b.insertRoomMember(t, &models.RoomMember{ID: id(22), RoomID: room.ID, UserID: bob.ID, JoinedAt: at(time.Hour), LeftAt: at(2 * time.Hour)})
+			b.insertRoomMember(t, &models.RoomMember{ID: id(23), RoomID: other.ID, UserID: bob.ID, JoinedAt: at(3 * time.Hour), LeftAt: at(4 * time.Hour)})
+			b.insertRoomMember(t, &models.RoomMember{ID: id(24), RoomID: other.ID, UserID: alice.ID, JoinedAt: at(5 * time.Hour)})
+
+			filters := []struct {
+				name     string
+				filter   room_members.RoomMemberQueryFilter
+				expected []string
+			}{
+				{name: "none", filter: room_members.RoomMemberQueryFilter{}, expected: []string{id(21), id(22), id(23), id(24)}},
+				{name: "active", filter: room_members.RoomMemberQueryFilter{Active: null.BoolFrom(true)}, expected: []string{id(21), id(24)}},
+				{name: "former", filter: room_members.RoomMemberQueryFilter{Active: null.BoolFrom(false)}, expected: []string{id(22), id(23)}},
+				{name: "room", filter: room_members.RoomMemberQueryFilter{RoomID: null.StringFrom(other.ID)}, expected: []string{id(23), id(24)}},
+				{name: "user", filter: room_members.RoomMemberQueryFilter{UserID: null.StringFrom(alice.ID)}, expected: []string{id(21), id(24)}},
+				{name: "joined-at", filter: room_members.RoomMemberQueryFilter{JoinedAt: at(time.Hour)}, expected: []string{id(22)}},
+				{name: "left-at", filter: room_members.RoomMemberQueryFilter{LeftAt: at(4 * time.Hour)}, expected: []string{id(23)}},
+				{name: "joined-before-excludes-bound", filter: room_members.RoomMemberQueryFilter{JoinedBefore: at(time.Hour)}, expected: []string{id(21)}},
+				{name: "left-after-only-former", filter: room_members.RoomMemberQueryFilter{LeftAfter: at(3 * time.Hour)}, expected: []string{id(23)}},
+				{name: "room-and-user", filter: room_members.RoomMemberQueryFilter{RoomID: null.StringFrom(room.ID), UserID: null.StringFrom(bob.ID)}, expected: []string{id(22)}},
+			}
+
+			for _, f := range filters {
+				t.Run(f.name, func(t *testing.T) {
+					found, err := b.RoomMembers.RoomMembers(context.Background(), b.Exec, f.filter)
+					require.NoError(t, err)
+					assert.Equal(t, f.expected, memberIDs(found))
+				})
+			}
+		},
+	},
+	{
+		name: "success-room-and-user-filters-accept-any-uuid-form",
+		run: func(t *testing.T, b Backend) {
+			room := b.insertRoom(t, &models.Room{})
+			user := b.insertUser(t, &models.User{})
+			member := b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID, UserID: user.ID})
+
+			filters := []room_members.RoomMemberQueryFilter{
+				{RoomID: null.StringFrom("{" + strings.ToUpper(room.ID) + "}")},
+				{UserID: null.StringFrom("urn:uuid:" + user.ID)},
+			}
+			for _, filter := range filters {
+				found, err := b.RoomMembers.RoomMembers(context.Background(), b.Exec, filter)
+				require.NoError(t, err)
+				assert.Equal(t, []string{member.ID}, memberIDs(found))
+			}
+		},
+	},
+	{
+		name: "error-invalid-room-or-user-filter",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			_, err := b.RoomMembers.RoomMembers(ctx, b.Exec, room_members.RoomMemberQueryFilter{RoomID: null.StringFrom("42")})
+			assert.ErrorIs(t, err, errlib.ErrInvalidID)
+
+			_, err = b.RoomMembers.CountRoomMembers(ctx, b.Exec, room_members.RoomMemberQueryFilter{UserID: null.StringFrom("42")})
+			assert.ErrorIs(t, err, errlib.ErrInvalidID)
+		},
+	},
+	{
+		name: "success-pages-by-joined-at",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			// IDs run against join order, so only the sort can explain the result
+			room := b.insertRoom(t, &models.Room{})
+			for i := 1; i <= 5; i++ {
+				b.insertRoomMember(t, &models.RoomMember{
+					ID:       id(30 - i),
+					RoomID:   room.ID,
+					JoinedAt: at(time.Duration(i) * time.Minute),
+				})
+			}
+
+			filter := room_members.RoomMemberQueryFilter{
+				Sorts: []room_members.RoomMemberSort{{Field: room_members.RoomMemberSortJoinedAt, Direction: querylib.SortAsc}},
+				Limit: null.IntFrom(2),
+			}
+			var pages [][]string
+			for {
+				page, err := b.RoomMembers.RoomMembersPage(ctx, b.Exec, filter)
+				require.NoError(t, err)
+				pages = append(pages, memberIDs(page.RoomMembers))
+				if page.NextCursor == "" {
+					break
+				}
+				filter.After = null.StringFrom(page.NextCursor)
+			}
+
+			assert.Equal(t, [][]string{{id(29), id(28)}, {id(27), id(26)}, {id(25)}}, pages)
+		},
+	},
+	{
+		name: "success-update-closes-memberships",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{})
+			first := b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID})
+			second := b.insertRoomMember(t, &models.RoomMember{RoomID: room.ID})
+
+			err := b.RoomMembers.Update(ctx, b.Exec, room_members.UpdateRoomMember{
+				IDs:    []string{first.ID, second.ID},
+				LeftAt: at(time.Hour),
+			})
+			require.NoError(t, err)
+
+			found, err := b.RoomMembers.RoomMembers(ctx, b.Exec, room_members.RoomMemberQueryFilter{RoomID: null.StringFrom(room.ID)})
+			require.NoError(t, err)
+			require.Len(t, found, 2)
+			for _, member := range found {
+				assert.False(t, member.IsActive())
+				assertTime(t, base.Add(time.Hour), member.LeftAt.Time)
+			}
+		},
+	},
+	{
+		name: "success-repo-update-writes-whitelisted-columns",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			member := b.insertRoomMember(t, &models.RoomMember{JoinedAt: at(0)})
+			other := b.insertRoom(t, &models.Room{})
+
+			member.RoomID = other.ID
+			member.LeftAt = at(time.Hour)
+			_, err := b.RoomMemberRepo.Update(ctx, b.Exec, member, boil.Whitelist(models.RoomMemberColumns.LeftAt))
+			require.NoError(t, err)
+
+			found, err := b.RoomMembers.RoomMember(ctx, b.Exec, room_members.RoomMemberQueryFilter{IDs: []string{member.ID}})
+			require.NoError(t, err)
+			assert.NotEqual(t, other.ID, found.RoomID)
+			assertTime(t, base.Add(time.Hour), found.LeftAt.Time)
+		},
+	},
+	{
+		name: "error-update-unknown-room",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			member := b.insertRoomMember(t, &models.RoomMember{})
+
+			err := b.RoomMembers.Update(ctx, b.Exec, room_members.UpdateRoomMember{
+				IDs:    []string{member.ID},
+				RoomID: null.StringFrom(id(99)),
+			})
+			assert.ErrorIs(t, err, errlib.ErrForeignKey)
+
+			found, err := b.RoomMembers.RoomMember(ctx, b.Exec, room_members.RoomMemberQueryFilter{IDs: []string{member.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, member.RoomID, found.RoomID)
+		},
+	},
+}
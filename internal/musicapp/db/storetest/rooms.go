@@ -0,0 +1,211 @@
+package storetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/models"
+)
+
+// roomNames returns the names of found, in order
+func roomNames(found []*rooms.Room) []string {
+	names := make([]string, len(found))
+	for i, room := range found {
+		names[i] = room.Name
+	}
+	return names
+}
+
+var roomCases = []testCase{
+	{
+		name: "success-insert-fills-defaults",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			creator := b.insertUser(t, &models.User{})
+
+			room, err := b.RoomRepo.Insert(ctx, b.Exec, &models.Room{Name: "Lobby", CreatedBy: creator.ID})
+			require.NoError(t, err)
+
+			found, err := b.Rooms.Room(ctx, b.Exec, rooms.RoomQueryFilter{IDs: []string{room.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, "Lobby", found.Name)
+			assert.Equal(t, creator.ID, found.CreatedBy)
+			assert.True(t, found.IsActive)
+			assert.WithinDuration(t, time.Now(), found.CreatedAt, 5*time.Second)
+		},
+	},
+	{
+		name: "error-insert-unknown-creator",
+		run: func(t *testing.T, b Backend) {
+			_, err := b.RoomRepo.Insert(context.Background(), b.Exec, &models.Room{Name: "Lobby", CreatedBy: id(99)})
+			assert.ErrorIs(t, err, errlib.ErrForeignKey)
+		},
+	},
+	{
+		name: "success-filters",
+		run: func(t *testing.T, b Backend) {
+			alice := b.insertUser(t, &models.User{ID: id(1)})
+			bob := b.insertUser(t, &models.User{ID: id(2)})
+			b.insertRoom(t, &models.Room{ID: id(11), Name: "Jazz Lounge", CreatedBy: alice.ID, CreatedAt: at(0)})
+			b.insertRoom(t, &models.Room{ID: id(12), Name: "Rock Cave", CreatedBy: alice.ID, CreatedAt: at(time.Hour)})
+			b.insertRoom(t, &models.Room{ID: id(13), Name: "jazz club", CreatedBy: bob.ID, CreatedAt: at(2 * time.Hour), IsActive: null.BoolFrom(false)})
+
+			filters := []struct {
+				name     string
+				filter   rooms.RoomQueryFilter
+				expected []string
+			}{
+				{name: "none", filter: rooms.RoomQueryFilter{}, expected: []string{"Jazz Lounge", "Rock Cave", "jazz club"}},
+				{name: "name-ignores-case", filter: rooms.RoomQueryFilter{Name: null.StringFrom("ROCK CAVE")}, expected: []string{"Rock Cave"}},
+				{name: "created-by", filter: rooms.RoomQueryFilter{CreatedBy: null.StringFrom(alice.ID)}, expected: []string{"Jazz Lounge", "Rock Cave"}},
+				{name: "created-by-ids", filter: rooms.RoomQueryFilter{CreatedByIDs: []string{bob.ID, id(99)}}, expected: []string{"jazz club"}},
+				{name: "inactive", filter: rooms.RoomQueryFilter{IsActive: null.BoolFrom(false)}, expected: []string{"jazz club"}},
+				{name: "created-at", filter: rooms.RoomQueryFilter{CreatedAt: at(time.Hour)}, expected: []string{"Rock Cave"}},
+				{name: "created-after", filter: rooms.RoomQueryFilter{CreatedAfter: at(time.Hour)}, expected: []string{"Rock Cave", "jazz club"}},
+				{name: "name-prefix", filter: rooms.RoomQueryFilter{NamePrefix: null.StringFrom("JAZZ")}, expected: []string{"Jazz Lounge", "jazz club"}},
+				{name: "name-contains", filter: rooms.RoomQueryFilter{NameContains: null.StringFrom("c")}, expected: []string{"Rock Cave", "jazz club"}},
+			}
+
+			for _, f := range filters {
+				t.Run(f.name, func(t *testing.T) {
+					found, err := b.Rooms.Rooms(context.Background(), b.Exec, f.filter)
+					require.NoError(t, err)
+					assert.Equal(t, f.expected, roomNames(found))
+				})
+			}
+		},
+	},
+	{
+		name: "error-invalid-created-by",
+		run: func(t *testing.T, b Backend) {
+			_, err := b.Rooms.CountRooms(context.Background(), b.Exec, rooms.RoomQueryFilter{CreatedBy: null.StringFrom("42")})
+			assert.ErrorIs(t, err, errlib.ErrInvalidID)
+		},
+	},
+	{
+		name: "success-pages-by-name-desc",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			for _, name := range []string{"b", "D", "a", "c", "E"} {
+				b.insertRoom(t, &models.Room{Name: name})
+			}
+
+			filter := rooms.RoomQueryFilter{
+				Sorts: []rooms.RoomSort{{Field: rooms.RoomSortName, Direction: querylib.SortDesc}},
+				Limit: null.IntFrom(2),
+			}
+			var pages [][]string
+			for {
+				page, err := b.Rooms.RoomsPage(ctx, b.Exec, filter)
+				require.NoError(t, err)
+				pages = append(pages, roomNames(page.Rooms))
+				if page.NextCursor == "" {
+					break
+				}
+				filter.After = null.StringFrom(page.NextCursor)
+			}
+
+			assert.Equal(t, [][]string{{"E", "D"}, {"c", "b"}, {"a"}}, pages)
+		},
+	},
+	{
+		name: "success-update",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{Name: "Lobby"})
+			other := b.insertRoom(t, &models.Room{Name: "Other"})
+			creator := b.insertUser(t, &models.User{})
+
+			err := b.Rooms.Update(ctx, b.Exec, rooms.UpdateRoom{
+				IDs:       []string{room.ID, other.ID},
+				IsActive:  null.BoolFrom(false),
+				CreatedBy: null.StringFrom(creator.ID),
+			})
+			require.NoError(t, err)
+
+			found, err := b.Rooms.Rooms(ctx, b.Exec, rooms.RoomQueryFilter{IsActive: null.BoolFrom(false)})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"Lobby", "Other"}, roomNames(found))
+			for _, r := range found {
+				assert.Equal(t, creator.ID, r.CreatedBy)
+			}
+		},
+	},
+	{
+		name: "error-update-unknown-creator-changes-nothing",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{Name: "Lobby"})
+
+			err := b.Rooms.Update(ctx, b.Exec, rooms.UpdateRoom{
+				IDs:       []string{room.ID},
+				Name:      null.StringFrom("Renamed"),
+				CreatedBy: null.StringFrom(id(99)),
+			})
+			assert.ErrorIs(t, err, errlib.ErrForeignKey)
+
+			found, err := b.Rooms.Room(ctx, b.Exec, rooms.RoomQueryFilter{IDs: []string{room.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, "Lobby", found.Name)
+		},
+	},
+	{
+		name: "success-lock-room",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			room := b.insertRoom(t, &models.Room{Name: "Lobby", IsActive: null.BoolFrom(false)})
+
+			err := b.Tx.WithTx(ctx, b.Exec, func(tx boil.ContextExecutor) error {
+				locked, err := b.RoomLocks.LockRoom(ctx, tx, strings.ToUpper(room.ID))
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, room.ID, locked.ID)
+				assert.Equal(t, "Lobby", locked.Name)
+				assert.False(t, locked.IsActive.Bool)
+				return nil
+			})
+			require.NoError(t, err)
+		},
+	},
+	{
+		name: "error-lock-unknown-room",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			_, err := b.RoomLocks.LockRoom(ctx, b.Exec, id(99))
+			assert.ErrorIs(t, err, errlib.ErrNotFound)
+
+			_, err = b.RoomLocks.LockRoom(ctx, b.Exec, "42")
+			assert.ErrorIs(t, err, errlib.ErrInvalidID)
+		},
+	},
+	{
+		name: "error-room-expects-exactly-one",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			b.insertRoom(t, &models.Room{})
+			b.insertRoom(t, &models.Room{})
+
+			_, err := b.Rooms.Room(ctx, b.Exec, rooms.RoomQueryFilter{IDs: []string{id(99)}})
+			assert.ErrorIs(t, err, errlib.ErrNotFound)
+
+			_, err = b.Rooms.Room(ctx, b.Exec, rooms.RoomQueryFilter{IsActive: null.BoolFrom(true)})
+			assert.ErrorIs(t, err, errlib.ErrMultipleResults)
+
+			exists, err := b.Rooms.RoomsExist(ctx, b.Exec, rooms.RoomQueryFilter{IsActive: null.BoolFrom(false)})
+			require.NoError(t, err)
+			assert.False(t, exists)
+		},
+	},
+}
@@ -0,0 +1,204 @@
+// Package storetest is the conformance suite for users, rooms and
+// room_members backends. MySQL (the stores with db/repo) and memdb both run
+// it, so logic and handler tests written against the in-memory backend hold
+// against MySQL too. A behaviour one backend gets wrong belongs here as a
+// new case.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/models"
+)
+
+// Backend is one complete set of stores and repos over a single database
+type Backend struct {
+	Exec boil.ContextExecutor
+	Tx   txn.Transactor
+
+	Users          users.Store
+	UserRepo       users.Repo
+	Rooms          rooms.Store
+	RoomLocks      room_members.RoomStore
+	RoomRepo       rooms.Repo
+	RoomMembers    room_members.Store
+	RoomMemberRepo room_members.Repo
+}
+
+// testCase is one conformance check, run against a fresh backend
+type testCase struct {
+	name string
+	run  func(t *testing.T, b Backend)
+}
+
+// Run runs the suite. newBackend is called for every case and must return a
+// backend whose tables are empty.
+func Run(t *testing.T, newBackend func(t *testing.T) Backend) {
+	groups := []struct {
+		name  string
+		cases []testCase
+	}{
+		{name: "Users", cases: userCases},
+		{name: "Rooms", cases: roomCases},
+		{name: "RoomMembers", cases: roomMemberCases},
+		{name: "Tx", cases: txCases},
+	}
+
+	for _, group := range groups {
+		t.Run(group.name, func(t *testing.T) {
+			for _, tc := range group.cases {
+				t.Run(tc.name, func(t *testing.T) {
+					tc.run(t, newBackend(t))
+				})
+			}
+		})
+	}
+}
+
+// base is the reference time seeded rows are created around. It is a whole
+// second, so TIMESTAMP rounding leaves it unchanged.
+var base = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// at returns base plus d as a nullable timestamp
+func at(d time.Duration) null.Time {
+	return null.TimeFrom(base.Add(d))
+}
+
+// id returns a fixed UUID ending in n, for cases that depend on ID order or
+// case-insensitive ID matching
+func id(n int) string {
+	return fmt.Sprintf("abcdef00-0000-4000-8000-%012d", n)
+}
+
+var seq atomic.Uint64
+
+// insertUser inserts user, filling a unique username and a gender when unset
+func (b Backend) insertUser(t *testing.T, user *models.User) *models.User {
+	t.Helper()
+
+	if user.Username == "" {
+		user.Username = fmt.Sprintf("user%d", seq.Add(1))
+	}
+	if !user.Gender.Valid {
+		user.Gender = null.StringFrom(string(users.GenderOther))
+	}
+
+	inserted, err := b.UserRepo.Insert(context.Background(), b.Exec, user)
+	require.NoError(t, err)
+	return inserted
+}
+
+// insertRoom inserts room, creating its creator when unset
+func (b Backend) insertRoom(t *testing.T, room *models.Room) *models.Room {
+	t.Helper()
+
+	if room.Name == "" {
+		room.Name = fmt.Sprintf("room%d", seq.Add(1))
+	}
+	if room.CreatedBy == "" {
+		room.CreatedBy = b.insertUser(t, &models.User{}).ID
+	}
+
+	inserted, err := b.RoomRepo.Insert(context.Background(), b.Exec, room)
+	require.NoError(t, err)
+	return inserted
+}
+
+// insertRoomMember inserts roomMember, creating its room and user when unset
+func (b Backend) insertRoomMember(t *testing.T, roomMember *models.RoomMember) *models.RoomMember {
+	t.Helper()
+
+	if roomMember.RoomID == "" {
+		roomMember.RoomID = b.insertRoom(t, &models.Room{}).ID
+	}
+	if roomMember.UserID == "" {
+		roomMember.UserID = b.insertUser(t, &models.User{}).ID
+	}
+
+	inserted, err := b.RoomMemberRepo.Insert(context.Background(), b.Exec, roomMember)
+	require.NoError(t, err)
+	return inserted
+}
+
+// assertTime checks two instants are equal, whatever their location
+func assertTime(t *testing.T, expected, actual time.Time) {
+	t.Helper()
+	assert.True(t, expected.Equal(actual), "expected %s, got %s", expected, actual)
+}
+
+var txCases = []testCase{
+	{
+		name: "success-commits",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			err := b.Tx.WithTx(ctx, b.Exec, func(tx boil.ContextExecutor) error {
+				_, err := b.UserRepo.Insert(ctx, tx, &models.User{Username: "alice", Gender: null.StringFrom("female")})
+				return err
+			})
+			require.NoError(t, err)
+
+			count, err := b.Users.CountUsers(ctx, b.Exec, users.UserQueryFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+		},
+	},
+	{
+		name: "error-rolls-back",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			err := b.Tx.WithTx(ctx, b.Exec, func(tx boil.ContextExecutor) error {
+				if _, err := b.UserRepo.Insert(ctx, tx, &models.User{Username: "alice", Gender: null.StringFrom("female")}); err != nil {
+					return err
+				}
+				return fmt.Errorf("boom")
+			})
+			require.EqualError(t, err, "boom")
+
+			count, err := b.Users.CountUsers(ctx, b.Exec, users.UserQueryFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), count)
+		},
+	},
+	{
+		name: "nested-error-rolls-back-inner-only",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			err := b.Tx.WithTx(ctx, b.Exec, func(tx boil.ContextExecutor) error {
+				if _, err := b.UserRepo.Insert(ctx, tx, &models.User{Username: "alice", Gender: null.StringFrom("female")}); err != nil {
+					return err
+				}
+
+				inner := b.Tx.WithTx(ctx, tx, func(tx boil.ContextExecutor) error {
+					if _, err := b.UserRepo.Insert(ctx, tx, &models.User{Username: "bob", Gender: null.StringFrom("male")}); err != nil {
+						return err
+					}
+					return fmt.Errorf("boom")
+				})
+				assert.EqualError(t, inner, "boom")
+				return nil
+			})
+			require.NoError(t, err)
+
+			found, err := b.Users.Users(ctx, b.Exec, users.UserQueryFilter{})
+			require.NoError(t, err)
+			require.Len(t, found, 1)
+			assert.Equal(t, "alice", found[0].Username)
+		},
+	},
+}
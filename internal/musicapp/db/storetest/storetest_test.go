@@ -0,0 +1,49 @@
+package storetest_test
+
+import (
+	"testing"
+
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/internal/musicapp/db/repo"
+	"mlm/internal/musicapp/db/storetest"
+	"mlm/internal/musicapp/db/txn"
+	roommemberstore "mlm/internal/musicapp/lib/room_members/store"
+	roomstore "mlm/internal/musicapp/lib/rooms/store"
+	userstore "mlm/internal/musicapp/lib/users/store"
+	"mlm/internal/testsuite"
+)
+
+func TestMemory(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Backend {
+		db := memdb.New()
+		return storetest.Backend{
+			Tx:             db,
+			Users:          memdb.NewUserStore(db),
+			UserRepo:       memdb.NewUserRepo(db),
+			Rooms:          memdb.NewRoomStore(db),
+			RoomLocks:      memdb.NewRoomStore(db),
+			RoomRepo:       memdb.NewRoomRepo(db),
+			RoomMembers:    memdb.NewRoomMemberStore(db),
+			RoomMemberRepo: memdb.NewRoomMemberRepo(db),
+		}
+	})
+}
+
+func TestMySQL(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Backend {
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		return storetest.Backend{
+			Exec:           testSuite.BackendAppDb(),
+			Tx:             txn.MySQL{},
+			Users:          userstore.New(),
+			UserRepo:       repo.NewUserRepo(),
+			Rooms:          roomstore.New(),
+			RoomLocks:      roomstore.New(),
+			RoomRepo:       repo.NewRoomRepo(),
+			RoomMembers:    roommemberstore.New(),
+			RoomMemberRepo: repo.NewRoomMember(),
+		}
+	})
+}
@@ -0,0 +1,463 @@
+package storetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/internal/util/validationlib"
+	"mlm/models"
+)
+
+// seedUsers inserts the users the filter cases run against
+func (b Backend) seedUsers(t *testing.T) {
+	b.insertUser(t, &models.User{
+		ID:          id(1),
+		Username:    "alice",
+		Email:       null.StringFrom("alice@example.com"),
+		DisplayName: null.StringFrom("Alice Smith"),
+		Gender:      null.StringFrom("female"),
+		CreatedAt:   at(0),
+	})
+	b.insertUser(t, &models.User{
+		ID:          id(2),
+		Username:    "bob",
+		DisplayName: null.StringFrom("100% Bob"),
+		Gender:      null.StringFrom("male"),
+		CreatedAt:   at(time.Hour),
+	})
+	b.insertUser(t, &models.User{
+		ID:        id(3),
+		Username:  "a_b",
+		Gender:    null.StringFrom("other"),
+		CreatedAt: at(2 * time.Hour),
+	})
+	b.insertUser(t, &models.User{
+		ID:          id(4),
+		Username:    "axb",
+		DisplayName: null.StringFrom("100 Axb"),
+		Gender:      null.StringFrom("male"),
+		CreatedAt:   at(3 * time.Hour),
+	})
+	b.insertUser(t, &models.User{
+		ID:        id(5),
+		Username:  "carol",
+		Gender:    null.StringFrom("female"),
+		CreatedAt: at(4 * time.Hour),
+		DeletedAt: at(5 * time.Hour),
+	})
+}
+
+// usernames returns the usernames of found, in order
+func usernames(found []*users.User) []string {
+	names := make([]string, len(found))
+	for i, user := range found {
+		names[i] = user.Username
+	}
+	return names
+}
+
+var byID = []users.UserSort{{Field: users.UserSortID}}
+
+var userCases = []testCase{
+	{
+		name: "success-insert-fills-defaults",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			user, err := b.UserRepo.Insert(ctx, b.Exec, &models.User{Username: "alice", Gender: null.StringFrom("female")})
+			require.NoError(t, err)
+			_, err = dbid.Parse(user.ID)
+			require.NoError(t, err)
+
+			found, err := b.Users.User(ctx, b.Exec, users.UserQueryFilter{IDs: []string{user.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, "alice", found.Username)
+			assert.Equal(t, users.GenderFemale, found.Gender)
+			assert.Empty(t, found.Email)
+			assert.WithinDuration(t, time.Now(), found.CreatedAt, 5*time.Second)
+			assert.False(t, found.DeletedAt.Valid)
+		},
+	},
+	{
+		name: "success-created-at-is-rounded-to-the-second",
+		run: func(t *testing.T, b Backend) {
+			user := b.insertUser(t, &models.User{CreatedAt: at(700 * time.Millisecond)})
+
+			found, err := b.Users.User(context.Background(), b.Exec, users.UserQueryFilter{IDs: []string{user.ID}})
+			require.NoError(t, err)
+			assertTime(t, base.Add(time.Second), found.CreatedAt)
+		},
+	},
+	{
+		name: "success-several-users-without-email",
+		run: func(t *testing.T, b Backend) {
+			b.insertUser(t, &models.User{})
+			b.insertUser(t, &models.User{})
+
+			count, err := b.Users.CountUsers(context.Background(), b.Exec, users.UserQueryFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+		},
+	},
+	{
+		name: "error-insert-duplicate-username-ignores-case",
+		run: func(t *testing.T, b Backend) {
+			b.insertUser(t, &models.User{Username: "alice"})
+
+			_, err := b.UserRepo.Insert(context.Background(), b.Exec, &models.User{
+				Username: "ALICE",
+				Gender:   null.StringFrom("female"),
+			})
+			assert.ErrorIs(t, err, errlib.ErrConflict)
+		},
+	},
+	{
+		name: "error-insert-duplicate-email",
+		run: func(t *testing.T, b Backend) {
+			b.insertUser(t, &models.User{Email: null.StringFrom("alice@example.com")})
+
+			_, err := b.UserRepo.Insert(context.Background(), b.Exec, &models.User{
+				Username: "alice2",
+				Email:    null.StringFrom("alice@example.com"),
+				Gender:   null.StringFrom("female"),
+			})
+			assert.ErrorIs(t, err, errlib.ErrConflict)
+		},
+	},
+	{
+		name: "error-bulk-insert-is-all-or-nothing",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			b.insertUser(t, &models.User{Username: "alice"})
+
+			err := b.UserRepo.BulkInsert(ctx, b.Exec, []*models.User{
+				{Username: "bob", Gender: null.StringFrom("male")},
+				{Username: "alice", Gender: null.StringFrom("female")},
+			})
+			assert.ErrorIs(t, err, errlib.ErrConflict)
+
+			exists, err := b.Users.UsersExist(ctx, b.Exec, users.UserQueryFilter{Username: null.StringFrom("bob")})
+			require.NoError(t, err)
+			assert.False(t, exists)
+		},
+	},
+	{
+		name: "success-writes-normalize-email",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			inserted, err := b.UserRepo.Insert(ctx, b.Exec, &models.User{
+				Username: "alice",
+				Email:    null.StringFrom(" Alice@Example.COM "),
+				Gender:   null.StringFrom("female"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "alice@example.com", inserted.Email.String)
+
+			err = b.UserRepo.BulkInsert(ctx, b.Exec, []*models.User{
+				{Username: "bob", Email: null.StringFrom("BOB@Example.com"), Gender: null.StringFrom("male")},
+				{Username: "carol", Gender: null.StringFrom("female")},
+			})
+			require.NoError(t, err)
+
+			found, err := b.Users.Users(ctx, b.Exec, users.UserQueryFilter{
+				Sorts: []users.UserSort{{Field: users.UserSortUsername}},
+			})
+			require.NoError(t, err)
+			require.Len(t, found, 3)
+			assert.Equal(t, "alice@example.com", found[0].Email)
+			assert.Equal(t, "bob@example.com", found[1].Email)
+			assert.Empty(t, found[2].Email)
+		},
+	},
+	{
+		name: "error-invalid-email-fails-write",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+
+			_, err := b.UserRepo.Insert(ctx, b.Exec, &models.User{
+				Username: "alice",
+				Email:    null.StringFrom("not-an-email"),
+				Gender:   null.StringFrom("female"),
+			})
+			assert.True(t, validationlib.IsValidationError(err), "got %v", err)
+
+			err = b.UserRepo.BulkInsert(ctx, b.Exec, []*models.User{
+				{Username: "bob", Email: null.StringFrom("Bob@Example.com"), Gender: null.StringFrom("male")},
+				{Username: "carol", Email: null.StringFrom("carol@localhost"), Gender: null.StringFrom("female")},
+			})
+			assert.True(t, validationlib.IsValidationError(err), "got %v", err)
+
+			exists, err := b.Users.UsersExist(ctx, b.Exec, users.UserQueryFilter{})
+			require.NoError(t, err)
+			assert.False(t, exists)
+		},
+	},
+	{
+		name: "success-filters",
+		run: func(t *testing.T, b Backend) {
+			b.seedUsers(t)
+
+			filters := []struct {
+				name     string
+				filter   users.UserQueryFilter
+				expected []string
+			}{
+				{name: "none-hides-deleted", filter: users.UserQueryFilter{}, expected: []string{"alice", "bob", "a_b", "axb"}},
+				{name: "include-deleted", filter: users.UserQueryFilter{IncludeDeleted: true}, expected: []string{"alice", "bob", "a_b", "axb", "carol"}},
+				{name: "ids-any-case", filter: users.UserQueryFilter{IDs: []string{strings.ToUpper(id(2)), id(4), id(99)}}, expected: []string{"bob", "axb"}},
+				{name: "username-ignores-case", filter: users.UserQueryFilter{Username: null.StringFrom("ALICE")}, expected: []string{"alice"}},
+				{name: "email-is-normalized", filter: users.UserQueryFilter{Email: null.StringFrom("  Alice@Example.COM ")}, expected: []string{"alice"}},
+				{name: "gender", filter: users.UserQueryFilter{Gender: null.StringFrom("male")}, expected: []string{"bob", "axb"}},
+				{name: "genders", filter: users.UserQueryFilter{Genders: []string{"female", "other"}}, expected: []string{"alice", "a_b"}},
+				{name: "username-prefix", filter: users.UserQueryFilter{UsernamePrefix: null.StringFrom("A")}, expected: []string{"alice", "a_b", "axb"}},
+				{name: "username-contains-literal-underscore", filter: users.UserQueryFilter{UsernameContains: null.StringFrom("_")}, expected: []string{"a_b"}},
+				{name: "display-name-prefix", filter: users.UserQueryFilter{DisplayNamePrefix: null.StringFrom("alice")}, expected: []string{"alice"}},
+				{name: "display-name-contains-literal-percent", filter: users.UserQueryFilter{DisplayNameContains: null.StringFrom("0%")}, expected: []string{"bob"}},
+				{name: "created-range-is-half-open", filter: users.UserQueryFilter{CreatedAfter: at(time.Hour), CreatedBefore: at(3 * time.Hour)}, expected: []string{"bob", "a_b"}},
+				{name: "conditions-combine", filter: users.UserQueryFilter{Gender: null.StringFrom("male"), UsernamePrefix: null.StringFrom("b")}, expected: []string{"bob"}},
+			}
+
+			for _, f := range filters {
+				t.Run(f.name, func(t *testing.T) {
+					f.filter.Sorts = byID
+					found, err := b.Users.Users(context.Background(), b.Exec, f.filter)
+					require.NoError(t, err)
+					assert.Equal(t, f.expected, usernames(found))
+				})
+			}
+		},
+	},
+	{
+		name: "error-invalid-id",
+		run: func(t *testing.T, b Backend) {
+			_, err := b.Users.Users(context.Background(), b.Exec, users.UserQueryFilter{IDs: []string{"42"}})
+			assert.ErrorIs(t, err, errlib.ErrInvalidID)
+		},
+	},
+	{
+		name: "success-sorts-with-id-tiebreak",
+		run: func(t *testing.T, b Backend) {
+			b.insertUser(t, &models.User{ID: id(3), Username: "carol", CreatedAt: at(0)})
+			b.insertUser(t, &models.User{ID: id(1), Username: "Bob", CreatedAt: at(time.Hour)})
+			b.insertUser(t, &models.User{ID: id(2), Username: "alice", CreatedAt: at(time.Hour)})
+
+			sorts := []struct {
+				name     string
+				sorts    []users.UserSort
+				expected []string
+			}{
+				{name: "default-by-id", expected: []string{"Bob", "alice", "carol"}},
+				{name: "username-ignores-case", sorts: []users.UserSort{{Field: users.UserSortUsername}}, expected: []string{"alice", "Bob", "carol"}},
+				{name: "username-desc", sorts: []users.UserSort{{Field: users.UserSortUsername, Direction: querylib.SortDesc}}, expected: []string{"carol", "Bob", "alice"}},
+				{name: "created-at-desc-then-id", sorts: []users.UserSort{{Field: users.UserSortCreatedAt, Direction: querylib.SortDesc}}, expected: []string{"Bob", "alice", "carol"}},
+			}
+
+			for _, s := range sorts {
+				t.Run(s.name, func(t *testing.T) {
+					found, err := b.Users.Users(context.Background(), b.Exec, users.UserQueryFilter{Sorts: s.sorts})
+					require.NoError(t, err)
+					assert.Equal(t, s.expected, usernames(found))
+				})
+			}
+		},
+	},
+	{
+		name: "error-unknown-sort-field",
+		run: func(t *testing.T, b Backend) {
+			_, err := b.Users.Users(context.Background(), b.Exec, users.UserQueryFilter{
+				Sorts: []users.UserSort{{Field: "email"}},
+			})
+			assert.True(t, validationlib.IsValidationError(err))
+		},
+	},
+	{
+		name: "success-limit-and-offset",
+		run: func(t *testing.T, b Backend) {
+			b.seedUsers(t)
+
+			found, err := b.Users.Users(context.Background(), b.Exec, users.UserQueryFilter{
+				Sorts:  byID,
+				Limit:  null.IntFrom(2),
+				Offset: null.IntFrom(1),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"bob", "a_b"}, usernames(found))
+		},
+	},
+	{
+		name: "success-pages-through-with-cursor",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			for _, name := range []string{"erin", "dave", "carol", "bob", "alice"} {
+				b.insertUser(t, &models.User{Username: name})
+			}
+
+			filter := users.UserQueryFilter{
+				Sorts: []users.UserSort{{Field: users.UserSortUsername, Direction: querylib.SortDesc}},
+				Limit: null.IntFrom(2),
+			}
+			var pages [][]string
+			for {
+				page, err := b.Users.UsersPage(ctx, b.Exec, filter)
+				require.NoError(t, err)
+				pages = append(pages, usernames(page.Users))
+				if page.NextCursor == "" {
+					break
+				}
+				filter.After = null.StringFrom(page.NextCursor)
+			}
+
+			assert.Equal(t, [][]string{{"erin", "dave"}, {"carol", "bob"}, {"alice"}}, pages)
+		},
+	},
+	{
+		name: "error-cursor-misuse",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			b.seedUsers(t)
+
+			page, err := b.Users.UsersPage(ctx, b.Exec, users.UserQueryFilter{Limit: null.IntFrom(1)})
+			require.NoError(t, err)
+			require.NotEmpty(t, page.NextCursor)
+
+			_, err = b.Users.UsersPage(ctx, b.Exec, users.UserQueryFilter{
+				After:  null.StringFrom(page.NextCursor),
+				Offset: null.IntFrom(1),
+			})
+			assert.True(t, validationlib.IsValidationError(err), "cursor with offset")
+
+			_, err = b.Users.UsersPage(ctx, b.Exec, users.UserQueryFilter{
+				After: null.StringFrom(page.NextCursor),
+				Sorts: []users.UserSort{{Field: users.UserSortUsername}},
+			})
+			assert.True(t, validationlib.IsValidationError(err), "cursor for another sort")
+		},
+	},
+	{
+		name: "success-count-and-exist-ignore-paging",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			b.seedUsers(t)
+
+			count, err := b.Users.CountUsers(ctx, b.Exec, users.UserQueryFilter{
+				Gender: null.StringFrom("male"),
+				Limit:  null.IntFrom(1),
+				Offset: null.IntFrom(5),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), count)
+
+			exists, err := b.Users.UsersExist(ctx, b.Exec, users.UserQueryFilter{Username: null.StringFrom("carol")})
+			require.NoError(t, err)
+			assert.False(t, exists, "deleted users are hidden")
+		},
+	},
+	{
+		name: "error-user-expects-exactly-one",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			b.seedUsers(t)
+
+			_, err := b.Users.User(ctx, b.Exec, users.UserQueryFilter{Username: null.StringFrom("nobody")})
+			assert.ErrorIs(t, err, errlib.ErrNotFound)
+
+			_, err = b.Users.User(ctx, b.Exec, users.UserQueryFilter{Gender: null.StringFrom("male")})
+			assert.ErrorIs(t, err, errlib.ErrMultipleResults)
+		},
+	},
+	{
+		name: "success-update",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			user := b.insertUser(t, &models.User{Username: "alice"})
+
+			err := b.Users.Update(ctx, b.Exec, users.UpdateUser{
+				IDs:         []string{user.ID},
+				Username:    null.StringFrom("alice2"),
+				Email:       null.StringFrom(" Alice@Example.com "),
+				DisplayName: null.StringFrom("Alice"),
+				Gender:      null.StringFrom("female"),
+			})
+			require.NoError(t, err)
+
+			found, err := b.Users.User(ctx, b.Exec, users.UserQueryFilter{IDs: []string{user.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, "alice2", found.Username)
+			assert.Equal(t, "alice@example.com", found.Email)
+			assert.Equal(t, "Alice", found.DisplayName)
+			assert.Equal(t, users.GenderFemale, found.Gender)
+		},
+	},
+	{
+		name: "success-update-unknown-id-is-a-no-op",
+		run: func(t *testing.T, b Backend) {
+			err := b.Users.Update(context.Background(), b.Exec, users.UpdateUser{
+				IDs:         []string{id(99)},
+				DisplayName: null.StringFrom("Nobody"),
+			})
+			assert.NoError(t, err)
+		},
+	},
+	{
+		name: "error-update-duplicate-username-changes-nothing",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			b.insertUser(t, &models.User{Username: "alice"})
+			bob := b.insertUser(t, &models.User{Username: "bob"})
+
+			err := b.Users.Update(ctx, b.Exec, users.UpdateUser{
+				IDs:         []string{bob.ID},
+				Username:    null.StringFrom("Alice"),
+				DisplayName: null.StringFrom("Bob"),
+			})
+			assert.ErrorIs(t, err, errlib.ErrConflict)
+
+			found, err := b.Users.User(ctx, b.Exec, users.UserQueryFilter{IDs: []string{bob.ID}})
+			require.NoError(t, err)
+			assert.Equal(t, "bob", found.Username)
+			assert.Empty(t, found.DisplayName)
+		},
+	},
+	{
+		name: "error-update-email-on-several-users",
+		run: func(t *testing.T, b Backend) {
+			err := b.Users.Update(context.Background(), b.Exec, users.UpdateUser{
+				IDs:   []string{b.insertUser(t, &models.User{}).ID, b.insertUser(t, &models.User{}).ID},
+				Email: null.StringFrom("shared@example.com"),
+			})
+			assert.True(t, validationlib.IsValidationError(err))
+		},
+	},
+	{
+		name: "success-repo-update-writes-only-whitelisted-columns",
+		run: func(t *testing.T, b Backend) {
+			ctx := context.Background()
+			user := b.insertUser(t, &models.User{Username: "alice", DisplayName: null.StringFrom("Alice")})
+
+			_, err := b.UserRepo.Update(ctx, b.Exec, &models.User{
+				ID:          user.ID,
+				DisplayName: null.StringFrom("ignored"),
+				DeletedAt:   at(0),
+			}, boil.Whitelist(models.UserColumns.DeletedAt))
+			require.NoError(t, err)
+
+			found, err := b.Users.User(ctx, b.Exec, users.UserQueryFilter{IDs: []string{user.ID}, IncludeDeleted: true})
+			require.NoError(t, err)
+			assert.Equal(t, "Alice", found.DisplayName)
+			require.True(t, found.DeletedAt.Valid)
+			assertTime(t, base, found.DeletedAt.Time)
+		},
+	},
+}
@@ -0,0 +1,152 @@
+package txn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL errors that abort the transaction and are safe to retry from the start
+const (
+	errLockWaitTimeout uint16 = 1205
+	errDeadlock        uint16 = 1213
+)
+
+// MaxAttempts is how many times WithTx runs fn when MySQL reports a deadlock
+// or lock wait timeout
+const MaxAttempts = 3
+
+// retryBackoff is the base delay between attempts; attempt n waits n times it
+var retryBackoff = 20 * time.Millisecond
+
+// savepointSeq makes savepoint names unique across nested calls
+var savepointSeq atomic.Uint64
+
+// WithTx runs fn as a unit of work and commits when it returns nil.
+//
+// exec decides how:
+//   - a *sql.DB (or any boil.ContextBeginner) starts a new transaction. If
+//     fn fails with a deadlock (1213) or lock wait timeout (1205) the whole
+//     transaction is rolled back and fn is retried, up to MaxAttempts times.
+//   - a *sql.Tx, i.e. a call nested inside another WithTx, runs fn inside a
+//     savepoint. Only the savepoint is rolled back on error; retries are left
+//     to the outermost call since MySQL aborts the whole transaction.
+//
+// fn must only use the executor it is given, and must be safe to run more
+// than once. A panic in fn rolls back and is re-raised.
+func WithTx(ctx context.Context, exec boil.ContextExecutor, fn func(tx boil.ContextExecutor) error) error {
+	switch e := exec.(type) {
+	case *sql.Tx:
+		return withSavepoint(ctx, e, fn)
+	case boil.ContextBeginner:
+		return withRetry(ctx, e, fn)
+	}
+	return fmt.Errorf("txn: executor %T can't begin a transaction", exec)
+}
+
+// withRetry runs fn in a new transaction, retrying on transient lock errors
+func withRetry(ctx context.Context, db boil.ContextBeginner, fn func(tx boil.ContextExecutor) error) error {
+	var err error
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		err = withNewTx(ctx, db, fn)
+		if err == nil || !IsRetryable(err) || attempt == MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(time.Duration(attempt) * retryBackoff):
+		}
+	}
+	return err
+}
+
+func withNewTx(ctx context.Context, db boil.ContextBeginner, fn func(tx boil.ContextExecutor) error) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// withSavepoint runs fn inside a savepoint of an existing transaction
+func withSavepoint(ctx context.Context, tx *sql.Tx, fn func(tx boil.ContextExecutor) error) (err error) {
+	name := fmt.Sprintf("mlm_sp_%d", savepointSeq.Add(1))
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		// After a deadlock MySQL has already rolled back the whole
+		// transaction and the savepoint is gone; the outer call retries.
+		if IsRetryable(err) {
+			return err
+		}
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback to savepoint: %w", rbErr))
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
+
+// Transactor runs fn as a unit of work. Logic types take one instead of
+// calling WithTx directly, so a backend without transactions can be swapped
+// in.
+type Transactor interface {
+	WithTx(ctx context.Context, exec boil.ContextExecutor, fn func(tx boil.ContextExecutor) error) error
+}
+
+// MySQL is the Transactor backed by WithTx
+type MySQL struct{}
+
+// WithTx calls the package-level WithTx
+func (MySQL) WithTx(ctx context.Context, exec boil.ContextExecutor, fn func(tx boil.ContextExecutor) error) error {
+	return WithTx(ctx, exec, fn)
+}
+
+// IsRetryable reports whether err is a MySQL deadlock or lock wait timeout
+func IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == errDeadlock || mysqlErr.Number == errLockWaitTimeout
+}
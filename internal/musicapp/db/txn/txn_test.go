@@ -0,0 +1,130 @@
+package txn_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/testsuite"
+	"mlm/models"
+)
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "deadlock", err: &mysql.MySQLError{Number: 1213}, expected: true},
+		{name: "lock-wait-timeout", err: &mysql.MySQLError{Number: 1205}, expected: true},
+		{name: "wrapped-deadlock", err: fmt.Errorf("insert room: %w", &mysql.MySQLError{Number: 1213}), expected: true},
+		{name: "duplicate-key", err: &mysql.MySQLError{Number: 1062}, expected: false},
+		{name: "other-error", err: errors.New("boom"), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, txn.IsRetryable(tc.err))
+		})
+	}
+}
+
+func countUsers(th *testsuite.Helper) int64 {
+	count, err := models.Users().Count(th.Ctx, th.BackendAppDb())
+	require.NoError(th.T, err)
+	return count
+}
+
+func TestWithTx(t *testing.T) {
+	t.Run("success-commits", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		err := txn.WithTx(testSuite.Ctx, testSuite.BackendAppDb(), func(tx boil.ContextExecutor) error {
+			factory.User(testSuite.T, tx, nil)
+			return nil
+		})
+
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, int64(1), countUsers(testSuite))
+	})
+
+	t.Run("error-rolls-back", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		errBoom := errors.New("boom")
+		err := txn.WithTx(testSuite.Ctx, testSuite.BackendAppDb(), func(tx boil.ContextExecutor) error {
+			factory.User(testSuite.T, tx, nil)
+			return errBoom
+		})
+
+		require.ErrorIs(testSuite.T, err, errBoom)
+		assert.Equal(testSuite.T, int64(0), countUsers(testSuite))
+	})
+
+	t.Run("panic-rolls-back-and-repanics", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		assert.PanicsWithValue(testSuite.T, "boom", func() {
+			_ = txn.WithTx(testSuite.Ctx, testSuite.BackendAppDb(), func(tx boil.ContextExecutor) error {
+				factory.User(testSuite.T, tx, nil)
+				panic("boom")
+			})
+		})
+		assert.Equal(testSuite.T, int64(0), countUsers(testSuite))
+	})
+
+	t.Run("nested-error-rolls-back-only-the-savepoint", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		errInner := errors.New("inner")
+		err := txn.WithTx(testSuite.Ctx, testSuite.BackendAppDb(), func(tx boil.ContextExecutor) error {
+			factory.User(testSuite.T, tx, nil)
+
+			innerErr := txn.WithTx(testSuite.Ctx, tx, func(tx boil.ContextExecutor) error {
+				factory.User(testSuite.T, tx, nil)
+				return errInner
+			})
+			assert.ErrorIs(testSuite.T, innerErr, errInner)
+
+			return nil
+		})
+
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, int64(1), countUsers(testSuite))
+	})
+
+	t.Run("retries-deadlocks", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		attempts := 0
+		err := txn.WithTx(testSuite.Ctx, testSuite.BackendAppDb(), func(tx boil.ContextExecutor) error {
+			attempts++
+			factory.User(testSuite.T, tx, nil)
+			if attempts < txn.MaxAttempts {
+				return &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+			}
+			return nil
+		})
+
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, txn.MaxAttempts, attempts)
+		assert.Equal(testSuite.T, int64(1), countUsers(testSuite))
+	})
+}
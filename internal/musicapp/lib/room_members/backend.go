@@ -0,0 +1,45 @@
+package room_members
+
+import (
+	"context"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/lib/users"
+	"mlm/models"
+)
+
+// Store is the read side of a membership backend: room_members/store on
+// MySQL, or memdb.RoomMemberStore in memory; see users.Store
+type Store interface {
+	RoomMembers(ctx context.Context, exec boil.ContextExecutor, filter RoomMemberQueryFilter) ([]*RoomMembers, error)
+	RoomMembersPage(ctx context.Context, exec boil.ContextExecutor, filter RoomMemberQueryFilter) (*RoomMemberPage, error)
+	CountRoomMembers(ctx context.Context, exec boil.ContextExecutor, filter RoomMemberQueryFilter) (int64, error)
+	RoomMembersExist(ctx context.Context, exec boil.ContextExecutor, filter RoomMemberQueryFilter) (bool, error)
+	RoomMember(ctx context.Context, exec boil.ContextExecutor, filter RoomMemberQueryFilter) (*RoomMembers, error)
+	Update(ctx context.Context, exec boil.ContextExecutor, update UpdateRoomMember) error
+}
+
+// Repo is the write side of a membership backend: db/repo.RoomMemberRepo on
+// MySQL, or memdb.RoomMemberRepo in memory; see users.Repo
+type Repo interface {
+	Insert(ctx context.Context, exec boil.ContextExecutor, roomMember *models.RoomMember) (*models.RoomMember, error)
+	BulkInsert(ctx context.Context, exec boil.ContextExecutor, roomMembers []*models.RoomMember) error
+	Update(ctx context.Context, exec boil.ContextExecutor, roomMember *models.RoomMember, columns boil.Columns) (*models.RoomMember, error)
+}
+
+// RoomStore locks the room being joined: rooms/store on MySQL, or
+// memdb.RoomStore in memory. The rooms package depends on this one, so the
+// room comes back as its model.
+type RoomStore interface {
+	LockRoom(ctx context.Context, exec boil.ContextExecutor, id string) (*models.Room, error)
+}
+
+// UserStore looks up the user joining a room; see rooms.UserStore
+type UserStore interface {
+	Users(ctx context.Context, exec boil.ContextExecutor, filter users.UserQueryFilter) ([]*users.User, error)
+}
+
+// ExportSection is the personal data archive section room_members/store
+// registers with exportlib for a user's membership history
+const ExportSection = "memberships"
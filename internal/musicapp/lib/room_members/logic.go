@@ -0,0 +1,305 @@
+package room_members
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
+	"mlm/models"
+)
+
+var (
+	// ErrAlreadyMember is returned when joining a room the user is already
+	// active in; it is an errlib.ErrConflict
+	ErrAlreadyMember = errlib.New(errlib.ErrConflict, "user is already a member of this room")
+	// ErrNotMember is returned when leaving a room the user is not active in;
+	// it is an errlib.ErrNotFound
+	ErrNotMember = errlib.New(errlib.ErrNotFound, "user is not a member of this room")
+	// ErrRoomInactive is returned when joining a deactivated room; it is an
+	// errlib.ErrConflict
+	ErrRoomInactive = errlib.New(errlib.ErrConflict, "room is not active")
+)
+
+// Logic implements the join/leave membership workflow. Each write runs in
+// its own transaction, or a savepoint when exec is already one.
+type Logic struct {
+	store     Store
+	repo      Repo
+	roomStore RoomStore
+	userStore UserStore
+	tx        txn.Transactor
+}
+
+// NewLogic creates the membership logic, failing fast on missing dependencies
+func NewLogic(store Store, repo Repo, roomStore RoomStore, userStore UserStore, tx txn.Transactor) (*Logic, error) {
+	if store == nil {
+		return nil, fmt.Errorf("room members logic: store is required")
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("room members logic: repo is required")
+	}
+	if roomStore == nil {
+		return nil, fmt.Errorf("room members logic: room store is required")
+	}
+	if userStore == nil {
+		return nil, fmt.Errorf("room members logic: user store is required")
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("room members logic: transactor is required")
+	}
+
+	return &Logic{
+		store:     store,
+		repo:      repo,
+		roomStore: roomStore,
+		userStore: userStore,
+		tx:        tx,
+	}, nil
+}
+
+// ActiveMember returns the user's active membership of the room, or
+// ErrNotMember if they are not currently in it
+func (l *Logic) ActiveMember(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomID string,
+	userID string,
+) (*RoomMembers, error) {
+	members, err := l.store.RoomMembers(ctx, exec, RoomMemberQueryFilter{
+		RoomID: null.StringFrom(roomID),
+		UserID: null.StringFrom(userID),
+		Active: null.BoolFrom(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, ErrNotMember
+	}
+	if len(members) > 1 {
+		return nil, errlib.MultipleResults("active membership", len(members))
+	}
+
+	return members[0], nil
+}
+
+// Join adds the user to the room, or returns ErrAlreadyMember when they are
+// already in it. The check gives the common case a clean error; a concurrent
+// join that passes it too is rejected by uniq_active_membership, and that
+// conflict is reported as ErrAlreadyMember as well.
+//
+// The room must exist and be active, and stays locked until the join
+// commits so a concurrent deactivation can't miss the new member. The user
+// must be an existing, non-deleted user.
+func (l *Logic) Join(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomID string,
+	userID string,
+) (*RoomMembers, error) {
+	roomKey, err := dbid.Parse(roomID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid room ID %s: %w", roomID, err)
+	}
+	userKey, err := dbid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %s: %w", userID, err)
+	}
+
+	var member *RoomMembers
+	err = l.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		room, err := l.roomStore.LockRoom(ctx, tx, roomKey)
+		if err != nil {
+			return err
+		}
+		if !room.IsActive.Bool {
+			return ErrRoomInactive
+		}
+		if err := l.checkUser(ctx, tx, userKey); err != nil {
+			return err
+		}
+
+		_, err = l.ActiveMember(ctx, tx, roomKey, userKey)
+		if err == nil {
+			return ErrAlreadyMember
+		}
+		if !errors.Is(err, ErrNotMember) {
+			return err
+		}
+
+		_, err = l.repo.Insert(ctx, tx, &models.RoomMember{
+			RoomID:   roomKey,
+			UserID:   userKey,
+			JoinedAt: null.TimeFrom(time.Now()),
+		})
+		if errors.Is(err, errlib.ErrConflict) {
+			return ErrAlreadyMember
+		}
+		if err != nil {
+			return err
+		}
+
+		member, err = l.ActiveMember(ctx, tx, roomKey, userKey)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// Leave ends the user's active membership of the room. The row is kept with
+// left_at set so membership history is preserved.
+func (l *Logic) Leave(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomID string,
+	userID string,
+) error {
+	return l.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		member, err := l.ActiveMember(ctx, tx, roomID, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = l.repo.Update(ctx, tx, &models.RoomMember{
+			ID:     member.ID,
+			LeftAt: null.TimeFrom(time.Now()),
+		}, boil.Whitelist("left_at"))
+		return err
+	})
+}
+
+// Members returns one page of the room's current members, longest-standing
+// first. Pass the previous page's NextCursor as after to continue.
+func (l *Logic) Members(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomID string,
+	limit null.Int,
+	after null.String,
+) (*RoomMemberPage, error) {
+	return l.store.RoomMembersPage(ctx, exec, RoomMemberQueryFilter{
+		RoomID: null.StringFrom(roomID),
+		Active: null.BoolFrom(true),
+		Sorts:  []RoomMemberSort{{Field: RoomMemberSortJoinedAt}},
+		Limit:  limit,
+		After:  after,
+	})
+}
+
+// CountMembers returns how many members the room has now, or with
+// includeFormer how many memberships it has had in total
+func (l *Logic) CountMembers(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomID string,
+	includeFormer bool,
+) (int64, error) {
+	filter := RoomMemberQueryFilter{RoomID: null.StringFrom(roomID)}
+	if !includeFormer {
+		filter.Active = null.BoolFrom(true)
+	}
+	return l.store.CountRoomMembers(ctx, exec, filter)
+}
+
+// ActiveMemberships returns the rooms the user is currently in, as their
+// active memberships, longest-standing first
+func (l *Logic) ActiveMemberships(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	userID string,
+) ([]*RoomMembers, error) {
+	return l.store.RoomMembers(ctx, exec, RoomMemberQueryFilter{
+		UserID: null.StringFrom(userID),
+		Active: null.BoolFrom(true),
+		Sorts:  []RoomMemberSort{{Field: RoomMemberSortJoinedAt}},
+	})
+}
+
+// Timeline returns one page of the room's membership history, current and
+// former members alike, in the order they joined. Pass the previous page's
+// NextCursor as after to continue.
+func (l *Logic) Timeline(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomID string,
+	limit null.Int,
+	after null.String,
+) (*RoomMemberPage, error) {
+	return l.store.RoomMembersPage(ctx, exec, RoomMemberQueryFilter{
+		RoomID: null.StringFrom(roomID),
+		Sorts:  []RoomMemberSort{{Field: RoomMemberSortJoinedAt}},
+		Limit:  limit,
+		After:  after,
+	})
+}
+
+// CloseAll ends every active membership of the room in one update, e.g. when
+// the room is deactivated, and returns how many were closed. Rows are kept so
+// the room's history survives.
+func (l *Logic) CloseAll(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	roomID string,
+) (int, error) {
+	var closed int
+	err := l.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		active, err := l.store.RoomMembers(ctx, tx, RoomMemberQueryFilter{
+			RoomID: null.StringFrom(roomID),
+			Active: null.BoolFrom(true),
+		})
+		if err != nil {
+			return err
+		}
+		if len(active) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(active))
+		for i, member := range active {
+			ids[i] = member.ID
+		}
+
+		err = l.store.Update(ctx, tx, UpdateRoomMember{
+			IDs:    ids,
+			LeftAt: null.TimeFrom(time.Now()),
+		})
+		if err != nil {
+			return err
+		}
+
+		closed = len(active)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return closed, nil
+}
+
+// checkUser returns an errlib.ErrForeignKey unless userID is an existing,
+// non-deleted user
+func (l *Logic) checkUser(ctx context.Context, exec boil.ContextExecutor, userID string) error {
+	found, err := l.userStore.Users(ctx, exec, users.UserQueryFilter{
+		IDs: []string{userID},
+	})
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return errlib.New(errlib.ErrForeignKey, "user_id: user %s does not exist", userID)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package room_members_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/util/errlib"
+)
+
+// newLogic returns logic over a fresh in-memory database
+func newLogic(t *testing.T) (*room_members.Logic, *memdb.DB) {
+	db := memdb.New()
+	logic, err := room_members.NewLogic(memdb.NewRoomMemberStore(db), memdb.NewRoomMemberRepo(db), memdb.NewRoomStore(db), memdb.NewUserStore(db), db)
+	require.NoError(t, err)
+	return logic, db
+}
+
+// staleStore finds no memberships on its first read, like a check that ran
+// before a concurrent join committed
+type staleStore struct {
+	room_members.Store
+	read bool
+}
+
+func (s *staleStore) RoomMembers(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) ([]*room_members.RoomMembers, error) {
+	if !s.read {
+		s.read = true
+		return nil, nil
+	}
+	return s.Store.RoomMembers(ctx, exec, filter)
+}
+
+func TestLogic_ActiveMemberships(t *testing.T) {
+	ctx := context.Background()
+	logic, db := newLogic(t)
+
+	user := factory.MemUser(t, db, nil)
+	current := factory.MemRoomMember(t, db, &factory.RoomMemberMods{
+		UserID: &user.ID,
+	})
+	factory.MemRoomMember(t, db, &factory.RoomMemberMods{
+		UserID: &user.ID,
+		LeftAt: null.TimeFrom(time.Now()),
+	})
+
+	result, err := logic.ActiveMemberships(ctx, nil, user.ID)
+
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, current.RoomID, result[0].RoomID)
+}
+
+func TestLogic_Timeline(t *testing.T) {
+	ctx := context.Background()
+	logic, db := newLogic(t)
+
+	room := factory.MemRoom(t, db, nil)
+	first := factory.MemRoomMember(t, db, &factory.RoomMemberMods{
+		RoomID:   &room.ID,
+		JoinedAt: null.TimeFrom(time.Now().Add(-3 * time.Hour)),
+		LeftAt:   null.TimeFrom(time.Now().Add(-2 * time.Hour)),
+	})
+	second := factory.MemRoomMember(t, db, &factory.RoomMemberMods{
+		RoomID:   &room.ID,
+		JoinedAt: null.TimeFrom(time.Now().Add(-1 * time.Hour)),
+	})
+
+	page, err := logic.Timeline(ctx, nil, room.ID, null.IntFrom(1), null.String{})
+	require.NoError(t, err)
+	require.Len(t, page.RoomMembers, 1)
+	assert.Equal(t, first.ID, page.RoomMembers[0].ID)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = logic.Timeline(ctx, nil, room.ID, null.IntFrom(1), null.StringFrom(page.NextCursor))
+	require.NoError(t, err)
+	require.Len(t, page.RoomMembers, 1)
+	assert.Equal(t, second.ID, page.RoomMembers[0].ID)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestLogic_CloseAll(t *testing.T) {
+	t.Run("success-closes-only-active-members-of-room", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		room := factory.MemRoom(t, db, nil)
+		factory.MemRoomMember(t, db, &factory.RoomMemberMods{RoomID: &room.ID})
+		factory.MemRoomMember(t, db, &factory.RoomMemberMods{RoomID: &room.ID})
+		factory.MemRoomMember(t, db, &factory.RoomMemberMods{
+			RoomID: &room.ID,
+			LeftAt: null.TimeFrom(time.Now().Add(-time.Hour)),
+		})
+		other := factory.MemRoomMember(t, db, nil)
+
+		closed, err := logic.CloseAll(ctx, nil, room.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, closed)
+
+		active, err := memdb.NewRoomMemberStore(db).CountRoomMembers(ctx, nil, room_members.RoomMemberQueryFilter{
+			Active: null.BoolFrom(true),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), active) // only the other room's member
+
+		stillIn, err := memdb.NewRoomMemberStore(db).RoomMember(ctx, nil, room_members.RoomMemberQueryFilter{
+			IDs: []string{other.ID},
+		})
+		require.NoError(t, err)
+		assert.True(t, stillIn.IsActive())
+	})
+
+	t.Run("success-empty-room", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		room := factory.MemRoom(t, db, nil)
+
+		closed, err := logic.CloseAll(ctx, nil, room.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, closed)
+	})
+}
+
+func TestNewLogic(t *testing.T) {
+	db := memdb.New()
+	_, err := room_members.NewLogic(memdb.NewRoomMemberStore(db), memdb.NewRoomMemberRepo(db), memdb.NewRoomStore(db), memdb.NewUserStore(db), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transactor is required")
+}
+
+func TestLogic_Join(t *testing.T) {
+	t.Run("success-joins-room", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		room := factory.MemRoom(t, db, nil)
+		user := factory.MemUser(t, db, nil)
+
+		member, err := logic.Join(ctx, nil, room.ID, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, room.ID, member.RoomID)
+		assert.Equal(t, user.ID, member.UserID)
+		assert.True(t, member.IsActive())
+	})
+
+	t.Run("error-already-member", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		member := factory.MemRoomMember(t, db, nil)
+
+		_, err := logic.Join(ctx, nil, member.RoomID, member.UserID)
+		assert.ErrorIs(t, err, room_members.ErrAlreadyMember)
+		assert.ErrorIs(t, err, errlib.ErrConflict)
+	})
+
+	t.Run("error-concurrent-join", func(t *testing.T) {
+		ctx := context.Background()
+		db := memdb.New()
+		// The other join commits after this one's check, so the check sees
+		// no membership and the unique key has to catch it
+		store := &staleStore{Store: memdb.NewRoomMemberStore(db)}
+		logic, err := room_members.NewLogic(store, memdb.NewRoomMemberRepo(db), memdb.NewRoomStore(db), memdb.NewUserStore(db), db)
+		require.NoError(t, err)
+
+		member := factory.MemRoomMember(t, db, nil)
+
+		_, err = logic.Join(ctx, nil, member.RoomID, member.UserID)
+		assert.ErrorIs(t, err, room_members.ErrAlreadyMember)
+
+		count, err := memdb.NewRoomMemberStore(db).CountRoomMembers(ctx, nil, room_members.RoomMemberQueryFilter{
+			Active: null.BoolFrom(true),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("error-room-or-user-cannot-join", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		room := factory.MemRoom(t, db, nil)
+		inactive := factory.MemRoom(t, db, &factory.RoomMods{IsActive: null.BoolFrom(false)})
+		user := factory.MemUser(t, db, nil)
+		deleted := factory.MemUser(t, db, &factory.UserMods{DeletedAt: null.TimeFrom(time.Now())})
+
+		joins := []struct {
+			name     string
+			roomID   string
+			userID   string
+			expected error
+		}{
+			{name: "unknown-room", roomID: "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11", userID: user.ID, expected: errlib.ErrNotFound},
+			{name: "inactive-room", roomID: inactive.ID, userID: user.ID, expected: room_members.ErrRoomInactive},
+			{name: "unknown-user", roomID: room.ID, userID: "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11", expected: errlib.ErrForeignKey},
+			{name: "deleted-user", roomID: room.ID, userID: deleted.ID, expected: errlib.ErrForeignKey},
+		}
+
+		for _, join := range joins {
+			t.Run(join.name, func(t *testing.T) {
+				_, err := logic.Join(ctx, nil, join.roomID, join.userID)
+				assert.ErrorIs(t, err, join.expected)
+			})
+		}
+
+		// Nothing was joined
+		count, err := memdb.NewRoomMemberStore(db).CountRoomMembers(ctx, nil, room_members.RoomMemberQueryFilter{
+			UserID: null.StringFrom(user.ID),
+		})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+		count, err = memdb.NewRoomMemberStore(db).CountRoomMembers(ctx, nil, room_members.RoomMemberQueryFilter{
+			UserID: null.StringFrom(deleted.ID),
+		})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("error-invalid-user-id", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		room := factory.MemRoom(t, db, nil)
+
+		_, err := logic.Join(ctx, nil, room.ID, "42")
+		assert.ErrorIs(t, err, errlib.ErrInvalidID)
+	})
+}
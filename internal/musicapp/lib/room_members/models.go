@@ -4,26 +4,73 @@ import (
 	"time"
 
 	"github.com/aarondl/null/v8"
+
+	"mlm/internal/util/querylib"
 )
 
+// RoomMembers - a user's membership of a room. LeftAt is invalid (NULL)
+// while the membership is active.
 type RoomMembers struct {
 	ID       string
 	RoomID   string
 	UserID   string
 	JoinedAt time.Time
-	LeftAt   time.Time
+	LeftAt   null.Time
+}
+
+// IsActive reports whether the member is still in the room
+func (m *RoomMembers) IsActive() bool {
+	return !m.LeftAt.Valid
 }
 
+// RoomMemberSortField - whitelisted columns memberships can be sorted by
+type RoomMemberSortField string
+
+const (
+	RoomMemberSortID       RoomMemberSortField = "id"
+	RoomMemberSortJoinedAt RoomMemberSortField = "joined_at"
+)
+
+// RoomMemberSort - one sort term, e.g. {RoomMemberSortJoinedAt, querylib.SortDesc}
+type RoomMemberSort = querylib.Sort[RoomMemberSortField]
+
 type RoomMemberQueryFilter struct {
 	IDs      []string
 	RoomID   null.String
 	UserID   null.String
 	JoinedAt null.Time
 	LeftAt   null.Time
+
+	// Joined in [JoinedAfter, JoinedBefore), left in [LeftAfter, LeftBefore).
+	// A left range only matches former members.
+	JoinedAfter  null.Time
+	JoinedBefore null.Time
+	LeftAfter    null.Time
+	LeftBefore   null.Time
+
+	// Active filters on membership state: true = left_at IS NULL,
+	// false = left_at IS NOT NULL
+	Active null.Bool
+
+	// Sorting - applied in order, with id as the final tiebreak
+	Sorts  []RoomMemberSort
+	Limit  null.Int
+	Offset null.Int
+
+	// After is an opaque cursor from RoomMemberPage.NextCursor; it can't be
+	// combined with Offset
+	After null.String
 }
 
+// RoomMemberPage - one page of memberships. NextCursor is empty on the last page.
+type RoomMemberPage struct {
+	RoomMembers []*RoomMembers
+	NextCursor  string
+}
+
+// UpdateRoomMember - nullable fields for partial updates
 type UpdateRoomMember struct {
-	IDs      []string
+	IDs      []string // Which memberships to update
 	RoomID   null.String
 	UserID   null.String
 	JoinedAt null.Time
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/util/exportlib"
+	"mlm/internal/util/querylib"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// membershipExport is one stay in a room, in a personal data archive
+type membershipExport struct {
+	RoomID   string    `json:"room_id"`
+	JoinedAt time.Time `json:"joined_at"`
+	LeftAt   null.Time `json:"left_at"` // null while still a member
+}
+
+func init() {
+	exportlib.Register(room_members.ExportSection, exportMemberships)
+}
+
+// exportMemberships exports the user's full membership history, oldest first
+func exportMemberships(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error) {
+	result, err := New().RoomMembers(ctx, exec, room_members.RoomMemberQueryFilter{
+		UserID: null.StringFrom(userID),
+		Sorts: []room_members.RoomMemberSort{
+			{Field: room_members.RoomMemberSortJoinedAt, Direction: querylib.SortAsc},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]membershipExport, len(result))
+	for i, m := range result {
+		history[i] = membershipExport{
+			RoomID:   m.RoomID,
+			JoinedAt: m.JoinedAt,
+			LeftAt:   m.LeftAt,
+		}
+	}
+	return history, nil
+}
@@ -3,7 +3,11 @@ package store
 import (
 	"context"
 	"fmt"
+	"mlm/internal/musicapp/db/dbid"
 	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/internal/util/validationlib"
 	"mlm/models"
 	"strconv"
 
@@ -14,6 +18,11 @@ import (
 
 type Store struct{}
 
+var roomMemberSortColumns = map[room_members.RoomMemberSortField]string{
+	room_members.RoomMemberSortID:       models.RoomMemberColumns.ID,
+	room_members.RoomMemberSortJoinedAt: models.RoomMemberColumns.JoinedAt,
+}
+
 func New() *Store {
 	return &Store{}
 }
@@ -23,26 +32,235 @@ func (s *Store) RoomMembers(
 	exec boil.ContextExecutor,
 	filter room_members.RoomMemberQueryFilter,
 ) ([]*room_members.RoomMembers, error) {
+	mods, _, err := roomMemberQueryMods(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRoomMembers, err := models.RoomMembers(mods...).All(ctx, exec)
+	if err != nil {
+		return nil, fmt.Errorf("query room members: %w", err)
+	}
+
+	return dbRoomMembersToRoomMembers(dbRoomMembers), nil
+}
+
+// RoomMembersPage returns one page of memberships and a cursor for the next
+// page; see users/store.Store.UsersPage
+func (s *Store) RoomMembersPage(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (*room_members.RoomMemberPage, error) {
+	pageSize := querylib.PageSize(filter.Limit)
+	filter.Limit = null.IntFrom(pageSize + 1)
+
+	mods, keyset, err := roomMemberQueryMods(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRoomMembers, err := models.RoomMembers(mods...).All(ctx, exec)
+	if err != nil {
+		return nil, fmt.Errorf("query room members: %w", err)
+	}
+
+	page := &room_members.RoomMemberPage{}
+	if len(dbRoomMembers) > pageSize {
+		dbRoomMembers = dbRoomMembers[:pageSize]
+
+		values, err := roomMemberCursorValues(dbRoomMembers[pageSize-1], keyset.Columns())
+		if err != nil {
+			return nil, err
+		}
+		if page.NextCursor, err = keyset.Cursor(values...); err != nil {
+			return nil, err
+		}
+	}
+	page.RoomMembers = dbRoomMembersToRoomMembers(dbRoomMembers)
+
+	return page, nil
+}
+
+// CountRoomMembers returns how many room members match the filter. Sorts and
+// pagination fields are ignored.
+func (s *Store) CountRoomMembers(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (int64, error) {
+	mods, err := roomMemberFilterMods(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := models.RoomMembers(mods...).Count(ctx, exec)
+	if err != nil {
+		return 0, fmt.Errorf("count room members: %w", err)
+	}
+
+	return count, nil
+}
+
+// RoomMembersExist reports whether any room members match the filter, without fetching rows
+func (s *Store) RoomMembersExist(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (bool, error) {
+	mods, err := roomMemberFilterMods(filter)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := models.RoomMembers(mods...).Exists(ctx, exec)
+	if err != nil {
+		return false, fmt.Errorf("check room members exist: %w", err)
+	}
+
+	return exists, nil
+}
+
+// RoomMember returns exactly 1 room member, errors if 0 or >1 found
+func (s *Store) RoomMember(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter room_members.RoomMemberQueryFilter,
+) (*room_members.RoomMembers, error) {
+	results, err := s.RoomMembers(ctx, exec, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, errlib.NotFound("room member")
+	}
+	if len(results) > 1 {
+		return nil, errlib.MultipleResults("room member", len(results))
+	}
+
+	return results[0], nil
+}
+
+// Update performs generic update with nullable fields
+func (s *Store) Update(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	update room_members.UpdateRoomMember,
+) error {
+	if len(update.IDs) == 0 {
+		return fmt.Errorf("no room member IDs provided")
+	}
+
+	cols := make(map[string]interface{})
+
+	if update.RoomID.Valid {
+		roomID, err := dbid.Parse(update.RoomID.String)
+		if err != nil {
+			return fmt.Errorf("invalid room ID %s: %w", update.RoomID.String, err)
+		}
+		cols["room_id"] = roomID
+	}
+	if update.UserID.Valid {
+		userID, err := dbid.Parse(update.UserID.String)
+		if err != nil {
+			return fmt.Errorf("invalid user ID %s: %w", update.UserID.String, err)
+		}
+		cols["user_id"] = userID
+	}
+	if update.JoinedAt.Valid {
+		cols["joined_at"] = update.JoinedAt.Time
+	}
+	if update.LeftAt.Valid {
+		cols["left_at"] = update.LeftAt.Time
+	}
+
+	if len(cols) == 0 {
+		return nil // Nothing to update
+	}
+
+	ids := make([]interface{}, len(update.IDs))
+	for i, id := range update.IDs {
+		parsed, err := dbid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("invalid room member ID %s: %w", id, err)
+		}
+		ids[i] = parsed
+	}
+
+	// Execute update
+	_, err := models.RoomMembers(
+		qm.WhereIn("id IN ?", ids...),
+	).UpdateAll(ctx, exec, cols)
+
+	return errlib.FromDB(err)
+}
+
+func roomMemberQueryMods(filter room_members.RoomMemberQueryFilter) ([]qm.QueryMod, *querylib.Keyset, error) {
+	mods, err := roomMemberFilterMods(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyset, err := querylib.NewKeyset(filter.Sorts, roomMemberSortColumns, models.RoomMemberColumns.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	mods = append(mods, keyset.OrderBy())
+
+	if filter.After.Valid {
+		if filter.Offset.Valid {
+			return nil, nil, validationlib.NewValidationError("offset", strconv.Itoa(filter.Offset.Int),
+				"can't be combined with a cursor")
+		}
+		after, err := keyset.After(filter.After.String)
+		if err != nil {
+			return nil, nil, err
+		}
+		mods = append(mods, after)
+	}
+
+	if filter.Limit.Valid {
+		mods = append(mods, qm.Limit(filter.Limit.Int))
+	}
+	if filter.Offset.Valid {
+		mods = append(mods, qm.Offset(filter.Offset.Int))
+	}
+
+	return mods, keyset, nil
+}
+
+// roomMemberFilterMods translates the filter's conditions into WHERE mods. It is
+// shared by list, page, count and exists queries.
+func roomMemberFilterMods(filter room_members.RoomMemberQueryFilter) ([]qm.QueryMod, error) {
 	mods := []qm.QueryMod{}
 
 	if len(filter.IDs) > 0 {
 		ids := make([]interface{}, len(filter.IDs))
 		for i, id := range filter.IDs {
-			idNum, err := strconv.ParseUint(id, 10, 64)
+			parsed, err := dbid.Parse(id)
 			if err != nil {
 				return nil, fmt.Errorf("invalid room member ID %s: %w", id, err)
 			}
-			ids[i] = idNum
+			ids[i] = parsed
 		}
-		mods = append(mods, qm.WhereIn("id IN (?)", ids))
+		mods = append(mods, qm.WhereIn("id IN ?", ids...))
 	}
 
 	if filter.RoomID.Valid {
-		mods = append(mods, qm.Where("room_id = ?", filter.RoomID.String))
+		roomID, err := dbid.Parse(filter.RoomID.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid room ID %s: %w", filter.RoomID.String, err)
+		}
+		mods = append(mods, qm.Where("room_id = ?", roomID))
 	}
 
 	if filter.UserID.Valid {
-		mods = append(mods, qm.Where("user_id = ?", filter.UserID.String))
+		userID, err := dbid.Parse(filter.UserID.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %s: %w", filter.UserID.String, err)
+		}
+		mods = append(mods, qm.Where("user_id = ?", userID))
 	}
 
 	if filter.JoinedAt.Valid {
@@ -53,12 +271,33 @@ func (s *Store) RoomMembers(
 		mods = append(mods, qm.WhereIn("left_at = ?", filter.LeftAt.Time))
 	}
 
-	dbRoomMembers, err := models.RoomMembers(mods...).All(ctx, exec)
-	if err != nil {
-		return nil, fmt.Errorf("query room members: %w", err)
+	mods = append(mods, querylib.WhereTimeRange("joined_at", filter.JoinedAfter, filter.JoinedBefore)...)
+	mods = append(mods, querylib.WhereTimeRange("left_at", filter.LeftAfter, filter.LeftBefore)...)
+
+	if filter.Active.Valid {
+		if filter.Active.Bool {
+			mods = append(mods, qm.Where("left_at IS NULL"))
+		} else {
+			mods = append(mods, qm.Where("left_at IS NOT NULL"))
+		}
 	}
 
-	return dbRoomMembersToRoomMembers(dbRoomMembers), nil
+	return mods, nil
+}
+
+func roomMemberCursorValues(db *models.RoomMember, columns []string) ([]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch column {
+		case models.RoomMemberColumns.ID:
+			values[i] = db.ID
+		case models.RoomMemberColumns.JoinedAt:
+			values[i] = db.JoinedAt
+		default:
+			return nil, fmt.Errorf("no cursor value for room member column %s", column)
+		}
+	}
+	return values, nil
 }
 
 func dbRoomMembersToRoomMembers(dbRoomMembers []*models.RoomMember) []*room_members.RoomMembers {
@@ -70,11 +309,11 @@ func dbRoomMembersToRoomMembers(dbRoomMembers []*models.RoomMember) []*room_memb
 		}
 
 		result[i] = &room_members.RoomMembers{
-			ID:       fmt.Sprintf("%d", db.ID),
-			RoomID:   fmt.Sprintf("%d", db.RoomID),
-			UserID:   fmt.Sprintf("%d", db.UserID),
+			ID:       db.ID,
+			RoomID:   db.RoomID,
+			UserID:   db.UserID,
 			JoinedAt: joinedAt.Time,
-			LeftAt:   db.LeftAt.Time,
+			LeftAt:   db.LeftAt,
 		}
 	}
 	return result
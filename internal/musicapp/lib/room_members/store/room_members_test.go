@@ -0,0 +1,298 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/room_members/store"
+	"mlm/internal/testsuite"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/validationlib"
+)
+
+// Test case struct for RoomMembers()
+type testCaseRoomMembers struct {
+	name            string
+	setup           func(th *testsuite.Helper) room_members.RoomMemberQueryFilter
+	extraAssertions func(th *testsuite.Helper, result []*room_members.RoomMembers, err error)
+}
+
+// Test cases for RoomMembers() method
+func roomMembersTestCases() []testCaseRoomMembers {
+	return []testCaseRoomMembers{
+		{
+			name: "success-returns-all-members",
+			setup: func(th *testsuite.Helper) room_members.RoomMemberQueryFilter {
+				factory.RoomMember(th.T, th.BackendAppDb(), nil)
+				factory.RoomMember(th.T, th.BackendAppDb(), nil)
+
+				return room_members.RoomMemberQueryFilter{}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*room_members.RoomMembers, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 2)
+			},
+		},
+		{
+			name: "success-filters-by-ids",
+			setup: func(th *testsuite.Helper) room_members.RoomMemberQueryFilter {
+				m1 := factory.RoomMember(th.T, th.BackendAppDb(), nil)
+				m2 := factory.RoomMember(th.T, th.BackendAppDb(), nil)
+				factory.RoomMember(th.T, th.BackendAppDb(), nil) // Extra member not in filter
+
+				return room_members.RoomMemberQueryFilter{
+					IDs: []string{
+						m1.ID,
+						m2.ID,
+					},
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*room_members.RoomMembers, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 2)
+			},
+		},
+		{
+			name: "success-filters-active-members",
+			setup: func(th *testsuite.Helper) room_members.RoomMemberQueryFilter {
+				room := factory.Room(th.T, th.BackendAppDb(), nil)
+				factory.RoomMember(th.T, th.BackendAppDb(), &factory.RoomMemberMods{
+					RoomID: &room.ID,
+				})
+				factory.RoomMember(th.T, th.BackendAppDb(), &factory.RoomMemberMods{
+					RoomID: &room.ID,
+					LeftAt: null.TimeFrom(time.Now()),
+				})
+
+				return room_members.RoomMemberQueryFilter{
+					RoomID: null.StringFrom(room.ID),
+					Active: null.BoolFrom(true),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*room_members.RoomMembers, err error) {
+				require.NoError(th.T, err)
+				require.Len(th.T, result, 1)
+				assert.True(th.T, result[0].IsActive())
+			},
+		},
+		{
+			name: "success-filters-former-members",
+			setup: func(th *testsuite.Helper) room_members.RoomMemberQueryFilter {
+				room := factory.Room(th.T, th.BackendAppDb(), nil)
+				factory.RoomMember(th.T, th.BackendAppDb(), &factory.RoomMemberMods{
+					RoomID: &room.ID,
+				})
+				factory.RoomMember(th.T, th.BackendAppDb(), &factory.RoomMemberMods{
+					RoomID: &room.ID,
+					LeftAt: null.TimeFrom(time.Now()),
+				})
+
+				return room_members.RoomMemberQueryFilter{
+					RoomID: null.StringFrom(room.ID),
+					Active: null.BoolFrom(false),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*room_members.RoomMembers, err error) {
+				require.NoError(th.T, err)
+				require.Len(th.T, result, 1)
+				assert.False(th.T, result[0].IsActive())
+				assert.True(th.T, result[0].LeftAt.Valid)
+			},
+		},
+		{
+			name: "success-pagination-with-offset",
+			setup: func(th *testsuite.Helper) room_members.RoomMemberQueryFilter {
+				room := factory.Room(th.T, th.BackendAppDb(), nil)
+				for i := 3; i > 0; i-- {
+					factory.RoomMember(th.T, th.BackendAppDb(), &factory.RoomMemberMods{
+						RoomID:   &room.ID,
+						JoinedAt: null.TimeFrom(time.Now().Add(-time.Duration(i) * time.Hour)),
+					})
+				}
+
+				return room_members.RoomMemberQueryFilter{
+					RoomID: null.StringFrom(room.ID),
+					Sorts:  []room_members.RoomMemberSort{{Field: room_members.RoomMemberSortJoinedAt}},
+					Limit:  null.IntFrom(2),
+					Offset: null.IntFrom(2),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*room_members.RoomMembers, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 1)
+			},
+		},
+		{
+			name: "error-offset-with-cursor",
+			setup: func(th *testsuite.Helper) room_members.RoomMemberQueryFilter {
+				return room_members.RoomMemberQueryFilter{
+					Offset: null.IntFrom(10),
+					After:  null.StringFrom("anything"),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*room_members.RoomMembers, err error) {
+				require.Error(th.T, err)
+				assert.True(th.T, validationlib.IsValidationError(err))
+			},
+		},
+	}
+}
+
+// TestStore_RoomMembers - main test function
+func TestStore_RoomMembers(t *testing.T) {
+
+	for _, tt := range roomMembersTestCases() {
+		t.Run(tt.name, func(t *testing.T) {
+
+			testSuite := testsuite.New(t)
+			t.Cleanup(testSuite.UseBackendDB())
+
+			store := store.New()
+			filter := tt.setup(testSuite)
+
+			result, err := store.RoomMembers(
+				testSuite.Ctx,
+				testSuite.BackendAppDb(),
+				filter,
+			)
+
+			if tt.extraAssertions != nil {
+				tt.extraAssertions(testSuite, result, err)
+			}
+		})
+	}
+}
+
+// TestStore_RoomMember - test RoomMember() method
+func TestStore_RoomMember(t *testing.T) {
+	t.Run("success-returns-single-member", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		member := factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), nil)
+
+		store := store.New()
+		result, err := store.RoomMember(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			room_members.RoomMemberQueryFilter{
+				IDs: []string{member.ID},
+			},
+		)
+
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, member.ID, result.ID)
+		assert.Equal(testSuite.T, member.RoomID, result.RoomID)
+	})
+
+	t.Run("error-no-member-found", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		store := store.New()
+		_, err := store.RoomMember(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			room_members.RoomMemberQueryFilter{
+				RoomID: null.StringFrom("0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11"),
+			},
+		)
+
+		require.Error(testSuite.T, err)
+		assert.Contains(testSuite.T, err.Error(), "no room member found")
+		assert.ErrorIs(testSuite.T, err, errlib.ErrNotFound)
+	})
+
+	t.Run("error-multiple-members-found", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		room := factory.Room(testSuite.T, testSuite.BackendAppDb(), nil)
+		factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMemberMods{RoomID: &room.ID})
+		factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), &factory.RoomMemberMods{RoomID: &room.ID})
+
+		store := store.New()
+		_, err := store.RoomMember(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			room_members.RoomMemberQueryFilter{
+				RoomID: null.StringFrom(room.ID),
+			},
+		)
+
+		require.Error(testSuite.T, err)
+		assert.Contains(testSuite.T, err.Error(), "expected 1 room member, got 2")
+		assert.ErrorIs(testSuite.T, err, errlib.ErrMultipleResults)
+	})
+}
+
+// TestStore_Update - test Update() method
+func TestStore_Update(t *testing.T) {
+	t.Run("success-closes-multiple-memberships", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		m1 := factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), nil)
+		m2 := factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), nil)
+		untouched := factory.RoomMember(testSuite.T, testSuite.BackendAppDb(), nil)
+
+		store := store.New()
+		err := store.Update(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			room_members.UpdateRoomMember{
+				IDs:    []string{m1.ID, m2.ID},
+				LeftAt: null.TimeFrom(time.Now()),
+			},
+		)
+		require.NoError(testSuite.T, err)
+
+		former, err := store.RoomMembers(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			room_members.RoomMemberQueryFilter{
+				Active: null.BoolFrom(false),
+			},
+		)
+		require.NoError(testSuite.T, err)
+		assert.Len(testSuite.T, former, 2)
+
+		still, err := store.RoomMember(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			room_members.RoomMemberQueryFilter{
+				IDs: []string{untouched.ID},
+			},
+		)
+		require.NoError(testSuite.T, err)
+		assert.True(testSuite.T, still.IsActive())
+	})
+
+	t.Run("error-no-ids-provided", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		store := store.New()
+		err := store.Update(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			room_members.UpdateRoomMember{
+				LeftAt: null.TimeFrom(time.Now()),
+			},
+		)
+
+		require.Error(testSuite.T, err)
+		assert.Contains(testSuite.T, err.Error(), "no room member IDs provided")
+	})
+}
@@ -0,0 +1,31 @@
+package rooms
+
+import (
+	"context"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/models"
+)
+
+// Store is the read side of a room backend: rooms/store on MySQL, or
+// memdb.RoomStore in memory; see users.Store
+type Store interface {
+	Rooms(ctx context.Context, exec boil.ContextExecutor, filter RoomQueryFilter) ([]*Room, error)
+	RoomsPage(ctx context.Context, exec boil.ContextExecutor, filter RoomQueryFilter) (*RoomPage, error)
+	CountRooms(ctx context.Context, exec boil.ContextExecutor, filter RoomQueryFilter) (int64, error)
+	RoomsExist(ctx context.Context, exec boil.ContextExecutor, filter RoomQueryFilter) (bool, error)
+	Room(ctx context.Context, exec boil.ContextExecutor, filter RoomQueryFilter) (*Room, error)
+	Update(ctx context.Context, exec boil.ContextExecutor, update UpdateRoom) error
+}
+
+// Repo is the write side of a room backend: db/repo.RoomRepo on MySQL, or
+// memdb.RoomRepo in memory; see users.Repo
+type Repo interface {
+	Insert(ctx context.Context, exec boil.ContextExecutor, room *models.Room) (*models.Room, error)
+	BulkInsert(ctx context.Context, exec boil.ContextExecutor, rooms []*models.Room) error
+}
+
+// ExportSection is the personal data archive section rooms/store registers
+// with exportlib for the rooms a user created
+const ExportSection = "rooms_created"
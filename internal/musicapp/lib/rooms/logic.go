@@ -0,0 +1,216 @@
+package rooms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
+	"mlm/models"
+)
+
+// UserStore looks up the users rooms are created by
+type UserStore interface {
+	Users(ctx context.Context, exec boil.ContextExecutor, filter users.UserQueryFilter) ([]*users.User, error)
+}
+
+// Memberships is the part of room_members.Logic used by Logic
+type Memberships interface {
+	Join(ctx context.Context, exec boil.ContextExecutor, roomID string, userID string) (*room_members.RoomMembers, error)
+	CloseAll(ctx context.Context, exec boil.ContextExecutor, roomID string) (int, error)
+}
+
+// Logic validates room input, checks creators exist and keeps memberships in
+// step with a room's lifecycle
+type Logic struct {
+	store       Store
+	repo        Repo
+	userStore   UserStore
+	memberships Memberships
+	tx          txn.Transactor
+}
+
+// NewLogic creates the room logic, failing fast on missing dependencies
+func NewLogic(store Store, repo Repo, userStore UserStore, memberships Memberships, tx txn.Transactor) (*Logic, error) {
+	if store == nil {
+		return nil, fmt.Errorf("rooms logic: store is required")
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("rooms logic: repo is required")
+	}
+	if userStore == nil {
+		return nil, fmt.Errorf("rooms logic: user store is required")
+	}
+	if memberships == nil {
+		return nil, fmt.Errorf("rooms logic: memberships are required")
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("rooms logic: transactor is required")
+	}
+
+	return &Logic{
+		store:       store,
+		repo:        repo,
+		userStore:   userStore,
+		memberships: memberships,
+		tx:          tx,
+	}, nil
+}
+
+// Get returns the room with the given ID, or an errlib.ErrNotFound when it
+// does not exist
+func (l *Logic) Get(ctx context.Context, exec boil.ContextExecutor, id string) (*Room, error) {
+	result, err := l.store.Rooms(ctx, exec, RoomQueryFilter{
+		IDs: []string{id},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, errlib.NotFound("room")
+	}
+
+	return result[0], nil
+}
+
+// Page returns one page of rooms matching filter
+func (l *Logic) Page(ctx context.Context, exec boil.ContextExecutor, filter RoomQueryFilter) (*RoomPage, error) {
+	return l.store.RoomsPage(ctx, exec, filter)
+}
+
+// Count returns how many rooms match filter across all pages
+func (l *Logic) Count(ctx context.Context, exec boil.ContextExecutor, filter RoomQueryFilter) (int64, error) {
+	return l.store.CountRooms(ctx, exec, filter)
+}
+
+// Create opens a room created by createdBy, who becomes its first member.
+// The creator must be an existing, non-deleted user.
+func (l *Logic) Create(ctx context.Context, exec boil.ContextExecutor, name string, createdBy string) (*Room, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	creatorID, err := dbid.Parse(createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid created_by %s: %w", createdBy, err)
+	}
+
+	var room *Room
+	err = l.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		if err := l.checkUser(ctx, tx, creatorID); err != nil {
+			return err
+		}
+
+		dbRoom, err := l.repo.Insert(ctx, tx, &models.Room{
+			Name:      name,
+			CreatedBy: creatorID,
+			IsActive:  null.BoolFrom(true),
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := l.memberships.Join(ctx, tx, dbRoom.ID, creatorID); err != nil {
+			return err
+		}
+
+		room, err = l.Get(ctx, tx, dbRoom.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// Update validates and applies a partial update to existing rooms.
+// Deactivating a room also ends all of its active memberships; the number
+// ended is returned.
+func (l *Logic) Update(ctx context.Context, exec boil.ContextExecutor, update UpdateRoom) (int, error) {
+	if len(update.IDs) == 0 {
+		return 0, fmt.Errorf("no room IDs provided")
+	}
+	if update.Name.Valid {
+		name, err := NormalizeName(update.Name.String)
+		if err != nil {
+			return 0, err
+		}
+		update.Name.String = name
+	}
+	if update.CreatedBy.Valid {
+		creatorID, err := dbid.Parse(update.CreatedBy.String)
+		if err != nil {
+			return 0, fmt.Errorf("invalid created_by %s: %w", update.CreatedBy.String, err)
+		}
+		update.CreatedBy.String = creatorID
+	}
+
+	var closed int
+	err := l.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		closed = 0
+
+		// Write first: the update waits for any join holding the room, and
+		// only then do the reads below start their snapshot, so CloseAll sees
+		// that member. Failed checks roll the write back.
+		if err := l.store.Update(ctx, tx, update); err != nil {
+			return err
+		}
+
+		found, err := l.store.Rooms(ctx, tx, RoomQueryFilter{IDs: update.IDs})
+		if err != nil {
+			return err
+		}
+		unique := make(map[string]bool, len(update.IDs))
+		for _, id := range update.IDs {
+			unique[strings.ToLower(id)] = true
+		}
+		if len(found) < len(unique) {
+			return errlib.NotFound("room")
+		}
+
+		if update.CreatedBy.Valid {
+			if err := l.checkUser(ctx, tx, update.CreatedBy.String); err != nil {
+				return err
+			}
+		}
+
+		if update.IsActive.Valid && !update.IsActive.Bool {
+			for _, room := range found {
+				n, err := l.memberships.CloseAll(ctx, tx, room.ID)
+				if err != nil {
+					return err
+				}
+				closed += n
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return closed, nil
+}
+
+// checkUser returns an errlib.ErrForeignKey unless userID is an existing,
+// non-deleted user
+func (l *Logic) checkUser(ctx context.Context, exec boil.ContextExecutor, userID string) error {
+	found, err := l.userStore.Users(ctx, exec, users.UserQueryFilter{
+		IDs: []string{userID},
+	})
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return errlib.New(errlib.ErrForeignKey, "created_by: user %s does not exist", userID)
+	}
+	return nil
+}
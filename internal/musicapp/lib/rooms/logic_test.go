@@ -0,0 +1,138 @@
+package rooms_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/internal/musicapp/lib/room_members"
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/validationlib"
+)
+
+// newLogic returns logic over a fresh in-memory database
+func newLogic(t *testing.T) (*rooms.Logic, *memdb.DB) {
+	db := memdb.New()
+	memberships, err := room_members.NewLogic(memdb.NewRoomMemberStore(db), memdb.NewRoomMemberRepo(db), memdb.NewRoomStore(db), memdb.NewUserStore(db), db)
+	require.NoError(t, err)
+
+	logic, err := rooms.NewLogic(memdb.NewRoomStore(db), memdb.NewRoomRepo(db), memdb.NewUserStore(db), memberships, db)
+	require.NoError(t, err)
+	return logic, db
+}
+
+func TestNewLogic(t *testing.T) {
+	db := memdb.New()
+	_, err := rooms.NewLogic(memdb.NewRoomStore(db), memdb.NewRoomRepo(db), memdb.NewUserStore(db), nil, db)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "memberships are required")
+}
+
+func TestLogic_Create(t *testing.T) {
+	t.Run("success-creator-is-first-member", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		creator := factory.MemUser(t, db, nil)
+
+		room, err := logic.Create(ctx, nil, "  Jazz Lounge ", creator.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Jazz Lounge", room.Name)
+		assert.Equal(t, creator.ID, room.CreatedBy)
+		assert.True(t, room.IsActive)
+
+		members, err := memdb.NewRoomMemberStore(db).RoomMembers(ctx, nil, room_members.RoomMemberQueryFilter{
+			RoomID: null.StringFrom(room.ID),
+		})
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		assert.Equal(t, creator.ID, members[0].UserID)
+	})
+
+	t.Run("error-blank-name", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		creator := factory.MemUser(t, db, nil)
+
+		_, err := logic.Create(ctx, nil, "   ", creator.ID)
+		assert.True(t, validationlib.IsValidationError(err))
+	})
+
+	t.Run("error-creator-deleted", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		creator := factory.MemUser(t, db, &factory.UserMods{
+			DeletedAt: null.TimeFrom(time.Now()),
+		})
+
+		_, err := logic.Create(ctx, nil, "Jazz Lounge", creator.ID)
+		assert.ErrorIs(t, err, errlib.ErrForeignKey)
+
+		count, err := memdb.NewRoomStore(db).CountRooms(ctx, nil, rooms.RoomQueryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("error-invalid-creator-id", func(t *testing.T) {
+		ctx := context.Background()
+		logic, _ := newLogic(t)
+
+		_, err := logic.Create(ctx, nil, "Jazz Lounge", "42")
+		assert.ErrorIs(t, err, errlib.ErrInvalidID)
+	})
+}
+
+func TestLogic_Update(t *testing.T) {
+	t.Run("success-deactivate-closes-memberships", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		room := factory.MemRoom(t, db, nil)
+		factory.MemRoomMember(t, db, &factory.RoomMemberMods{RoomID: &room.ID})
+		factory.MemRoomMember(t, db, &factory.RoomMemberMods{RoomID: &room.ID})
+
+		closed, err := logic.Update(ctx, nil, rooms.UpdateRoom{
+			IDs:      []string{room.ID},
+			IsActive: null.BoolFrom(false),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, closed)
+
+		updated, err := logic.Get(ctx, nil, room.ID)
+		require.NoError(t, err)
+		assert.False(t, updated.IsActive)
+	})
+
+	t.Run("error-unknown-creator", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		room := factory.MemRoom(t, db, nil)
+
+		_, err := logic.Update(ctx, nil, rooms.UpdateRoom{
+			IDs:       []string{room.ID},
+			CreatedBy: null.StringFrom("0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11"),
+		})
+		assert.ErrorIs(t, err, errlib.ErrForeignKey)
+	})
+
+	t.Run("error-room-not-found", func(t *testing.T) {
+		ctx := context.Background()
+		logic, _ := newLogic(t)
+
+		_, err := logic.Update(ctx, nil, rooms.UpdateRoom{
+			IDs:  []string{"0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11"},
+			Name: null.StringFrom("Renamed"),
+		})
+		assert.ErrorIs(t, err, errlib.ErrNotFound)
+	})
+}
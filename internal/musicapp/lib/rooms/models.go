@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/aarondl/null/v8"
+
+	"mlm/internal/util/querylib"
 )
 
 type Room struct {
@@ -14,18 +16,48 @@ type Room struct {
 	CreatedAt time.Time
 }
 
+// RoomSortField - whitelisted columns rooms can be sorted by
+type RoomSortField string
+
+const (
+	RoomSortID        RoomSortField = "id"
+	RoomSortName      RoomSortField = "name"
+	RoomSortCreatedAt RoomSortField = "created_at"
+)
+
+// RoomSort - one sort term, e.g. {RoomSortName, querylib.SortAsc}
+type RoomSort = querylib.Sort[RoomSortField]
+
 type RoomQueryFilter struct {
-	IDs       []string
-	Name      null.String
-	CreatedBy null.String
-	IsActive  null.Bool
-	CreatedAt null.Time
+	IDs          []string
+	Name         null.String
+	CreatedBy    null.String
+	CreatedByIDs []string // any of
+	IsActive     null.Bool
+	CreatedAt    null.Time
+
+	// Case-insensitive search
+	NamePrefix   null.String
+	NameContains null.String
+
+	// Created in [CreatedAfter, CreatedBefore)
+	CreatedAfter  null.Time
+	CreatedBefore null.Time
+
+	// Sorting - applied in order, with id as the final tiebreak
+	Sorts  []RoomSort
+	Limit  null.Int
+	Offset null.Int
+
+	// After is an opaque cursor from RoomPage.NextCursor; it must be used
+	// with the same Sorts it was issued for and can't be combined with Offset
+	After null.String
+}
 
-	// Sorting
-	OrderBy null.String // "created_at", "name"
-	Sort    null.String // "ASC", "DESC"
-	Limit   null.Int
-	Offset  null.Int
+// RoomPage - one page of rooms. NextCursor is empty on the last page.
+type RoomPage struct {
+	Rooms      []*Room
+	NextCursor string
 }
 
 type UpdateRoom struct {
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/util/exportlib"
+	"mlm/internal/util/querylib"
+)
+
+// roomExport is a room the user created, in a personal data archive
+type roomExport struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func init() {
+	exportlib.Register(rooms.ExportSection, exportRoomsCreated)
+}
+
+// exportRoomsCreated exports every room the user created, oldest first
+func exportRoomsCreated(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error) {
+	result, err := New().Rooms(ctx, exec, rooms.RoomQueryFilter{
+		CreatedBy: null.StringFrom(userID),
+		Sorts: []rooms.RoomSort{
+			{Field: rooms.RoomSortCreatedAt, Direction: querylib.SortAsc},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]roomExport, len(result))
+	for i, r := range result {
+		created[i] = roomExport{
+			ID:        r.ID,
+			Name:      r.Name,
+			IsActive:  r.IsActive,
+			CreatedAt: r.CreatedAt,
+		}
+	}
+	return created, nil
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -10,15 +12,29 @@ import (
 	"github.com/aarondl/sqlboiler/v4/boil"
 	"github.com/aarondl/sqlboiler/v4/queries/qm"
 
+	"mlm/internal/musicapp/db/dbid"
+	"mlm/internal/musicapp/lib/room_members"
 	"mlm/internal/musicapp/lib/rooms"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/internal/util/validationlib"
 	"mlm/models" // SQLBoiler generated models
 )
 
+var _ room_members.RoomStore = (*Store)(nil)
+
 // Store handles room queries
 type Store struct {
 	// No dependencies - store is pure query logic
 }
 
+// roomSortColumns whitelists the columns rooms can be sorted by
+var roomSortColumns = map[rooms.RoomSortField]string{
+	rooms.RoomSortID:        models.RoomColumns.ID,
+	rooms.RoomSortName:      models.RoomColumns.Name,
+	rooms.RoomSortCreatedAt: models.RoomColumns.CreatedAt,
+}
+
 // New creates a new room store
 func New() *Store {
 	return &Store{}
@@ -30,18 +46,151 @@ func (s *Store) Rooms(
 	exec boil.ContextExecutor,
 	filter rooms.RoomQueryFilter,
 ) ([]*rooms.Room, error) {
+	mods, _, err := roomQueryMods(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Execute query
+	dbRooms, err := models.Rooms(mods...).All(ctx, exec)
+	if err != nil {
+		return nil, fmt.Errorf("query rooms: %w", err)
+	}
+
+	return dbRoomsToRooms(dbRooms), nil
+}
+
+// RoomsPage returns one page of rooms matching the filter, and a cursor for
+// the next page. filter.Limit is the page size (querylib.DefaultPageSize when
+// unset); pass the returned NextCursor as filter.After to continue.
+func (s *Store) RoomsPage(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) (*rooms.RoomPage, error) {
+	pageSize := querylib.PageSize(filter.Limit)
+	filter.Limit = null.IntFrom(pageSize + 1) // one extra row tells us there is a next page
+
+	mods, keyset, err := roomQueryMods(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dbRooms, err := models.Rooms(mods...).All(ctx, exec)
+	if err != nil {
+		return nil, fmt.Errorf("query rooms: %w", err)
+	}
+
+	page := &rooms.RoomPage{}
+	if len(dbRooms) > pageSize {
+		dbRooms = dbRooms[:pageSize]
+
+		values, err := roomCursorValues(dbRooms[pageSize-1], keyset.Columns())
+		if err != nil {
+			return nil, err
+		}
+		if page.NextCursor, err = keyset.Cursor(values...); err != nil {
+			return nil, err
+		}
+	}
+	page.Rooms = dbRoomsToRooms(dbRooms)
+
+	return page, nil
+}
+
+// CountRooms returns how many rooms match the filter. Sorts and
+// pagination fields are ignored.
+func (s *Store) CountRooms(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) (int64, error) {
+	mods, err := roomFilterMods(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := models.Rooms(mods...).Count(ctx, exec)
+	if err != nil {
+		return 0, fmt.Errorf("count rooms: %w", err)
+	}
+
+	return count, nil
+}
+
+// RoomsExist reports whether any rooms match the filter, without fetching rows
+func (s *Store) RoomsExist(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter rooms.RoomQueryFilter,
+) (bool, error) {
+	mods, err := roomFilterMods(filter)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := models.Rooms(mods...).Exists(ctx, exec)
+	if err != nil {
+		return false, fmt.Errorf("check rooms exist: %w", err)
+	}
+
+	return exists, nil
+}
+
+// roomQueryMods builds the query mods for a filter, returning the keyset used
+// to order (and page through) the results
+func roomQueryMods(filter rooms.RoomQueryFilter) ([]qm.QueryMod, *querylib.Keyset, error) {
+	mods, err := roomFilterMods(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Sorting
+	keyset, err := querylib.NewKeyset(filter.Sorts, roomSortColumns, models.RoomColumns.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	mods = append(mods, keyset.OrderBy())
+
+	// Cursor
+	if filter.After.Valid {
+		if filter.Offset.Valid {
+			return nil, nil, validationlib.NewValidationError("offset", strconv.Itoa(filter.Offset.Int),
+				"can't be combined with a cursor")
+		}
+		after, err := keyset.After(filter.After.String)
+		if err != nil {
+			return nil, nil, err
+		}
+		mods = append(mods, after)
+	}
+
+	// Pagination
+	if filter.Limit.Valid {
+		mods = append(mods, qm.Limit(filter.Limit.Int))
+	}
+	if filter.Offset.Valid {
+		mods = append(mods, qm.Offset(filter.Offset.Int))
+	}
+
+	return mods, keyset, nil
+}
+
+// roomFilterMods translates the filter's conditions into WHERE mods. It is
+// shared by list, page, count and exists queries so they can't drift apart;
+// sorting and pagination are left to roomQueryMods.
+func roomFilterMods(filter rooms.RoomQueryFilter) ([]qm.QueryMod, error) {
 	mods := []qm.QueryMod{}
 
 	// IDs filter
 	if len(filter.IDs) > 0 {
-		// Convert string IDs to uint64 for current DB schema
 		ids := make([]interface{}, len(filter.IDs))
 		for i, id := range filter.IDs {
-			idNum, err := strconv.ParseUint(id, 10, 64)
+			parsed, err := dbid.Parse(id)
 			if err != nil {
 				return nil, fmt.Errorf("invalid room ID %s: %w", id, err)
 			}
-			ids[i] = idNum
+			ids[i] = parsed
 		}
 		mods = append(mods, qm.WhereIn("id IN ?", ids...))
 	}
@@ -53,11 +202,22 @@ func (s *Store) Rooms(
 
 	// CreatedBy filter
 	if filter.CreatedBy.Valid {
-		createdByNum, err := strconv.ParseUint(filter.CreatedBy.String, 10, 64)
+		createdBy, err := dbid.Parse(filter.CreatedBy.String)
 		if err != nil {
 			return nil, fmt.Errorf("invalid created_by ID %s: %w", filter.CreatedBy.String, err)
 		}
-		mods = append(mods, qm.Where("created_by = ?", createdByNum))
+		mods = append(mods, qm.Where("created_by = ?", createdBy))
+	}
+	if len(filter.CreatedByIDs) > 0 {
+		createdBy := make([]interface{}, len(filter.CreatedByIDs))
+		for i, id := range filter.CreatedByIDs {
+			parsed, err := dbid.Parse(id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid created_by ID %s: %w", id, err)
+			}
+			createdBy[i] = parsed
+		}
+		mods = append(mods, qm.WhereIn("created_by IN ?", createdBy...))
 	}
 
 	// IsActive filter
@@ -69,31 +229,35 @@ func (s *Store) Rooms(
 	if filter.CreatedAt.Valid {
 		mods = append(mods, qm.Where("created_at = ?", filter.CreatedAt.Time))
 	}
+	mods = append(mods, querylib.WhereTimeRange("created_at", filter.CreatedAfter, filter.CreatedBefore)...)
 
-	// Sorting
-	if filter.OrderBy.Valid {
-		sortDir := "ASC"
-		if filter.Sort.Valid {
-			sortDir = filter.Sort.String
-		}
-		mods = append(mods, qm.OrderBy(filter.OrderBy.String+" "+sortDir))
+	// Search filters
+	if filter.NamePrefix.Valid {
+		mods = append(mods, querylib.WhereStartsWith("name", filter.NamePrefix.String))
 	}
-
-	// Pagination
-	if filter.Limit.Valid {
-		mods = append(mods, qm.Limit(filter.Limit.Int))
-	}
-	if filter.Offset.Valid {
-		mods = append(mods, qm.Offset(filter.Offset.Int))
+	if filter.NameContains.Valid {
+		mods = append(mods, querylib.WhereContains("name", filter.NameContains.String))
 	}
 
-	// Execute query
-	dbRooms, err := models.Rooms(mods...).All(ctx, exec)
-	if err != nil {
-		return nil, fmt.Errorf("query rooms: %w", err)
-	}
+	return mods, nil
+}
 
-	return dbRoomsToRooms(dbRooms), nil
+// roomCursorValues returns the values of a room's sort columns for a cursor
+func roomCursorValues(db *models.Room, columns []string) ([]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch column {
+		case models.RoomColumns.ID:
+			values[i] = db.ID
+		case models.RoomColumns.Name:
+			values[i] = db.Name
+		case models.RoomColumns.CreatedAt:
+			values[i] = db.CreatedAt
+		default:
+			return nil, fmt.Errorf("no cursor value for room column %s", column)
+		}
+	}
+	return values, nil
 }
 
 // Room returns exactly 1 room, errors if 0 or >1 found
@@ -108,15 +272,41 @@ func (s *Store) Room(
 	}
 
 	if len(results) == 0 {
-		return nil, fmt.Errorf("no room found")
+		return nil, errlib.NotFound("room")
 	}
 	if len(results) > 1 {
-		return nil, fmt.Errorf("expected 1 room, got %d", len(results))
+		return nil, errlib.MultipleResults("room", len(results))
 	}
 
 	return results[0], nil
 }
 
+// LockRoom returns the room with the given ID and holds a shared lock on its
+// row until exec's transaction ends, so it can't be deactivated meanwhile
+func (s *Store) LockRoom(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	id string,
+) (*models.Room, error) {
+	roomID, err := dbid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid room ID %s: %w", id, err)
+	}
+
+	room, err := models.Rooms(
+		qm.Where("id = ?", roomID),
+		qm.For("SHARE"),
+	).One(ctx, exec)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errlib.NotFound("room")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock room: %w", err)
+	}
+
+	return room, nil
+}
+
 // Update performs generic update with nullable fields
 func (s *Store) Update(
 	ctx context.Context,
@@ -140,25 +330,24 @@ func (s *Store) Update(
 		cols["created_at"] = update.CreatedAt.Time
 	}
 	if update.CreatedBy.Valid {
-		createdByNum, err := strconv.ParseUint(update.CreatedBy.String, 10, 64)
+		createdBy, err := dbid.Parse(update.CreatedBy.String)
 		if err != nil {
 			return fmt.Errorf("invalid created_by ID %s: %w", update.CreatedBy.String, err)
 		}
-		cols["created_by"] = createdByNum
+		cols["created_by"] = createdBy
 	}
 
 	if len(cols) == 0 {
 		return nil // Nothing to update
 	}
 
-	// Convert string IDs to uint64
 	ids := make([]interface{}, len(update.IDs))
 	for i, id := range update.IDs {
-		idNum, err := strconv.ParseUint(id, 10, 64)
+		parsed, err := dbid.Parse(id)
 		if err != nil {
 			return fmt.Errorf("invalid room ID %s: %w", id, err)
 		}
-		ids[i] = idNum
+		ids[i] = parsed
 	}
 
 	// Execute update
@@ -166,7 +355,7 @@ func (s *Store) Update(
 		qm.WhereIn("id IN ?", ids...),
 	).UpdateAll(ctx, exec, cols)
 
-	return err
+	return errlib.FromDB(err)
 }
 
 // dbRoomsToRooms converts DB models to domain models
@@ -184,9 +373,9 @@ func dbRoomsToRooms(dbRooms []*models.Room) []*rooms.Room {
 		}
 
 		result[i] = &rooms.Room{
-			ID:        fmt.Sprintf("%d", db.ID),
+			ID:        db.ID,
 			Name:      db.Name,
-			CreatedBy: fmt.Sprintf("%d", db.CreatedBy),
+			CreatedBy: db.CreatedBy,
 			IsActive:  isActive,
 			CreatedAt: createdAt,
 		}
@@ -196,12 +385,12 @@ func dbRoomsToRooms(dbRooms []*models.Room) []*rooms.Room {
 
 // roomToDBRoom converts domain model to DB model
 func roomToDBRoom(room *rooms.Room) (*models.Room, error) {
-	id, err := strconv.ParseUint(room.ID, 10, 64)
+	id, err := dbid.Parse(room.ID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid room ID: %w", err)
 	}
 
-	createdBy, err := strconv.ParseUint(room.CreatedBy, 10, 64)
+	createdBy, err := dbid.Parse(room.CreatedBy)
 	if err != nil {
 		return nil, fmt.Errorf("invalid created_by ID: %w", err)
 	}
@@ -0,0 +1,32 @@
+package rooms
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"mlm/internal/util/validationlib"
+)
+
+// NameMaxLength matches the rooms.name column
+const NameMaxLength = 100
+
+// NormalizeName trims a room name and checks it is non-empty, fits its
+// column and has no control characters
+func NormalizeName(name string) (string, error) {
+	normalized := strings.TrimSpace(name)
+
+	if normalized == "" {
+		return "", validationlib.NewValidationError("name", name, "must not be empty")
+	}
+	if utf8.RuneCountInString(normalized) > NameMaxLength {
+		return "", validationlib.NewValidationError("name", name,
+			fmt.Sprintf("must be at most %d characters", NameMaxLength))
+	}
+	if strings.IndexFunc(normalized, unicode.IsControl) >= 0 {
+		return "", validationlib.NewValidationError("name", name, "must not contain control characters")
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,40 @@
+package rooms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/util/validationlib"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{name: "plain", input: "Jazz Lounge", expected: "Jazz Lounge"},
+		{name: "trimmed", input: "  Jazz Lounge \n", expected: "Jazz Lounge"},
+		{name: "max-length-runes", input: strings.Repeat("é", NameMaxLength), expected: strings.Repeat("é", NameMaxLength)},
+		{name: "too-long", input: strings.Repeat("a", NameMaxLength+1), err: true},
+		{name: "blank", input: "   ", err: true},
+		{name: "control-character", input: "Jazz\x00Lounge", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeName(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				assert.True(t, validationlib.IsValidationError(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
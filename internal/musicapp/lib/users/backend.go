@@ -0,0 +1,34 @@
+package users
+
+import (
+	"context"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/models"
+)
+
+// Store is the read side of a user backend: users/store on MySQL, or
+// memdb.UserStore in memory. Both must pass the db/storetest conformance
+// suite, so they agree on filtering, sorting and paging.
+type Store interface {
+	Users(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter) ([]*User, error)
+	UsersPage(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter) (*UserPage, error)
+	CountUsers(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter) (int64, error)
+	UsersExist(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter) (bool, error)
+	User(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter) (*User, error)
+	Update(ctx context.Context, exec boil.ContextExecutor, update UpdateUser) error
+}
+
+// Repo is the write side of a user backend: db/repo.UserRepo on MySQL, or
+// memdb.UserRepo in memory. Upserts are left out; they rely on MySQL's ON
+// DUPLICATE KEY handling and stay on db/repo.
+type Repo interface {
+	Insert(ctx context.Context, exec boil.ContextExecutor, user *models.User) (*models.User, error)
+	BulkInsert(ctx context.Context, exec boil.ContextExecutor, users []*models.User) error
+	Update(ctx context.Context, exec boil.ContextExecutor, user *models.User, columns boil.Columns) (*models.User, error)
+}
+
+// ExportSection is the personal data archive section users/store registers
+// with exportlib for the user's profile
+const ExportSection = "profile"
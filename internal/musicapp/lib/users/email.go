@@ -0,0 +1,29 @@
+package users
+
+import (
+	"net/mail"
+	"strings"
+
+	"mlm/internal/util/validationlib"
+)
+
+// NormalizeEmail validates a bare email address (no display name) and
+// returns it trimmed and lower-cased. Emails are stored in this form so the
+// unique index on users.email is case-insensitive.
+func NormalizeEmail(email string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(email))
+
+	addr, err := mail.ParseAddress(normalized)
+	if err != nil || addr.Address != normalized {
+		return "", validationlib.NewValidationError("email", email, "must be a valid email address")
+	}
+
+	// ParseAddress accepts dotless domains like "localhost"; users need a
+	// routable address for notifications.
+	domain := normalized[strings.LastIndex(normalized, "@")+1:]
+	if !strings.Contains(domain, ".") {
+		return "", validationlib.NewValidationError("email", email, "must be a valid email address")
+	}
+
+	return normalized, nil
+}
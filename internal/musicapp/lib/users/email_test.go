@@ -0,0 +1,40 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/util/validationlib"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{name: "plain", input: "alice@example.com", expected: "alice@example.com"},
+		{name: "mixed-case-and-spaces", input: "  Alice@Example.COM ", expected: "alice@example.com"},
+		{name: "plus-tag", input: "alice+music@example.com", expected: "alice+music@example.com"},
+		{name: "missing-at", input: "alice.example.com", err: true},
+		{name: "missing-domain-dot", input: "alice@localhost", err: true},
+		{name: "display-name", input: "Alice <alice@example.com>", err: true},
+		{name: "empty", input: "", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeEmail(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				assert.True(t, validationlib.IsValidationError(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/db/txn"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/validationlib"
+	"mlm/models"
+)
+
+var (
+	// ErrUsernameTaken is returned when another user, deleted or not, already
+	// has the username; it is an errlib.ErrConflict
+	ErrUsernameTaken = errlib.New(errlib.ErrConflict, "username is already taken")
+	// ErrEmailTaken is returned when another user, deleted or not, already
+	// has the email; it is an errlib.ErrConflict
+	ErrEmailTaken = errlib.New(errlib.ErrConflict, "email is already in use")
+)
+
+// NewUser - what Create needs; Email and DisplayName are optional
+type NewUser struct {
+	Username    string
+	Email       string
+	DisplayName string
+	Gender      Gender
+}
+
+// Logic validates user input and keeps usernames and emails unique before
+// anything reaches the store
+type Logic struct {
+	store Store
+	repo  Repo
+	tx    txn.Transactor
+}
+
+// NewLogic creates the user logic, failing fast on missing dependencies
+func NewLogic(store Store, repo Repo, tx txn.Transactor) (*Logic, error) {
+	if store == nil {
+		return nil, fmt.Errorf("users logic: store is required")
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("users logic: repo is required")
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("users logic: transactor is required")
+	}
+
+	return &Logic{
+		store: store,
+		repo:  repo,
+		tx:    tx,
+	}, nil
+}
+
+// Get returns the user with the given ID, or an errlib.ErrNotFound when it
+// does not exist or is soft-deleted
+func (l *Logic) Get(ctx context.Context, exec boil.ContextExecutor, id string) (*User, error) {
+	result, err := l.store.Users(ctx, exec, UserQueryFilter{
+		IDs: []string{id},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, errlib.NotFound("user")
+	}
+
+	return result[0], nil
+}
+
+// Page returns one page of users matching filter
+func (l *Logic) Page(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter) (*UserPage, error) {
+	return l.store.UsersPage(ctx, exec, filter)
+}
+
+// Count returns how many users match filter across all pages
+func (l *Logic) Count(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter) (int64, error) {
+	return l.store.CountUsers(ctx, exec, filter)
+}
+
+// Create validates and inserts a new user. The uniqueness checks and the
+// insert share a transaction; the unique indexes still catch a race, as an
+// errlib.ErrConflict from the repo.
+func (l *Logic) Create(ctx context.Context, exec boil.ContextExecutor, newUser NewUser) (*User, error) {
+	if err := ValidateUsername(newUser.Username); err != nil {
+		return nil, err
+	}
+	if err := ValidateGender(string(newUser.Gender)); err != nil {
+		return nil, err
+	}
+	if err := ValidateDisplayName(newUser.DisplayName); err != nil {
+		return nil, err
+	}
+
+	var email null.String
+	if newUser.Email != "" {
+		normalized, err := NormalizeEmail(newUser.Email)
+		if err != nil {
+			return nil, err
+		}
+		email = null.StringFrom(normalized)
+	}
+
+	var user *User
+	err := l.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		if err := l.checkAvailable(ctx, tx, null.StringFrom(newUser.Username), email, ""); err != nil {
+			return err
+		}
+
+		dbUser, err := l.repo.Insert(ctx, tx, &models.User{
+			Username:    newUser.Username,
+			Email:       email,
+			DisplayName: null.NewString(newUser.DisplayName, newUser.DisplayName != ""),
+			Gender:      null.StringFrom(string(newUser.Gender)),
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = l.Get(ctx, tx, dbUser.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Update validates and applies a partial update. Every user in update.IDs
+// must exist; a username or email can only be set on a single user.
+func (l *Logic) Update(ctx context.Context, exec boil.ContextExecutor, update UpdateUser) error {
+	if update.Username.Valid {
+		if len(update.IDs) > 1 {
+			return validationlib.NewValidationError("username", update.Username.String, "cannot be set on more than one user")
+		}
+		if err := ValidateUsername(update.Username.String); err != nil {
+			return err
+		}
+	}
+	if update.Email.Valid {
+		if len(update.IDs) > 1 {
+			return validationlib.NewValidationError("email", update.Email.String, "cannot be set on more than one user")
+		}
+		normalized, err := NormalizeEmail(update.Email.String)
+		if err != nil {
+			return err
+		}
+		update.Email.String = normalized
+	}
+	if update.Gender.Valid {
+		if err := ValidateGender(update.Gender.String); err != nil {
+			return err
+		}
+	}
+	if update.DisplayName.Valid {
+		if err := ValidateDisplayName(update.DisplayName.String); err != nil {
+			return err
+		}
+	}
+
+	return l.tx.WithTx(ctx, exec, func(tx boil.ContextExecutor) error {
+		found, err := l.checkExist(ctx, tx, update.IDs)
+		if err != nil {
+			return err
+		}
+
+		if len(found) == 1 {
+			if err := l.checkAvailable(ctx, tx, update.Username, update.Email, found[0].ID); err != nil {
+				return err
+			}
+		}
+
+		return l.store.Update(ctx, tx, update)
+	})
+}
+
+// checkExist loads the users, returning an errlib.ErrNotFound unless every
+// ID is an active user
+func (l *Logic) checkExist(ctx context.Context, exec boil.ContextExecutor, ids []string) ([]*User, error) {
+	if len(ids) == 0 {
+		return nil, nil // the store rejects an update without IDs
+	}
+
+	found, err := l.store.Users(ctx, exec, UserQueryFilter{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		unique[strings.ToLower(id)] = true
+	}
+	if len(found) < len(unique) {
+		return nil, errlib.NotFound("user")
+	}
+	return found, nil
+}
+
+// checkAvailable returns ErrUsernameTaken or ErrEmailTaken when a user other
+// than ownerID (empty when creating) already has the username or email. Soft-
+// deleted users are included: they still hold their row in the unique index.
+func (l *Logic) checkAvailable(ctx context.Context, exec boil.ContextExecutor, username, email null.String, ownerID string) error {
+	if username.Valid {
+		taken, err := l.takenByOther(ctx, exec, UserQueryFilter{Username: username, IncludeDeleted: true}, ownerID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrUsernameTaken
+		}
+	}
+
+	if email.Valid {
+		taken, err := l.takenByOther(ctx, exec, UserQueryFilter{Email: email, IncludeDeleted: true}, ownerID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrEmailTaken
+		}
+	}
+
+	return nil
+}
+
+func (l *Logic) takenByOther(ctx context.Context, exec boil.ContextExecutor, filter UserQueryFilter, ownerID string) (bool, error) {
+	result, err := l.store.Users(ctx, exec, filter)
+	if err != nil {
+		return false, err
+	}
+	for _, user := range result {
+		if user.ID != ownerID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
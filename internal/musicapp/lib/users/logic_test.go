@@ -0,0 +1,161 @@
+package users_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/musicapp/db/factory"
+	"mlm/internal/musicapp/db/memdb"
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/validationlib"
+)
+
+// newLogic returns logic over a fresh in-memory database
+func newLogic(t *testing.T) (*users.Logic, *memdb.DB) {
+	db := memdb.New()
+	logic, err := users.NewLogic(memdb.NewUserStore(db), memdb.NewUserRepo(db), db)
+	require.NoError(t, err)
+	return logic, db
+}
+
+func TestNewLogic(t *testing.T) {
+	_, err := users.NewLogic(nil, memdb.NewUserRepo(memdb.New()), memdb.New())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "store is required")
+}
+
+func TestLogic_Create(t *testing.T) {
+	t.Run("success-creates-user", func(t *testing.T) {
+		ctx := context.Background()
+		logic, _ := newLogic(t)
+
+		user, err := logic.Create(ctx, nil, users.NewUser{
+			Username:    "alice",
+			Email:       "Alice@Example.com",
+			DisplayName: "Alice Smith",
+			Gender:      users.GenderFemale,
+		})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, user.ID)
+		assert.Equal(t, "alice", user.Username)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, users.GenderFemale, user.Gender)
+	})
+
+	t.Run("error-invalid-input", func(t *testing.T) {
+		ctx := context.Background()
+		logic, _ := newLogic(t)
+
+		inputs := []users.NewUser{
+			{Username: "a b", Gender: users.GenderMale},
+			{Username: "alice", Gender: "unknown"},
+			{Username: "alice", Gender: users.GenderMale, Email: "not-an-email"},
+		}
+		for _, input := range inputs {
+			_, err := logic.Create(ctx, nil, input)
+			assert.True(t, validationlib.IsValidationError(err), "input %+v", input)
+		}
+	})
+
+	t.Run("error-username-taken-by-deleted-user", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		factory.MemUser(t, db, &factory.UserMods{
+			Username:  "alice",
+			DeletedAt: null.TimeFrom(time.Now()),
+		})
+
+		_, err := logic.Create(ctx, nil, users.NewUser{
+			Username: "alice",
+			Gender:   users.GenderFemale,
+		})
+		assert.ErrorIs(t, err, users.ErrUsernameTaken)
+		assert.ErrorIs(t, err, errlib.ErrConflict)
+	})
+
+	t.Run("error-email-taken", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		factory.MemUser(t, db, &factory.UserMods{
+			Email: "alice@example.com",
+		})
+
+		_, err := logic.Create(ctx, nil, users.NewUser{
+			Username: "alice2",
+			Email:    "ALICE@example.com",
+			Gender:   users.GenderFemale,
+		})
+		assert.ErrorIs(t, err, users.ErrEmailTaken)
+	})
+}
+
+func TestLogic_Update(t *testing.T) {
+	t.Run("success-keeps-own-username", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		dbUser := factory.MemUser(t, db, &factory.UserMods{
+			Username: "alice",
+		})
+
+		err := logic.Update(ctx, nil, users.UpdateUser{
+			IDs:         []string{dbUser.ID},
+			Username:    null.StringFrom("alice"),
+			DisplayName: null.StringFrom("Alice"),
+		})
+		require.NoError(t, err)
+
+		user, err := logic.Get(ctx, nil, dbUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", user.DisplayName)
+	})
+
+	t.Run("error-username-taken", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		factory.MemUser(t, db, &factory.UserMods{
+			Username: "alice",
+		})
+		dbUser := factory.MemUser(t, db, nil)
+
+		err := logic.Update(ctx, nil, users.UpdateUser{
+			IDs:      []string{dbUser.ID},
+			Username: null.StringFrom("alice"),
+		})
+		assert.ErrorIs(t, err, users.ErrUsernameTaken)
+	})
+
+	t.Run("error-invalid-gender", func(t *testing.T) {
+		ctx := context.Background()
+		logic, db := newLogic(t)
+
+		dbUser := factory.MemUser(t, db, nil)
+
+		err := logic.Update(ctx, nil, users.UpdateUser{
+			IDs:    []string{dbUser.ID},
+			Gender: null.StringFrom("robot"),
+		})
+		assert.True(t, validationlib.IsValidationError(err))
+	})
+
+	t.Run("error-user-not-found", func(t *testing.T) {
+		ctx := context.Background()
+		logic, _ := newLogic(t)
+
+		err := logic.Update(ctx, nil, users.UpdateUser{
+			IDs:         []string{"0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11"},
+			DisplayName: null.StringFrom("Nobody"),
+		})
+		assert.ErrorIs(t, err, errlib.ErrNotFound)
+	})
+}
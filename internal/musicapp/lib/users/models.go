@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/aarondl/null/v8"
+
+	"mlm/internal/util/querylib"
 )
 
 // User - Clean domain model (no DB tags)
@@ -14,6 +16,7 @@ type User struct {
 	DisplayName string
 	Gender      Gender
 	CreatedAt   time.Time
+	DeletedAt   null.Time // set when the account is soft-deleted
 }
 
 // Gender enum
@@ -25,24 +28,60 @@ const (
 	GenderOther  Gender = "other"
 )
 
+// UserSortField - whitelisted columns users can be sorted by
+type UserSortField string
+
+const (
+	UserSortID        UserSortField = "id"
+	UserSortUsername  UserSortField = "username"
+	UserSortCreatedAt UserSortField = "created_at"
+)
+
+// UserSort - one sort term, e.g. {UserSortCreatedAt, querylib.SortDesc}
+type UserSort = querylib.Sort[UserSortField]
+
 // UserQueryFilter - uses null types for optional filters
 type UserQueryFilter struct {
 	IDs      []string
 	Username null.String
 	Email    null.String
 	Gender   null.String
+	Genders  []string // any of
+
+	// Case-insensitive search
+	UsernamePrefix      null.String
+	UsernameContains    null.String
+	DisplayNamePrefix   null.String
+	DisplayNameContains null.String
+
+	// Created in [CreatedAfter, CreatedBefore)
+	CreatedAfter  null.Time
+	CreatedBefore null.Time
+
+	// Soft-deleted users are excluded unless IncludeDeleted is set
+	IncludeDeleted bool
+
+	// Sorting - applied in order, with id as the final tiebreak
+	Sorts  []UserSort
+	Limit  null.Int
+	Offset null.Int
+
+	// After is an opaque cursor from UserPage.NextCursor; it must be used
+	// with the same Sorts it was issued for and can't be combined with Offset
+	After null.String
+}
 
-	// Sorting
-	OrderBy null.String // "created_at", "username"
-	Sort    null.String // "asc", "desc", "ASC", "DESC"
-	Limit   null.Int
-	Offset  null.Int
+// UserPage - one page of users. NextCursor is empty on the last page.
+type UserPage struct {
+	Users      []*User
+	NextCursor string
 }
 
 // UpdateUser - nullable fields for partial updates
 type UpdateUser struct {
 	IDs         []string // Which users to update
 	Username    null.String
+	Email       null.String // validated and lower-cased by the store
 	DisplayName null.String
 	Gender      null.String
 }
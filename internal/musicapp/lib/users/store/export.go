@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/boil"
+
+	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/exportlib"
+)
+
+// profileExport is the user's own record in a personal data archive
+type profileExport struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Gender      string    `json:"gender"`
+	CreatedAt   time.Time `json:"created_at"`
+	DeletedAt   null.Time `json:"deleted_at"`
+}
+
+func init() {
+	exportlib.Register(users.ExportSection, exportProfile)
+}
+
+// exportProfile exports the user's profile, including soft-deleted accounts
+func exportProfile(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error) {
+	result, err := New().Users(ctx, exec, users.UserQueryFilter{
+		IDs:            []string{userID},
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	u := result[0]
+	return profileExport{
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+		Gender:      string(u.Gender),
+		CreatedAt:   u.CreatedAt,
+		DeletedAt:   u.DeletedAt,
+	}, nil
+}
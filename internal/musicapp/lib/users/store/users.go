@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aarondl/null/v8"
 	"github.com/aarondl/sqlboiler/v4/boil"
 	"github.com/aarondl/sqlboiler/v4/queries/qm"
 
+	"mlm/internal/musicapp/db/dbid"
 	"mlm/internal/musicapp/lib/users"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/internal/util/validationlib"
 	"mlm/models" // SQLBoiler generated models
 )
 
@@ -19,6 +24,13 @@ type Store struct {
 	// No dependencies - store is pure query logic
 }
 
+// userSortColumns whitelists the columns users can be sorted by
+var userSortColumns = map[users.UserSortField]string{
+	users.UserSortID:        models.UserColumns.ID,
+	users.UserSortUsername:  models.UserColumns.Username,
+	users.UserSortCreatedAt: models.UserColumns.CreatedAt,
+}
+
 // New creates a new user store
 func New() *Store {
 	return &Store{}
@@ -30,18 +42,151 @@ func (s *Store) Users(
 	exec boil.ContextExecutor,
 	filter users.UserQueryFilter,
 ) ([]*users.User, error) {
+	mods, _, err := userQueryMods(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Execute query
+	dbUsers, err := models.Users(mods...).All(ctx, exec)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+
+	return dbUsersToUsers(dbUsers), nil
+}
+
+// UsersPage returns one page of users matching the filter, and a cursor for
+// the next page. filter.Limit is the page size (querylib.DefaultPageSize when
+// unset); pass the returned NextCursor as filter.After to continue.
+func (s *Store) UsersPage(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) (*users.UserPage, error) {
+	pageSize := querylib.PageSize(filter.Limit)
+	filter.Limit = null.IntFrom(pageSize + 1) // one extra row tells us there is a next page
+
+	mods, keyset, err := userQueryMods(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dbUsers, err := models.Users(mods...).All(ctx, exec)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+
+	page := &users.UserPage{}
+	if len(dbUsers) > pageSize {
+		dbUsers = dbUsers[:pageSize]
+
+		values, err := userCursorValues(dbUsers[pageSize-1], keyset.Columns())
+		if err != nil {
+			return nil, err
+		}
+		if page.NextCursor, err = keyset.Cursor(values...); err != nil {
+			return nil, err
+		}
+	}
+	page.Users = dbUsersToUsers(dbUsers)
+
+	return page, nil
+}
+
+// CountUsers returns how many users match the filter. Sorts and
+// pagination fields are ignored.
+func (s *Store) CountUsers(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) (int64, error) {
+	mods, err := userFilterMods(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := models.Users(mods...).Count(ctx, exec)
+	if err != nil {
+		return 0, fmt.Errorf("count users: %w", err)
+	}
+
+	return count, nil
+}
+
+// UsersExist reports whether any users match the filter, without fetching rows
+func (s *Store) UsersExist(
+	ctx context.Context,
+	exec boil.ContextExecutor,
+	filter users.UserQueryFilter,
+) (bool, error) {
+	mods, err := userFilterMods(filter)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := models.Users(mods...).Exists(ctx, exec)
+	if err != nil {
+		return false, fmt.Errorf("check users exist: %w", err)
+	}
+
+	return exists, nil
+}
+
+// userQueryMods builds the query mods for a filter, returning the keyset used
+// to order (and page through) the results
+func userQueryMods(filter users.UserQueryFilter) ([]qm.QueryMod, *querylib.Keyset, error) {
+	mods, err := userFilterMods(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Sorting
+	keyset, err := querylib.NewKeyset(filter.Sorts, userSortColumns, models.UserColumns.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	mods = append(mods, keyset.OrderBy())
+
+	// Cursor
+	if filter.After.Valid {
+		if filter.Offset.Valid {
+			return nil, nil, validationlib.NewValidationError("offset", strconv.Itoa(filter.Offset.Int),
+				"can't be combined with a cursor")
+		}
+		after, err := keyset.After(filter.After.String)
+		if err != nil {
+			return nil, nil, err
+		}
+		mods = append(mods, after)
+	}
+
+	// Pagination
+	if filter.Limit.Valid {
+		mods = append(mods, qm.Limit(filter.Limit.Int))
+	}
+	if filter.Offset.Valid {
+		mods = append(mods, qm.Offset(filter.Offset.Int))
+	}
+
+	return mods, keyset, nil
+}
+
+// userFilterMods translates the filter's conditions into WHERE mods. It is
+// shared by list, page, count and exists queries so they can't drift apart;
+// sorting and pagination are left to userQueryMods.
+func userFilterMods(filter users.UserQueryFilter) ([]qm.QueryMod, error) {
 	mods := []qm.QueryMod{}
 
 	// IDs filter
 	if len(filter.IDs) > 0 {
-		// Convert string IDs to uint64 for current DB schema
 		ids := make([]interface{}, len(filter.IDs))
 		for i, id := range filter.IDs {
-			idNum, err := strconv.ParseUint(id, 10, 64)
+			parsed, err := dbid.Parse(id)
 			if err != nil {
 				return nil, fmt.Errorf("invalid user ID %s: %w", id, err)
 			}
-			ids[i] = idNum
+			ids[i] = parsed
 		}
 		mods = append(mods, qm.WhereIn("id IN ?", ids...))
 	}
@@ -51,40 +196,64 @@ func (s *Store) Users(
 		mods = append(mods, qm.Where("username = ?", filter.Username.String))
 	}
 
-	// Email filter
+	// Soft-deleted users are hidden by default
+	if !filter.IncludeDeleted {
+		mods = append(mods, qm.Where("deleted_at IS NULL"))
+	}
+
+	// Email filter - emails are stored lower-cased
 	if filter.Email.Valid {
-		mods = append(mods, qm.Where("email = ?", filter.Email.String))
+		mods = append(mods, qm.Where("email = ?", strings.ToLower(strings.TrimSpace(filter.Email.String))))
 	}
 
 	// Gender filter
 	if filter.Gender.Valid {
 		mods = append(mods, qm.Where("gender = ?", string(filter.Gender.String)))
 	}
-
-	// Sorting
-	if filter.OrderBy.Valid {
-		sortDir := "ASC"
-		if filter.Sort.Valid {
-			sortDir = filter.Sort.String
+	if len(filter.Genders) > 0 {
+		genders := make([]interface{}, len(filter.Genders))
+		for i, gender := range filter.Genders {
+			genders[i] = gender
 		}
-		mods = append(mods, qm.OrderBy(filter.OrderBy.String+" "+sortDir))
+		mods = append(mods, qm.WhereIn("gender IN ?", genders...))
 	}
 
-	// Pagination
-	if filter.Limit.Valid {
-		mods = append(mods, qm.Limit(filter.Limit.Int))
+	// Search filters
+	if filter.UsernamePrefix.Valid {
+		mods = append(mods, querylib.WhereStartsWith("username", filter.UsernamePrefix.String))
 	}
-	if filter.Offset.Valid {
-		mods = append(mods, qm.Offset(filter.Offset.Int))
+	if filter.UsernameContains.Valid {
+		mods = append(mods, querylib.WhereContains("username", filter.UsernameContains.String))
 	}
-
-	// Execute query
-	dbUsers, err := models.Users(mods...).All(ctx, exec)
-	if err != nil {
-		return nil, fmt.Errorf("query users: %w", err)
+	if filter.DisplayNamePrefix.Valid {
+		mods = append(mods, querylib.WhereStartsWith("display_name", filter.DisplayNamePrefix.String))
+	}
+	if filter.DisplayNameContains.Valid {
+		mods = append(mods, querylib.WhereContains("display_name", filter.DisplayNameContains.String))
 	}
 
-	return dbUsersToUsers(dbUsers), nil
+	// CreatedAt range
+	mods = append(mods, querylib.WhereTimeRange("created_at", filter.CreatedAfter, filter.CreatedBefore)...)
+
+	return mods, nil
+}
+
+// userCursorValues returns the values of a user's sort columns for a cursor
+func userCursorValues(db *models.User, columns []string) ([]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch column {
+		case models.UserColumns.ID:
+			values[i] = db.ID
+		case models.UserColumns.Username:
+			values[i] = db.Username
+		case models.UserColumns.CreatedAt:
+			values[i] = db.CreatedAt
+		default:
+			return nil, fmt.Errorf("no cursor value for user column %s", column)
+		}
+	}
+	return values, nil
 }
 
 // User returns exactly 1 user, errors if 0 or >1 found
@@ -99,10 +268,10 @@ func (s *Store) User(
 	}
 
 	if len(results) == 0 {
-		return nil, fmt.Errorf("no user found")
+		return nil, errlib.NotFound("user")
 	}
 	if len(results) > 1 {
-		return nil, fmt.Errorf("expected 1 user, got %d", len(results))
+		return nil, errlib.MultipleResults("user", len(results))
 	}
 
 	return results[0], nil
@@ -124,6 +293,17 @@ func (s *Store) Update(
 	if update.Username.Valid {
 		cols["username"] = update.Username.String
 	}
+	if update.Email.Valid {
+		// Emails are unique, so one can't be given to several users at once
+		if len(update.IDs) > 1 {
+			return validationlib.NewValidationError("email", update.Email.String, "cannot be set on more than one user")
+		}
+		email, err := users.NormalizeEmail(update.Email.String)
+		if err != nil {
+			return err
+		}
+		cols["email"] = email
+	}
 	if update.DisplayName.Valid {
 		cols["display_name"] = update.DisplayName.String
 	}
@@ -135,14 +315,13 @@ func (s *Store) Update(
 		return nil // Nothing to update
 	}
 
-	// Convert string IDs to uint64
 	ids := make([]interface{}, len(update.IDs))
 	for i, id := range update.IDs {
-		idNum, err := strconv.ParseUint(id, 10, 64)
+		parsed, err := dbid.Parse(id)
 		if err != nil {
 			return fmt.Errorf("invalid user ID %s: %w", id, err)
 		}
-		ids[i] = idNum
+		ids[i] = parsed
 	}
 
 	// Execute update
@@ -150,7 +329,7 @@ func (s *Store) Update(
 		qm.WhereIn("id IN ?", ids...),
 	).UpdateAll(ctx, exec, cols)
 
-	return err
+	return errlib.FromDB(err)
 }
 
 // dbUsersToUsers converts DB models to domain models
@@ -173,12 +352,13 @@ func dbUsersToUsers(dbUsers []*models.User) []*users.User {
 		}
 
 		result[i] = &users.User{
-			ID:          fmt.Sprintf("%d", db.ID),
+			ID:          db.ID,
 			Username:    db.Username,
-			Email:       "", // TODO: Add after migration
+			Email:       db.Email.String,
 			DisplayName: displayName,
 			Gender:      gender,
 			CreatedAt:   createdAt,
+			DeletedAt:   db.DeletedAt,
 		}
 	}
 	return result
@@ -186,14 +366,24 @@ func dbUsersToUsers(dbUsers []*models.User) []*users.User {
 
 // userToDBUser converts domain model to DB model
 func userToDBUser(user *users.User) (*models.User, error) {
-	id, err := strconv.ParseUint(user.ID, 10, 64)
+	id, err := dbid.Parse(user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
+	var email null.String
+	if user.Email != "" {
+		normalized, err := users.NormalizeEmail(user.Email)
+		if err != nil {
+			return nil, err
+		}
+		email = null.StringFrom(normalized)
+	}
+
 	return &models.User{
 		ID:       id,
 		Username: user.Username,
+		Email:    email,
 		DisplayName: null.String{
 			String: user.DisplayName,
 			Valid:  user.DisplayName != "",
@@ -1,7 +1,6 @@
 package store_test
 
 import (
-	"fmt"
 	"testing"
 	"time"
 
@@ -13,6 +12,9 @@ import (
 	"mlm/internal/musicapp/lib/users"
 	"mlm/internal/musicapp/lib/users/store"
 	"mlm/internal/testsuite"
+	"mlm/internal/util/errlib"
+	"mlm/internal/util/querylib"
+	"mlm/internal/util/validationlib"
 )
 
 // Test case struct for Users()
@@ -108,6 +110,69 @@ func usersTestCases() []testCaseUsers {
 				assert.Equal(th.T, "alice", result[0].Username)
 			},
 		},
+		{
+			name: "success-filters-by-email-ignoring-case",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "alice",
+					Email:    "alice@example.com",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "bob",
+				})
+
+				return users.UserQueryFilter{
+					Email: null.StringFrom(" Alice@Example.COM"),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				require.Len(th.T, result, 1)
+				assert.Equal(th.T, "alice", result[0].Username)
+				assert.Equal(th.T, "alice@example.com", result[0].Email)
+			},
+		},
+		{
+			name: "success-excludes-soft-deleted-by-default",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "alice",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username:  "bob",
+					DeletedAt: null.TimeFrom(time.Now()),
+				})
+
+				return users.UserQueryFilter{}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				require.Len(th.T, result, 1)
+				assert.Equal(th.T, "alice", result[0].Username)
+			},
+		},
+		{
+			name: "success-includes-soft-deleted-when-asked",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "alice",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username:  "bob",
+					DeletedAt: null.TimeFrom(time.Now()),
+				})
+
+				return users.UserQueryFilter{
+					Username:       null.StringFrom("bob"),
+					IncludeDeleted: true,
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				require.Len(th.T, result, 1)
+				assert.True(th.T, result[0].DeletedAt.Valid)
+			},
+		},
 		{
 			name: "success-sorts-by-created-at-desc",
 			setup: func(th *testsuite.Helper) users.UserQueryFilter {
@@ -120,8 +185,9 @@ func usersTestCases() []testCaseUsers {
 				})
 
 				return users.UserQueryFilter{
-					OrderBy: null.StringFrom("created_at"),
-					Sort:    null.StringFrom("DESC"),
+					Sorts: []users.UserSort{
+						{Field: users.UserSortCreatedAt, Direction: querylib.SortDesc},
+					},
 				}
 			},
 			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
@@ -146,8 +212,9 @@ func usersTestCases() []testCaseUsers {
 				})
 
 				return users.UserQueryFilter{
-					OrderBy: null.StringFrom("username"),
-					Sort:    null.StringFrom("ASC"),
+					Sorts: []users.UserSort{
+						{Field: users.UserSortUsername, Direction: querylib.SortAsc},
+					},
 				}
 			},
 			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
@@ -158,6 +225,151 @@ func usersTestCases() []testCaseUsers {
 				assert.Equal(th.T, "charlie", result[2].Username)
 			},
 		},
+		{
+			name: "success-sorts-by-username-desc",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "bob",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "alice",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "carol",
+				})
+
+				return users.UserQueryFilter{
+					Sorts: []users.UserSort{
+						{Field: users.UserSortUsername, Direction: querylib.SortDesc},
+					},
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 3)
+				assert.Equal(th.T, "carol", result[0].Username)
+				assert.Equal(th.T, "bob", result[1].Username)
+				assert.Equal(th.T, "alice", result[2].Username)
+			},
+		},
+		{
+			name: "error-unknown-sort-field",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				return users.UserQueryFilter{
+					Sorts: []users.UserSort{
+						{Field: "username; DROP TABLE users"},
+					},
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.Error(th.T, err)
+				assert.True(th.T, validationlib.IsValidationError(err))
+				assert.Nil(th.T, result)
+			},
+		},
+		{
+			name: "error-invalid-sort-direction",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				return users.UserQueryFilter{
+					Sorts: []users.UserSort{
+						{Field: users.UserSortUsername, Direction: "sideways"},
+					},
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.Error(th.T, err)
+				assert.True(th.T, validationlib.IsValidationError(err))
+			},
+		},
+		{
+			name: "error-legacy-integer-id",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				return users.UserQueryFilter{
+					IDs: []string{"42"},
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.Error(th.T, err)
+				assert.Contains(th.T, err.Error(), "invalid user ID 42")
+			},
+		},
+		{
+			name: "success-filters-by-username-prefix-ignoring-case",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "Alice",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "alfred",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "malice",
+				})
+
+				return users.UserQueryFilter{
+					UsernamePrefix: null.StringFrom("AL"),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 2)
+			},
+		},
+		{
+			name: "success-contains-treats-wildcards-literally",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "dj_kool",
+				})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{
+					Username: "djxkool",
+				})
+
+				return users.UserQueryFilter{
+					UsernameContains: null.StringFrom("j_k"),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 1)
+				assert.Equal(th.T, "dj_kool", result[0].Username)
+			},
+		},
+		{
+			name: "success-filters-by-multiple-genders",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{Gender: "male"})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{Gender: "female"})
+				factory.User(th.T, th.BackendAppDb(), &factory.UserMods{Gender: "other"})
+
+				return users.UserQueryFilter{
+					Genders: []string{string(users.GenderMale), string(users.GenderOther)},
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 2)
+				for _, u := range result {
+					assert.NotEqual(th.T, users.GenderFemale, u.Gender)
+				}
+			},
+		},
+		{
+			name: "success-filters-by-created-range",
+			setup: func(th *testsuite.Helper) users.UserQueryFilter {
+				factory.Users(th.T, th.BackendAppDb(), 2, nil)
+
+				now := time.Now()
+				return users.UserQueryFilter{
+					CreatedAfter:  null.TimeFrom(now.Add(-time.Hour)),
+					CreatedBefore: null.TimeFrom(now.Add(-time.Minute)),
+				}
+			},
+			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
+				require.NoError(th.T, err)
+				assert.Len(th.T, result, 0)
+			},
+		},
 		{
 			name: "success-limits-results",
 			setup: func(th *testsuite.Helper) users.UserQueryFilter {
@@ -178,10 +390,11 @@ func usersTestCases() []testCaseUsers {
 				factory.Users(th.T, th.BackendAppDb(), 5, nil)
 
 				return users.UserQueryFilter{
-					OrderBy: null.StringFrom("id"),
-					Sort:    null.StringFrom("ASC"),
-					Limit:   null.IntFrom(2),
-					Offset:  null.IntFrom(2),
+					Sorts: []users.UserSort{
+						{Field: users.UserSortID, Direction: querylib.SortAsc},
+					},
+					Limit:  null.IntFrom(2),
+					Offset: null.IntFrom(2),
 				}
 			},
 			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
@@ -199,8 +412,8 @@ func usersTestCases() []testCaseUsers {
 
 				return users.UserQueryFilter{
 					IDs: []string{
-						fmt.Sprintf("%d", u1.ID),
-						fmt.Sprintf("%d", u2.ID),
+						u1.ID,
+						u2.ID,
 					},
 				}
 			},
@@ -241,10 +454,11 @@ func usersTestCases() []testCaseUsers {
 				})
 
 				return users.UserQueryFilter{
-					Gender:  null.StringFrom(string(users.GenderMale)),
-					OrderBy: null.StringFrom("username"),
-					Sort:    null.StringFrom("ASC"),
-					Limit:   null.IntFrom(10),
+					Gender: null.StringFrom(string(users.GenderMale)),
+					Sorts: []users.UserSort{
+						{Field: users.UserSortUsername, Direction: querylib.SortAsc},
+					},
+					Limit: null.IntFrom(10),
 				}
 			},
 			extraAssertions: func(th *testsuite.Helper, result []*users.User, err error) {
@@ -282,6 +496,129 @@ func TestStore_Users(t *testing.T) {
 	}
 }
 
+// TestStore_UsersPage - walks every page by cursor
+func TestStore_UsersPage(t *testing.T) {
+	t.Run("success-pages-through-all-users", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		for _, name := range []string{"erin", "alice", "dave", "bob", "carol"} {
+			factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+				Username: name,
+			})
+		}
+
+		store := store.New()
+		filter := users.UserQueryFilter{
+			Sorts: []users.UserSort{
+				{Field: users.UserSortUsername, Direction: querylib.SortAsc},
+			},
+			Limit: null.IntFrom(2),
+		}
+
+		var names []string
+		pages := 0
+		for {
+			page, err := store.UsersPage(testSuite.Ctx, testSuite.BackendAppDb(), filter)
+			require.NoError(testSuite.T, err)
+			pages++
+
+			for _, u := range page.Users {
+				names = append(names, u.Username)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			filter.After = null.StringFrom(page.NextCursor)
+		}
+
+		assert.Equal(testSuite.T, 3, pages)
+		assert.Equal(testSuite.T, []string{"alice", "bob", "carol", "dave", "erin"}, names)
+	})
+
+	t.Run("error-cursor-from-different-sort", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		factory.Users(testSuite.T, testSuite.BackendAppDb(), 3, nil)
+
+		store := store.New()
+		page, err := store.UsersPage(testSuite.Ctx, testSuite.BackendAppDb(), users.UserQueryFilter{
+			Limit: null.IntFrom(1),
+		})
+		require.NoError(testSuite.T, err)
+		require.NotEmpty(testSuite.T, page.NextCursor)
+
+		_, err = store.UsersPage(testSuite.Ctx, testSuite.BackendAppDb(), users.UserQueryFilter{
+			Sorts: []users.UserSort{
+				{Field: users.UserSortUsername},
+			},
+			After: null.StringFrom(page.NextCursor),
+		})
+		require.Error(testSuite.T, err)
+		assert.True(testSuite.T, validationlib.IsValidationError(err))
+	})
+}
+
+// TestStore_CountUsers - count and exists share the Users() filter
+func TestStore_CountUsers(t *testing.T) {
+	t.Run("success-counts-matching-users-ignoring-pagination", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		factory.Users(testSuite.T, testSuite.BackendAppDb(), 3, &factory.UserMods{
+			Gender: "female",
+		})
+		factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			Gender: "male",
+		})
+
+		store := store.New()
+		count, err := store.CountUsers(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UserQueryFilter{
+				Gender: null.StringFrom(string(users.GenderFemale)),
+				Limit:  null.IntFrom(1),
+				Offset: null.IntFrom(1),
+			},
+		)
+
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, int64(3), count)
+	})
+
+	t.Run("success-exists", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			Username: "alice",
+		})
+
+		store := store.New()
+		exists, err := store.UsersExist(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UserQueryFilter{Username: null.StringFrom("alice")},
+		)
+		require.NoError(testSuite.T, err)
+		assert.True(testSuite.T, exists)
+
+		exists, err = store.UsersExist(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UserQueryFilter{Username: null.StringFrom("nobody")},
+		)
+		require.NoError(testSuite.T, err)
+		assert.False(testSuite.T, exists)
+	})
+}
+
 // TestStore_User - test User() method (singular)
 func TestStore_User(t *testing.T) {
 	t.Run("success-returns-single-user", func(t *testing.T) {
@@ -298,12 +635,12 @@ func TestStore_User(t *testing.T) {
 			testSuite.Ctx,
 			testSuite.BackendAppDb(),
 			users.UserQueryFilter{
-				IDs: []string{fmt.Sprintf("%d", dbUser.ID)},
+				IDs: []string{dbUser.ID},
 			},
 		)
 
 		require.NoError(testSuite.T, err)
-		assert.Equal(testSuite.T, fmt.Sprintf("%d", dbUser.ID), result.ID)
+		assert.Equal(testSuite.T, dbUser.ID, result.ID)
 		assert.Equal(testSuite.T, "testuser", result.Username)
 	})
 
@@ -323,6 +660,7 @@ func TestStore_User(t *testing.T) {
 
 		require.Error(testSuite.T, err)
 		assert.Contains(testSuite.T, err.Error(), "no user found")
+		assert.ErrorIs(testSuite.T, err, errlib.ErrNotFound)
 	})
 
 	t.Run("error-multiple-users-found", func(t *testing.T) {
@@ -349,6 +687,7 @@ func TestStore_User(t *testing.T) {
 
 		require.Error(testSuite.T, err)
 		assert.Contains(testSuite.T, err.Error(), "expected 1 user, got 2")
+		assert.ErrorIs(testSuite.T, err, errlib.ErrMultipleResults)
 	})
 }
 
@@ -368,7 +707,7 @@ func TestStore_Update(t *testing.T) {
 			testSuite.Ctx,
 			testSuite.BackendAppDb(),
 			users.UpdateUser{
-				IDs:      []string{fmt.Sprintf("%d", dbUser.ID)},
+				IDs:      []string{dbUser.ID},
 				Username: null.StringFrom("newname"),
 			},
 		)
@@ -380,7 +719,7 @@ func TestStore_Update(t *testing.T) {
 			testSuite.Ctx,
 			testSuite.BackendAppDb(),
 			users.UserQueryFilter{
-				IDs: []string{fmt.Sprintf("%d", dbUser.ID)},
+				IDs: []string{dbUser.ID},
 			},
 		)
 
@@ -404,7 +743,7 @@ func TestStore_Update(t *testing.T) {
 			testSuite.Ctx,
 			testSuite.BackendAppDb(),
 			users.UpdateUser{
-				IDs:         []string{fmt.Sprintf("%d", dbUser.ID)},
+				IDs:         []string{dbUser.ID},
 				Username:    null.StringFrom("newname"),
 				Gender:      null.StringFrom(string(users.GenderFemale)),
 				DisplayName: null.StringFrom("New Display"),
@@ -418,7 +757,7 @@ func TestStore_Update(t *testing.T) {
 			testSuite.Ctx,
 			testSuite.BackendAppDb(),
 			users.UserQueryFilter{
-				IDs: []string{fmt.Sprintf("%d", dbUser.ID)},
+				IDs: []string{dbUser.ID},
 			},
 		)
 
@@ -442,8 +781,8 @@ func TestStore_Update(t *testing.T) {
 			testSuite.BackendAppDb(),
 			users.UpdateUser{
 				IDs: []string{
-					fmt.Sprintf("%d", u1.ID),
-					fmt.Sprintf("%d", u2.ID),
+					u1.ID,
+					u2.ID,
 				},
 				Gender: null.StringFrom(string(users.GenderOther)),
 			},
@@ -464,6 +803,123 @@ func TestStore_Update(t *testing.T) {
 		assert.Len(testSuite.T, updated, 2)
 	})
 
+	t.Run("success-updates-email-lower-cased", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		dbUser := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+
+		store := store.New()
+		err := store.Update(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UpdateUser{
+				IDs:   []string{dbUser.ID},
+				Email: null.StringFrom("New.Address@Example.com"),
+			},
+		)
+
+		require.NoError(testSuite.T, err)
+
+		updated, err := store.User(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UserQueryFilter{
+				IDs: []string{dbUser.ID},
+			},
+		)
+
+		require.NoError(testSuite.T, err)
+		assert.Equal(testSuite.T, "new.address@example.com", updated.Email)
+	})
+
+	t.Run("error-invalid-email", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		dbUser := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+
+		store := store.New()
+		err := store.Update(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UpdateUser{
+				IDs:   []string{dbUser.ID},
+				Email: null.StringFrom("not-an-email"),
+			},
+		)
+
+		require.Error(testSuite.T, err)
+		assert.True(testSuite.T, validationlib.IsValidationError(err))
+	})
+
+	t.Run("error-email-on-multiple-users", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		u1 := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+		u2 := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+
+		store := store.New()
+		err := store.Update(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UpdateUser{
+				IDs:   []string{u1.ID, u2.ID},
+				Email: null.StringFrom("shared@example.com"),
+			},
+		)
+
+		require.Error(testSuite.T, err)
+		assert.True(testSuite.T, validationlib.IsValidationError(err))
+	})
+
+	t.Run("error-duplicate-username", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		factory.User(testSuite.T, testSuite.BackendAppDb(), &factory.UserMods{
+			Username: "taken",
+		})
+		dbUser := factory.User(testSuite.T, testSuite.BackendAppDb(), nil)
+
+		store := store.New()
+		err := store.Update(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UpdateUser{
+				IDs:      []string{dbUser.ID},
+				Username: null.StringFrom("taken"),
+			},
+		)
+
+		require.Error(testSuite.T, err)
+		assert.ErrorIs(testSuite.T, err, errlib.ErrConflict)
+	})
+
+	t.Run("error-invalid-id", func(t *testing.T) {
+
+		testSuite := testsuite.New(t)
+		t.Cleanup(testSuite.UseBackendDB())
+
+		store := store.New()
+		err := store.Update(
+			testSuite.Ctx,
+			testSuite.BackendAppDb(),
+			users.UpdateUser{
+				IDs:      []string{"42"},
+				Username: null.StringFrom("newname"),
+			},
+		)
+
+		require.Error(testSuite.T, err)
+		assert.ErrorIs(testSuite.T, err, errlib.ErrInvalidID)
+	})
+
 	t.Run("error-no-ids-provided", func(t *testing.T) {
 
 		testSuite := testsuite.New(t)
@@ -495,7 +951,7 @@ func TestStore_Update(t *testing.T) {
 			testSuite.Ctx,
 			testSuite.BackendAppDb(),
 			users.UpdateUser{
-				IDs: []string{fmt.Sprintf("%d", dbUser.ID)},
+				IDs: []string{dbUser.ID},
 				// No fields to update
 			},
 		)
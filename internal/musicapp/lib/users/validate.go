@@ -0,0 +1,52 @@
+package users
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"mlm/internal/util/validationlib"
+)
+
+// Username and display name limits; the maximums match the users columns
+const (
+	UsernameMinLength    = 3
+	UsernameMaxLength    = 50
+	DisplayNameMaxLength = 100
+)
+
+// usernamePattern allows ASCII letters, digits, '_', '.' and '-', starting
+// with a letter or digit
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateUsername checks a username against the length and charset rules
+func ValidateUsername(username string) error {
+	if len(username) < UsernameMinLength || len(username) > UsernameMaxLength {
+		return validationlib.NewValidationError("username", username,
+			fmt.Sprintf("must be %d to %d characters", UsernameMinLength, UsernameMaxLength))
+	}
+	if !usernamePattern.MatchString(username) {
+		return validationlib.NewValidationError("username", username,
+			"may only contain letters, digits, '_', '.' and '-', and must start with a letter or digit")
+	}
+	return nil
+}
+
+// ValidateGender checks gender is one of the Gender values
+func ValidateGender(gender string) error {
+	switch Gender(gender) {
+	case GenderMale, GenderFemale, GenderOther:
+		return nil
+	}
+	return validationlib.NewValidationError("gender", gender,
+		fmt.Sprintf("must be one of %s, %s, %s", GenderMale, GenderFemale, GenderOther))
+}
+
+// ValidateDisplayName checks a display name fits its column; empty is allowed
+func ValidateDisplayName(displayName string) error {
+	if utf8.RuneCountInString(displayName) > DisplayNameMaxLength {
+		return validationlib.NewValidationError("display_name", displayName,
+			fmt.Sprintf("must be at most %d characters", DisplayNameMaxLength))
+	}
+	return nil
+}
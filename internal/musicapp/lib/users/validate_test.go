@@ -0,0 +1,54 @@
+package users
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mlm/internal/util/validationlib"
+)
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   bool
+	}{
+		{name: "plain", input: "alice"},
+		{name: "punctuation", input: "dj.alice_99-x"},
+		{name: "max-length", input: strings.Repeat("a", UsernameMaxLength)},
+		{name: "erased-placeholder", input: "erased_0b6f4a3e5d1c4a5e9a8b2f1e7c9d0a11"},
+		{name: "too-short", input: "al", err: true},
+		{name: "too-long", input: strings.Repeat("a", UsernameMaxLength+1), err: true},
+		{name: "space", input: "alice smith", err: true},
+		{name: "leading-punctuation", input: "_alice", err: true},
+		{name: "non-ascii", input: "alicé", err: true},
+		{name: "empty", input: "", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUsername(tt.input)
+			if tt.err {
+				assert.True(t, validationlib.IsValidationError(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateGender(t *testing.T) {
+	assert.NoError(t, ValidateGender("male"))
+	assert.NoError(t, ValidateGender("female"))
+	assert.NoError(t, ValidateGender("other"))
+	assert.True(t, validationlib.IsValidationError(ValidateGender("Male")))
+	assert.True(t, validationlib.IsValidationError(ValidateGender("")))
+}
+
+func TestValidateDisplayName(t *testing.T) {
+	assert.NoError(t, ValidateDisplayName(""))
+	assert.NoError(t, ValidateDisplayName(strings.Repeat("é", DisplayNameMaxLength)))
+	assert.True(t, validationlib.IsValidationError(ValidateDisplayName(strings.Repeat("a", DisplayNameMaxLength+1))))
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
 	"testing"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/aarondl/sqlboiler/v4/boil"
 )
 
+// defaultDSN is the test database used when MUSICAPP_TEST_DSN is unset
+const defaultDSN = "user:userpass@tcp(127.0.0.1:3306)/musicapp_test?parseTime=true"
+
 // Helper provides test infrastructure
 type Helper struct {
 	T   *testing.T
@@ -28,8 +32,11 @@ func New(t *testing.T) *Helper {
 // UseBackendDB connects to test database and returns cleanup function
 func (h *Helper) UseBackendDB() func() {
 	// Connect to test database
-	// Using environment variables or hardcoded test DB
-	dsn := "user:userpass@tcp(127.0.0.1:3306)/musicapp_test?parseTime=true"
+	// Using MUSICAPP_TEST_DSN or the default test DB
+	dsn := os.Getenv("MUSICAPP_TEST_DSN")
+	if dsn == "" {
+		dsn = defaultDSN
+	}
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
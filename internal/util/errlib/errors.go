@@ -0,0 +1,100 @@
+// Package errlib defines the error kinds shared by stores, repos, logic and
+// the HTTP layer. Callers test for a kind with errors.Is; the message of the
+// concrete error is left to whoever creates it.
+package errlib
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Error kinds
+var (
+	// ErrNotFound means the requested record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrMultipleResults means a lookup expected one record and found several
+	ErrMultipleResults = errors.New("multiple results")
+	// ErrConflict means a write clashed with an existing record, e.g. a
+	// duplicate key
+	ErrConflict = errors.New("conflict")
+	// ErrForeignKey means a write referenced a missing record, or a delete
+	// would orphan one
+	ErrForeignKey = errors.New("foreign key violation")
+	// ErrInvalidID means an ID is not in the format the database uses
+	ErrInvalidID = errors.New("invalid id")
+)
+
+// MySQL errors translated by FromDB
+const (
+	errDuplicateEntry  uint16 = 1062
+	errRowIsReferenced uint16 = 1451
+	errNoReferencedRow uint16 = 1452
+)
+
+// Error is an error of a given Kind. Its message is its own, so existing
+// messages survive, and the underlying cause (if any) stays reachable
+// through errors.As.
+type Error struct {
+	Kind error
+	Msg  string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+// Is matches the error's Kind, so errors.Is(err, ErrNotFound) works however
+// deeply err is wrapped
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error of kind with a formatted message
+func New(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+// NotFound reports that no entity matched, e.g. "no user found"
+func NotFound(entity string) error {
+	return New(ErrNotFound, "no %s found", entity)
+}
+
+// MultipleResults reports that n entities matched where one was expected,
+// e.g. "expected 1 room, got 3"
+func MultipleResults(entity string, n int) error {
+	return New(ErrMultipleResults, "expected 1 %s, got %d", entity, n)
+}
+
+// FromDB gives database errors a kind: sql.ErrNoRows becomes ErrNotFound,
+// duplicate keys ErrConflict and foreign key failures ErrForeignKey. The
+// message is unchanged. Other errors, errors that already have a kind, and
+// nil are returned as they are.
+func FromDB(err error) error {
+	var kindErr *Error
+	if err == nil || errors.As(err, &kindErr) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Error{Kind: ErrNotFound, Msg: err.Error(), Err: err}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+	switch mysqlErr.Number {
+	case errDuplicateEntry:
+		return &Error{Kind: ErrConflict, Msg: err.Error(), Err: err}
+	case errRowIsReferenced, errNoReferencedRow:
+		return &Error{Kind: ErrForeignKey, Msg: err.Error(), Err: err}
+	}
+	return err
+}
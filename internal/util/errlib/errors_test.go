@@ -0,0 +1,65 @@
+package errlib_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+
+	"mlm/internal/util/errlib"
+)
+
+func TestNotFound(t *testing.T) {
+	err := fmt.Errorf("get user: %w", errlib.NotFound("user"))
+
+	assert.ErrorIs(t, err, errlib.ErrNotFound)
+	assert.NotErrorIs(t, err, errlib.ErrMultipleResults)
+	assert.Equal(t, "get user: no user found", err.Error())
+}
+
+func TestMultipleResults(t *testing.T) {
+	err := errlib.MultipleResults("room", 3)
+
+	assert.ErrorIs(t, err, errlib.ErrMultipleResults)
+	assert.Equal(t, "expected 1 room, got 3", err.Error())
+}
+
+func TestFromDB(t *testing.T) {
+	duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'alice' for key 'users.username'"}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "duplicate-key", err: duplicate, expected: errlib.ErrConflict},
+		{name: "wrapped-duplicate-key", err: fmt.Errorf("insert user: %w", duplicate), expected: errlib.ErrConflict},
+		{name: "row-is-referenced", err: &mysql.MySQLError{Number: 1451}, expected: errlib.ErrForeignKey},
+		{name: "no-referenced-row", err: &mysql.MySQLError{Number: 1452}, expected: errlib.ErrForeignKey},
+		{name: "no-rows", err: sql.ErrNoRows, expected: errlib.ErrNotFound},
+		{name: "other-mysql-error", err: &mysql.MySQLError{Number: 1213}, expected: nil},
+		{name: "other-error", err: errors.New("boom"), expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := errlib.FromDB(tc.err)
+
+			// The message and the original error are always kept
+			assert.Equal(t, tc.err.Error(), err.Error())
+			assert.ErrorIs(t, err, tc.err)
+
+			kinds := []error{errlib.ErrNotFound, errlib.ErrConflict, errlib.ErrForeignKey}
+			for _, kind := range kinds {
+				assert.Equal(t, kind == tc.expected, errors.Is(err, kind), "kind %v", kind)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		assert.NoError(t, errlib.FromDB(nil))
+	})
+}
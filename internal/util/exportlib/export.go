@@ -0,0 +1,108 @@
+// Package exportlib collects a user's personal data for access requests.
+// Each domain registers an Exporter for the tables it owns, usually from an
+// init function in its store package, so a new table is included in every
+// archive as soon as its exporter is registered.
+package exportlib
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// Exporter returns one section of a user's archive. The result must encode
+// to JSON; return nil when the user has nothing in the section.
+type Exporter func(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error)
+
+// Archive is everything held about one user, keyed by section name
+type Archive struct {
+	UserID     string         `json:"user_id"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Sections   map[string]any `json:"sections"`
+}
+
+var (
+	mu        sync.RWMutex
+	exporters = map[string]Exporter{}
+)
+
+// Register adds the exporter for section. It panics when section is empty or
+// already registered, as both are programming errors caught at start-up.
+func Register(section string, exporter Exporter) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if section == "" {
+		panic("exportlib: section name is required")
+	}
+	if exporter == nil {
+		panic(fmt.Sprintf("exportlib: exporter for %q is nil", section))
+	}
+	if _, dup := exporters[section]; dup {
+		panic(fmt.Sprintf("exportlib: section %q registered twice", section))
+	}
+	exporters[section] = exporter
+}
+
+// Sections returns the registered section names in sorted order
+func Sections() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Require returns an error naming every section without a registered
+// exporter. Exporters register from init functions, so a binary that doesn't
+// import a domain's store package silently lacks its section; callers that
+// must not hand out a partial archive check for the sections they expect.
+func Require(sections ...string) error {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var missing []string
+	for _, section := range sections {
+		if _, ok := exporters[section]; !ok {
+			missing = append(missing, section)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("exportlib: no exporter registered for %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Export runs every registered exporter for the user. Sections with no data
+// are included as null so the archive shows they were checked. Callers
+// should pass a transaction to get a consistent snapshot across sections.
+func Export(ctx context.Context, exec boil.ContextExecutor, userID string) (*Archive, error) {
+	archive := &Archive{
+		UserID:     userID,
+		ExportedAt: time.Now().UTC(),
+		Sections:   map[string]any{},
+	}
+
+	for _, section := range Sections() {
+		mu.RLock()
+		exporter := exporters[section]
+		mu.RUnlock()
+
+		data, err := exporter(ctx, exec, userID)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", section, err)
+		}
+		archive.Sections[section] = data
+	}
+
+	return archive, nil
+}
@@ -0,0 +1,103 @@
+package exportlib
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aarondl/sqlboiler/v4/boil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useRegistry swaps in an empty registry for the duration of the test
+func useRegistry(t *testing.T) {
+	saved := exporters
+	exporters = map[string]Exporter{}
+	t.Cleanup(func() { exporters = saved })
+}
+
+func constant(v any) Exporter {
+	return func(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error) {
+		return v, nil
+	}
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("success-sections-sorted", func(t *testing.T) {
+		useRegistry(t)
+
+		Register("profile", constant(nil))
+		Register("memberships", constant(nil))
+
+		assert.Equal(t, []string{"memberships", "profile"}, Sections())
+	})
+
+	t.Run("error-duplicate-section", func(t *testing.T) {
+		useRegistry(t)
+
+		Register("profile", constant(nil))
+		assert.PanicsWithValue(t, `exportlib: section "profile" registered twice`, func() {
+			Register("profile", constant(nil))
+		})
+	})
+
+	t.Run("error-empty-section", func(t *testing.T) {
+		useRegistry(t)
+
+		assert.Panics(t, func() { Register("", constant(nil)) })
+	})
+}
+
+func TestRequire(t *testing.T) {
+	t.Run("success-all-registered", func(t *testing.T) {
+		useRegistry(t)
+
+		Register("profile", constant(nil))
+		Register("votes", constant(nil))
+
+		assert.NoError(t, Require("profile", "votes"))
+	})
+
+	t.Run("error-names-missing-sections", func(t *testing.T) {
+		useRegistry(t)
+
+		Register("profile", constant(nil))
+
+		err := Require("memberships", "profile", "votes")
+		require.Error(t, err)
+		assert.Equal(t, "exportlib: no exporter registered for memberships, votes", err.Error())
+	})
+}
+
+func TestExport(t *testing.T) {
+	t.Run("success-collects-every-section", func(t *testing.T) {
+		useRegistry(t)
+
+		Register("profile", func(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error) {
+			return map[string]string{"id": userID}, nil
+		})
+		Register("votes", constant(nil))
+
+		archive, err := Export(context.Background(), nil, "u1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "u1", archive.UserID)
+		assert.Equal(t, map[string]string{"id": "u1"}, archive.Sections["profile"])
+		assert.Contains(t, archive.Sections, "votes")
+		assert.Nil(t, archive.Sections["votes"])
+	})
+
+	t.Run("error-names-failing-section", func(t *testing.T) {
+		useRegistry(t)
+
+		Register("votes", func(ctx context.Context, exec boil.ContextExecutor, userID string) (any, error) {
+			return nil, errors.New("boom")
+		})
+
+		_, err := Export(context.Background(), nil, "u1")
+
+		require.Error(t, err)
+		assert.Equal(t, "export votes: boom", err.Error())
+	})
+}
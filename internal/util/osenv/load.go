@@ -1,39 +1,98 @@
 package osenv
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 )
 
-// Load loads environment variables from a specified source file.
+// Load loads environment variables from a specified source file,
+// overwriting variables already set in the process.
 func Load(source string) error {
-	file, err := os.Open(source)
+	vars, err := Read(source)
 	if err != nil {
-		return fmt.Errorf("error opening env file: %w", err)
+		return err
 	}
-	defer file.Close()
+	return apply(vars, true)
+}
+
+// LoadNoOverride loads environment variables from a specified source file,
+// keeping any variable already set in the process.
+func LoadNoOverride(source string) error {
+	vars, err := Read(source)
+	if err != nil {
+		return err
+	}
+	return apply(vars, false)
+}
+
+// LoadOptions controls how LoadFiles applies variables to the process
+type LoadOptions struct {
+	// NoOverride keeps variables already set in the process
+	NoOverride bool
+}
+
+// LoadFiles loads several dotenv files in layered order, e.g.
+// .env, .env.local, docker/docker.env: later files override earlier ones and
+// missing files are skipped.
+func LoadFiles(opts LoadOptions, sources ...string) error {
+	vars, err := ReadFiles(sources...)
+	if err != nil {
+		return err
+	}
+	return apply(vars, !opts.NoOverride)
+}
+
+// Read parses a source file into a map without touching the process environment.
+func Read(source string) (map[string]string, error) {
+	return read(source, os.LookupEnv)
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
+// ReadFiles parses several dotenv files in layered order into one map without
+// touching the process environment. Later files override earlier ones and may
+// reference their keys; missing files are skipped.
+func ReadFiles(sources ...string) (map[string]string, error) {
+	vars := make(map[string]string)
+	lookup := func(key string) (string, bool) {
+		if v, ok := vars[key]; ok {
+			return v, true
+		}
+		return os.LookupEnv(key)
+	}
+
+	for _, source := range sources {
+		fileVars, err := read(source, lookup)
+		if errors.Is(err, os.ErrNotExist) {
 			continue
 		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+		if err != nil {
+			return nil, err
+		}
+		for key, val := range fileVars {
+			vars[key] = val
+		}
+	}
+	return vars, nil
+}
+
+func read(source string, lookup func(string) (string, bool)) (map[string]string, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("error opening env file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file, source, lookup)
+}
+
+func apply(vars map[string]string, override bool) error {
+	for key, val := range vars {
+		if _, exists := os.LookupEnv(key); exists && !override {
 			continue
 		}
-		key := strings.TrimSpace(parts[0])
-		val := strings.TrimSpace(parts[1])
-		if err = os.Setenv(key, val); err != nil {
+		if err := os.Setenv(key, val); err != nil {
 			return fmt.Errorf("error setting env variable %s: %w", key, err)
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading env file: %w", err)
-	}
 	return nil
 }
@@ -0,0 +1,174 @@
+package osenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestParse(t *testing.T) {
+	env := map[string]string{"HOME_DIR": "/home/mlm"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{
+			name:     "plain values are trimmed",
+			input:    "FOO = bar \n\n# comment\nEMPTY=\n",
+			expected: map[string]string{"FOO": "bar", "EMPTY": ""},
+		},
+		{
+			name:     "export prefix is ignored",
+			input:    "export FOO=bar\n",
+			expected: map[string]string{"FOO": "bar"},
+		},
+		{
+			name:     "inline comments are stripped from unquoted values",
+			input:    "FOO=bar # trailing\nURL=http://host/#anchor\n",
+			expected: map[string]string{"FOO": "bar", "URL": "http://host/#anchor"},
+		},
+		{
+			name:     "quotes are removed",
+			input:    `SINGLE='a # b'` + "\n" + `DOUBLE="a # b" # comment` + "\n",
+			expected: map[string]string{"SINGLE": "a # b", "DOUBLE": "a # b"},
+		},
+		{
+			name:     "double quotes resolve escapes",
+			input:    `FOO="line1\nline2\t\"quoted\" \\"` + "\n",
+			expected: map[string]string{"FOO": "line1\nline2\t\"quoted\" \\"},
+		},
+		{
+			name:     "multi-line double-quoted values",
+			input:    "CERT=\"-----BEGIN-----\nabc\n-----END-----\"\nNEXT=1\n",
+			expected: map[string]string{"CERT": "-----BEGIN-----\nabc\n-----END-----", "NEXT": "1"},
+		},
+		{
+			name:  "interpolation uses earlier keys, then lookup",
+			input: "USER=mlm\nDSN=${USER}@$HOME_DIR/${MISSING}x\nQUOTED=\"${USER}\"\nLITERAL='${USER}'\nESCAPED=\\$USER\n",
+			expected: map[string]string{
+				"USER":    "mlm",
+				"DSN":     "mlm@/home/mlm/x",
+				"QUOTED":  "mlm",
+				"LITERAL": "${USER}",
+				"ESCAPED": "$USER",
+			},
+		},
+		{
+			name:  "unbraced references stop at a dot",
+			input: "HOST=db\napp.name=mlm\nURL=$HOST.local:${app.name}\n",
+			expected: map[string]string{
+				"HOST":     "db",
+				"app.name": "mlm",
+				"URL":      "db.local:mlm",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vars, err := Parse(strings.NewReader(tc.input), "test.env", lookup)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, vars)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "missing equals",
+			input:    "FOO=bar\nBAR\n",
+			expected: `test.env:2: expected KEY=VALUE, got "BAR"`,
+		},
+		{
+			name:     "invalid key",
+			input:    "\n\n1FOO=bar\n",
+			expected: `test.env:3: invalid key "1FOO"`,
+		},
+		{
+			name:     "unterminated double quote reports opening line",
+			input:    "FOO=bar\nCERT=\"abc\ndef\n",
+			expected: "test.env:2: unterminated double-quoted value",
+		},
+		{
+			name:     "unterminated single quote",
+			input:    "FOO='bar\n",
+			expected: "test.env:1: unterminated single-quoted value",
+		},
+		{
+			name:     "text after closing quote",
+			input:    "FOO=\"bar\" baz\n",
+			expected: `test.env:1: unexpected "baz" after closing quote`,
+		},
+		{
+			name:     "unterminated reference",
+			input:    "FOO=${BAR\n",
+			expected: "test.env:1: unterminated ${ reference",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.input), "test.env", nil)
+			require.Error(t, err)
+
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr))
+			assert.Equal(t, tc.expected, err.Error())
+		})
+	}
+}
+
+func TestLoad_Override(t *testing.T) {
+	path := writeEnvFile(t, t.TempDir(), ".env", "OSENV_TEST_SET=file\nOSENV_TEST_UNSET=file\n")
+	t.Setenv("OSENV_TEST_SET", "process")
+	t.Setenv("OSENV_TEST_UNSET", "")
+	os.Unsetenv("OSENV_TEST_UNSET")
+
+	require.NoError(t, LoadNoOverride(path))
+	assert.Equal(t, "process", os.Getenv("OSENV_TEST_SET"))
+	assert.Equal(t, "file", os.Getenv("OSENV_TEST_UNSET"))
+
+	require.NoError(t, Load(path))
+	assert.Equal(t, "file", os.Getenv("OSENV_TEST_SET"))
+}
+
+func TestReadFiles_Layered(t *testing.T) {
+	dir := t.TempDir()
+	base := writeEnvFile(t, dir, ".env", "A=base\nB=base\nC=base\n")
+	local := writeEnvFile(t, dir, ".env.local", "B=local\nC=${A}-local\n")
+	docker := writeEnvFile(t, dir, "docker.env", "C=docker\n")
+
+	vars, err := ReadFiles(base, local, filepath.Join(dir, "missing.env"), docker)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"A": "base", "B": "local", "C": "docker"}, vars)
+}
+
+func TestRead_Missing(t *testing.T) {
+	_, err := Read(filepath.Join(t.TempDir(), "missing.env"))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,260 @@
+package osenv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseError reports a malformed line in a dotenv source
+type ParseError struct {
+	Source string
+	Line   int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Source, e.Line, e.Msg)
+}
+
+// Parse reads dotenv syntax from r. Supported:
+//
+//	KEY=value              # unquoted, trailing " # comment" stripped
+//	export KEY=value       # leading export is ignored
+//	KEY='literal $value'   # single quotes: no escapes, no interpolation
+//	KEY="a\nb ${OTHER}"    # double quotes: escapes, interpolation, may span lines
+//	KEY=$OTHER/${OTHER}    # references earlier keys, then lookup
+//
+// lookup resolves references to keys not defined earlier in r; it may be nil.
+func Parse(r io.Reader, source string, lookup func(string) (string, bool)) (map[string]string, error) {
+	p := &parser{
+		source: source,
+		scan:   bufio.NewScanner(r),
+		vars:   make(map[string]string),
+		lookup: lookup,
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.vars, nil
+}
+
+type parser struct {
+	source string
+	scan   *bufio.Scanner
+	line   int
+	vars   map[string]string
+	lookup func(string) (string, bool)
+}
+
+func (p *parser) errorf(line int, format string, args ...any) error {
+	return &ParseError{Source: p.source, Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) next() (string, bool) {
+	if !p.scan.Scan() {
+		return "", false
+	}
+	p.line++
+	return p.scan.Text(), true
+}
+
+func (p *parser) parse() error {
+	for {
+		raw, ok := p.next()
+		if !ok {
+			break
+		}
+
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "export "); ok {
+			line = strings.TrimSpace(rest)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return p.errorf(p.line, "expected KEY=VALUE, got %q", line)
+		}
+		key = strings.TrimSpace(key)
+		if !isValidKey(key) {
+			return p.errorf(p.line, "invalid key %q", key)
+		}
+
+		val, err := p.value(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		p.vars[key] = val
+	}
+
+	if err := p.scan.Err(); err != nil {
+		return fmt.Errorf("error reading %s: %w", p.source, err)
+	}
+	return nil
+}
+
+// value parses the right-hand side of an assignment starting on the current line
+func (p *parser) value(raw string) (string, error) {
+	start := p.line
+
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", p.errorf(start, "unterminated single-quoted value")
+		}
+		if err := p.checkTrailing(raw[end+2:]); err != nil {
+			return "", err
+		}
+		return raw[1 : end+1], nil
+
+	case strings.HasPrefix(raw, `"`):
+		body := raw[1:]
+		for {
+			if end := closingQuote(body); end >= 0 {
+				if err := p.checkTrailing(body[end+1:]); err != nil {
+					return "", err
+				}
+				return p.expand(body[:end], start, true)
+			}
+			next, ok := p.next()
+			if !ok {
+				return "", p.errorf(start, "unterminated double-quoted value")
+			}
+			body += "\n" + next
+		}
+
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		} else if i := strings.Index(raw, "\t#"); i >= 0 {
+			raw = raw[:i]
+		}
+		return p.expand(strings.TrimSpace(raw), start, false)
+	}
+}
+
+// checkTrailing rejects anything but a comment after a closing quote
+func (p *parser) checkTrailing(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return p.errorf(p.line, "unexpected %q after closing quote", rest)
+	}
+	return nil
+}
+
+// expand replaces $KEY and ${KEY} references. With escapes set (double-quoted
+// values) it also resolves \n, \r, \t, \" and \\; \$ is always a literal dollar.
+func (p *parser) expand(s string, line int, escapes bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && (escapes || s[i+1] == '$') {
+			i++
+			b.WriteString(unescape(s[i]))
+			continue
+		}
+		if c != '$' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+
+		var name string
+		if s[i+1] == '{' {
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", p.errorf(line, "unterminated ${ reference")
+			}
+			name = s[i+2 : i+2+end]
+			if !isValidKey(name) {
+				return "", p.errorf(line, "invalid reference ${%s}", name)
+			}
+			i += end + 2
+		} else {
+			j := i + 1
+			for j < len(s) && isRefChar(s[j], j == i+1) {
+				j++
+			}
+			if j == i+1 {
+				b.WriteByte(c)
+				continue
+			}
+			name = s[i+1 : j]
+			i = j - 1
+		}
+
+		b.WriteString(p.resolve(name))
+	}
+	return b.String(), nil
+}
+
+func (p *parser) resolve(name string) string {
+	if v, ok := p.vars[name]; ok {
+		return v
+	}
+	if p.lookup != nil {
+		if v, ok := p.lookup(name); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// closingQuote returns the index of the first unescaped double quote in s
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape returns the character a backslash escape stands for
+func unescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	}
+	return string(c)
+}
+
+func isValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if !isKeyChar(key[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isKeyChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return true
+	case c >= '0' && c <= '9', c == '.':
+		return !first
+	}
+	return false
+}
+
+// isRefChar is isKeyChar for unbraced $KEY references, which stop at '.' as
+// in POSIX shells and compose: $HOST.local is $HOST followed by ".local".
+// Keys containing '.' can still be referenced as ${KEY}.
+func isRefChar(c byte, first bool) bool {
+	return c != '.' && isKeyChar(c, first)
+}
@@ -0,0 +1,289 @@
+package querylib
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+
+	"mlm/internal/util/validationlib"
+)
+
+// DefaultPageSize is used by page queries when the filter has no Limit
+const DefaultPageSize = 50
+
+// PageSize returns the number of rows a page query should return
+func PageSize(limit null.Int) int {
+	if !limit.Valid || limit.Int <= 0 {
+		return DefaultPageSize
+	}
+	return limit.Int
+}
+
+// Keyset is a validated sort order that can also page through results by
+// cursor: rows after a cursor are selected with a WHERE on the sort columns
+// instead of an OFFSET, so pages stay stable while rows are inserted.
+//
+// Sort columns are expected to be NOT NULL; a NULL sort value would never
+// compare as "after" the cursor.
+type Keyset struct {
+	keys []sortKey
+}
+
+// NewKeyset validates sorts against the whitelisted columns and appends
+// idColumn as the final, unique tiebreak
+func NewKeyset[F ~string](sorts []Sort[F], columns map[F]string, idColumn string) (*Keyset, error) {
+	keys, err := resolveSorts(sorts, columns, idColumn)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyset{keys: keys}, nil
+}
+
+// Columns returns the columns a cursor holds values for, in order
+func (k *Keyset) Columns() []string {
+	columns := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		columns[i] = key.column
+	}
+	return columns
+}
+
+// OrderBy returns the ORDER BY mod for the keyset
+func (k *Keyset) OrderBy() qm.QueryMod {
+	return qm.OrderBy(orderByClause(k.keys))
+}
+
+// After returns a WHERE mod selecting rows that sort after cursor. A cursor
+// that is malformed or was issued for a different sort order is a
+// *validationlib.ValidationError.
+func (k *Keyset) After(cursor string) (qm.QueryMod, error) {
+	values, err := k.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	clause, args := k.afterClause(values)
+	return qm.Where(clause, args...), nil
+}
+
+// Values decodes cursor into its sort values, in Columns() order. It is
+// After for callers that filter rows themselves, e.g. an in-memory store, and
+// rejects the same cursors.
+func (k *Keyset) Values(cursor string) ([]interface{}, error) {
+	return k.decode(cursor)
+}
+
+// Compare orders two rows by their sort values, given in Columns() order, the
+// way OrderBy sorts them: negative if a comes first, positive if b does and 0
+// if they tie on every key. Strings compare case-insensitively, like the
+// tables' default _ci collation.
+func (k *Keyset) Compare(a, b []interface{}) int {
+	for i, key := range k.keys {
+		c := compareValues(a[i], b[i])
+		if key.direction == SortDesc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// afterClause expands the keyset comparison into plain predicates, which
+// works for mixed directions: (a > ?) OR (a = ? AND b > ?) OR ...
+func (k *Keyset) afterClause(values []interface{}) (string, []interface{}) {
+	var (
+		terms []string
+		args  []interface{}
+	)
+	for i, key := range k.keys {
+		op := ">"
+		if key.direction == SortDesc {
+			op = "<"
+		}
+
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, k.keys[j].column+" = ?")
+			args = append(args, values[j])
+		}
+		parts = append(parts, key.column+" "+op+" ?")
+		args = append(args, values[i])
+
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+// Cursor encodes the sort values of the last row of a page. values must be
+// given in Columns() order.
+func (k *Keyset) Cursor(values ...interface{}) (string, error) {
+	if len(values) != len(k.keys) {
+		return "", fmt.Errorf("cursor needs %d values, got %d", len(k.keys), len(values))
+	}
+
+	payload := cursorPayload{
+		Order:  orderByClause(k.keys),
+		Values: make([]cursorValue, len(values)),
+	}
+	for i, v := range values {
+		cv, err := encodeCursorValue(v)
+		if err != nil {
+			return "", fmt.Errorf("cursor value for %s: %w", k.keys[i].column, err)
+		}
+		payload.Values[i] = cv
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// cursorPayload is the JSON behind an opaque cursor. Order pins the cursor to
+// the sort order it was issued for.
+type cursorPayload struct {
+	Order  string        `json:"o"`
+	Values []cursorValue `json:"v"`
+}
+
+// cursorValue keeps the Go type of a sort value so it is bound back into the
+// query as the same type, e.g. a time.Time rather than a string
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+const (
+	cursorString = "s"
+	cursorInt    = "i"
+	cursorUint   = "u"
+	cursorTime   = "t"
+	cursorBool   = "b"
+)
+
+func encodeCursorValue(v interface{}) (cursorValue, error) {
+	switch val := v.(type) {
+	case string:
+		return cursorValue{Type: cursorString, Value: val}, nil
+	case int:
+		return cursorValue{Type: cursorInt, Value: strconv.FormatInt(int64(val), 10)}, nil
+	case int64:
+		return cursorValue{Type: cursorInt, Value: strconv.FormatInt(val, 10)}, nil
+	case uint64:
+		return cursorValue{Type: cursorUint, Value: strconv.FormatUint(val, 10)}, nil
+	case bool:
+		return cursorValue{Type: cursorBool, Value: strconv.FormatBool(val)}, nil
+	case time.Time:
+		return cursorValue{Type: cursorTime, Value: val.UTC().Format(time.RFC3339Nano)}, nil
+	case null.Time:
+		if !val.Valid {
+			return cursorValue{}, fmt.Errorf("NULL values cannot be used in a cursor")
+		}
+		return encodeCursorValue(val.Time)
+	}
+	return cursorValue{}, fmt.Errorf("unsupported type %T", v)
+}
+
+func decodeCursorValue(cv cursorValue) (interface{}, error) {
+	switch cv.Type {
+	case cursorString:
+		return cv.Value, nil
+	case cursorInt:
+		return strconv.ParseInt(cv.Value, 10, 64)
+	case cursorUint:
+		return strconv.ParseUint(cv.Value, 10, 64)
+	case cursorBool:
+		return strconv.ParseBool(cv.Value)
+	case cursorTime:
+		return time.Parse(time.RFC3339Nano, cv.Value)
+	}
+	return nil, fmt.Errorf("unknown value type %q", cv.Type)
+}
+
+// decode unpacks a cursor into typed values in key order
+func (k *Keyset) decode(cursor string) ([]interface{}, error) {
+	invalid := func(reason string) error {
+		return validationlib.NewValidationError("cursor", cursor, reason)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid("malformed cursor")
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, invalid("malformed cursor")
+	}
+	if payload.Order != orderByClause(k.keys) || len(payload.Values) != len(k.keys) {
+		return nil, invalid("cursor was issued for a different sort order")
+	}
+
+	values := make([]interface{}, len(payload.Values))
+	for i, cv := range payload.Values {
+		v, err := decodeCursorValue(cv)
+		if err != nil {
+			return nil, invalid("malformed cursor")
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// compareValues compares two sort values of the same column. A NULL time
+// sorts first, as MySQL sorts NULLs in ascending order.
+func compareValues(a, b interface{}) int {
+	a, b = normalizeValue(a), normalizeValue(b)
+
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return cmp.Compare(strings.ToLower(av), strings.ToLower(bv))
+		}
+	case int64:
+		if bv, ok := b.(int64); ok {
+			return cmp.Compare(av, bv)
+		}
+	case uint64:
+		if bv, ok := b.(uint64); ok {
+			return cmp.Compare(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// normalizeValue converts v to the type a cursor decodes it as
+func normalizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int:
+		return int64(val)
+	case null.Time:
+		return val.Time
+	}
+	return v
+}
@@ -0,0 +1,116 @@
+package querylib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aarondl/null/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/util/validationlib"
+)
+
+func TestKeyset_CursorRoundTrip(t *testing.T) {
+	keyset, err := NewKeyset([]Sort[testField]{
+		{Field: "created_at", Direction: SortDesc},
+		{Field: "name"},
+	}, testColumns, "id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"created_at", "name", "id"}, keyset.Columns())
+
+	createdAt := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	cursor, err := keyset.Cursor(createdAt, "lobby", uint64(42))
+	require.NoError(t, err)
+
+	values, err := keyset.decode(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{createdAt, "lobby", uint64(42)}, values)
+
+	clause, args := keyset.afterClause(values)
+	assert.Equal(t,
+		"((created_at < ?) OR (created_at = ? AND name > ?) OR (created_at = ? AND name = ? AND id > ?))",
+		clause)
+	assert.Equal(t, []interface{}{createdAt, createdAt, "lobby", createdAt, "lobby", uint64(42)}, args)
+}
+
+func TestKeyset_RejectsBadCursors(t *testing.T) {
+	byName, err := NewKeyset([]Sort[testField]{{Field: "name"}}, testColumns, "id")
+	require.NoError(t, err)
+	byID, err := NewKeyset[testField](nil, testColumns, "id")
+	require.NoError(t, err)
+
+	cursor, err := byID.Cursor(uint64(7))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		cursor   string
+		expected string
+	}{
+		{name: "not base64", cursor: "%%%", expected: "malformed cursor"},
+		{name: "not json", cursor: "bm90IGpzb24", expected: "malformed cursor"},
+		{name: "different sort order", cursor: cursor, expected: "different sort order"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := byName.After(tc.cursor)
+			require.Error(t, err)
+			assert.True(t, validationlib.IsValidationError(err))
+			assert.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestKeyset_CursorNeedsOneValuePerColumn(t *testing.T) {
+	keyset, err := NewKeyset([]Sort[testField]{{Field: "name"}}, testColumns, "id")
+	require.NoError(t, err)
+
+	_, err = keyset.Cursor("lobby")
+	require.Error(t, err)
+}
+
+func TestKeyset_Compare(t *testing.T) {
+	keyset, err := NewKeyset([]Sort[testField]{
+		{Field: "created_at", Direction: SortDesc},
+		{Field: "name"},
+	}, testColumns, "id")
+	require.NoError(t, err)
+
+	earlier := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	testCases := []struct {
+		name     string
+		a, b     []interface{}
+		expected int
+	}{
+		{name: "descending first key", a: []interface{}{later, "b", "1"}, b: []interface{}{earlier, "a", "1"}, expected: -1},
+		{name: "ascending second key", a: []interface{}{earlier, "b", "1"}, b: []interface{}{earlier, "a", "1"}, expected: 1},
+		{name: "strings ignore case", a: []interface{}{earlier, "Lobby", "1"}, b: []interface{}{earlier, "lobby", "1"}, expected: 0},
+		{name: "id tiebreak", a: []interface{}{earlier, "lobby", "1"}, b: []interface{}{earlier, "lobby", "2"}, expected: -1},
+		{name: "null time matches time", a: []interface{}{null.TimeFrom(earlier), "a", "1"}, b: []interface{}{earlier, "a", "1"}, expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, keyset.Compare(tc.a, tc.b))
+		})
+	}
+}
+
+func TestKeyset_ValuesMatchCursor(t *testing.T) {
+	keyset, err := NewKeyset([]Sort[testField]{{Field: "name"}}, testColumns, "id")
+	require.NoError(t, err)
+
+	cursor, err := keyset.Cursor("lobby", "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11")
+	require.NoError(t, err)
+
+	values, err := keyset.Values(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, 0, keyset.Compare(values, []interface{}{"Lobby", "0b6f4a3e-5d1c-4a5e-9a8b-2f1e7c9d0a11"}))
+
+	_, err = keyset.Values("%%%")
+	assert.True(t, validationlib.IsValidationError(err))
+}
@@ -0,0 +1,51 @@
+package querylib
+
+import (
+	"strings"
+
+	"github.com/aarondl/null/v8"
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+)
+
+// likeEscape is the LIKE escape character. '!' is used rather than a
+// backslash so patterns behave the same under NO_BACKSLASH_ESCAPES.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(
+	likeEscape, likeEscape+likeEscape,
+	"%", likeEscape+"%",
+	"_", likeEscape+"_",
+)
+
+// EscapeLike escapes LIKE wildcards in s so it matches literally
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// WhereStartsWith matches column values beginning with prefix, ignoring case
+func WhereStartsWith(column, prefix string) qm.QueryMod {
+	return whereLike(column, EscapeLike(strings.ToLower(prefix))+"%")
+}
+
+// WhereContains matches column values containing substr, ignoring case
+func WhereContains(column, substr string) qm.QueryMod {
+	return whereLike(column, "%"+EscapeLike(strings.ToLower(substr))+"%")
+}
+
+func whereLike(column, pattern string) qm.QueryMod {
+	return qm.Where("LOWER("+column+") LIKE ? ESCAPE '"+likeEscape+"'", pattern)
+}
+
+// WhereTimeRange restricts column to the half-open range [from, to). Either
+// bound may be unset; half-open ranges let consecutive windows share a bound
+// without double counting.
+func WhereTimeRange(column string, from, to null.Time) []qm.QueryMod {
+	var mods []qm.QueryMod
+	if from.Valid {
+		mods = append(mods, qm.Where(column+" >= ?", from.Time))
+	}
+	if to.Valid {
+		mods = append(mods, qm.Where(column+" < ?", to.Time))
+	}
+	return mods
+}
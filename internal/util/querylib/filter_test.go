@@ -0,0 +1,26 @@
+package querylib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeLike(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "alice", expected: "alice"},
+		{input: "100%", expected: "100!%"},
+		{input: "snake_case", expected: "snake!_case"},
+		{input: "wow!", expected: "wow!!"},
+		{input: "!%_", expected: "!!!%!_"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.expected, EscapeLike(tc.input))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package querylib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aarondl/sqlboiler/v4/queries/qm"
+
+	"mlm/internal/util/validationlib"
+)
+
+// SortDirection - ascending or descending order
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// Valid reports whether d is a known direction. The zero value means SortAsc.
+func (d SortDirection) Valid() bool {
+	switch d {
+	case "", SortAsc, SortDesc:
+		return true
+	}
+	return false
+}
+
+// SQL returns the ORDER BY keyword for d
+func (d SortDirection) SQL() string {
+	if d == SortDesc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// Sort orders results by one field of an entity. F is the entity's enum of
+// sortable fields, e.g. users.UserSortField.
+type Sort[F ~string] struct {
+	Field     F
+	Direction SortDirection
+}
+
+// ParseSorts parses "field" or "field:dir" terms, e.g. from
+// ?sort=created_at:desc,username. Fields and directions are not validated
+// here; OrderBy rejects unknown values.
+func ParseSorts[F ~string](terms []string) []Sort[F] {
+	sorts := make([]Sort[F], 0, len(terms))
+	for _, term := range terms {
+		field, dir, _ := strings.Cut(strings.TrimSpace(term), ":")
+		sorts = append(sorts, Sort[F]{
+			Field:     F(strings.TrimSpace(field)),
+			Direction: SortDirection(strings.ToLower(strings.TrimSpace(dir))),
+		})
+	}
+	return sorts
+}
+
+// OrderBy builds an ORDER BY mod from sorts. columns whitelists the sortable
+// fields of the entity; anything else is a *validationlib.ValidationError.
+// idColumn is appended as a final tiebreak so the order is always stable.
+func OrderBy[F ~string](sorts []Sort[F], columns map[F]string, idColumn string) (qm.QueryMod, error) {
+	keyset, err := NewKeyset(sorts, columns, idColumn)
+	if err != nil {
+		return nil, err
+	}
+	return keyset.OrderBy(), nil
+}
+
+// sortKey is a validated sort term resolved to its column
+type sortKey struct {
+	column    string
+	direction SortDirection
+}
+
+// resolveSorts validates sorts against columns and appends the id tiebreak
+func resolveSorts[F ~string](sorts []Sort[F], columns map[F]string, idColumn string) ([]sortKey, error) {
+	keys := make([]sortKey, 0, len(sorts)+1)
+	sortedByID := false
+
+	for _, s := range sorts {
+		column, ok := columns[s.Field]
+		if !ok {
+			return nil, validationlib.NewValidationError("sort field", string(s.Field),
+				fmt.Sprintf("must be one of %s", strings.Join(fieldNames(columns), ", ")))
+		}
+		if !s.Direction.Valid() {
+			return nil, validationlib.NewValidationError("sort direction", string(s.Direction),
+				"must be asc or desc")
+		}
+
+		keys = append(keys, sortKey{column: column, direction: s.Direction})
+		if column == idColumn {
+			sortedByID = true
+			break // id is unique, later terms can never apply
+		}
+	}
+
+	if !sortedByID {
+		keys = append(keys, sortKey{column: idColumn, direction: SortAsc})
+	}
+
+	return keys, nil
+}
+
+// orderByClause renders keys as an ORDER BY list, e.g. "name ASC, id ASC"
+func orderByClause(keys []sortKey) string {
+	clauses := make([]string, len(keys))
+	for i, k := range keys {
+		clauses[i] = k.column + " " + k.direction.SQL()
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// fieldNames returns the whitelisted field names in a stable order
+func fieldNames[F ~string](columns map[F]string) []string {
+	names := make([]string, 0, len(columns))
+	for field := range columns {
+		names = append(names, string(field))
+	}
+	sort.Strings(names)
+	return names
+}
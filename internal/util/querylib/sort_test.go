@@ -0,0 +1,80 @@
+package querylib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mlm/internal/util/validationlib"
+)
+
+type testField string
+
+var testColumns = map[testField]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+}
+
+func TestOrderBy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sorts    []Sort[testField]
+		expected string
+	}{
+		{
+			name:     "no sorts orders by id",
+			sorts:    nil,
+			expected: "id ASC",
+		},
+		{
+			name:     "default direction is ascending",
+			sorts:    []Sort[testField]{{Field: "name"}},
+			expected: "name ASC, id ASC",
+		},
+		{
+			name: "multi-column sort with tiebreak",
+			sorts: []Sort[testField]{
+				{Field: "created_at", Direction: SortDesc},
+				{Field: "name", Direction: SortAsc},
+			},
+			expected: "created_at DESC, name ASC, id ASC",
+		},
+		{
+			name:     "explicit id sort is not repeated",
+			sorts:    []Sort[testField]{{Field: "id", Direction: SortDesc}},
+			expected: "id DESC",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keyset, err := NewKeyset(tc.sorts, testColumns, "id")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, orderByClause(keyset.keys))
+		})
+	}
+}
+
+func TestOrderBy_RejectsUnknownValues(t *testing.T) {
+	_, err := OrderBy([]Sort[testField]{{Field: "name; DROP TABLE users"}}, testColumns, "id")
+	require.Error(t, err)
+	assert.True(t, validationlib.IsValidationError(err))
+	assert.Contains(t, err.Error(), "must be one of created_at, id, name")
+
+	_, err = OrderBy([]Sort[testField]{{Field: "name", Direction: "sideways"}}, testColumns, "id")
+	require.Error(t, err)
+	assert.True(t, validationlib.IsValidationError(err))
+	assert.Contains(t, err.Error(), `invalid sort direction "sideways"`)
+}
+
+func TestParseSorts(t *testing.T) {
+	sorts := ParseSorts[testField]([]string{"created_at:DESC", " name ", "id:asc"})
+
+	assert.Equal(t, []Sort[testField]{
+		{Field: "created_at", Direction: SortDesc},
+		{Field: "name"},
+		{Field: "id", Direction: SortAsc},
+	}, sorts)
+}
@@ -1 +1,29 @@
 package validationlib
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationError reports a caller-supplied value that was rejected before
+// it reached the database
+type ValidationError struct {
+	Field  string
+	Value  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// NewValidationError creates a ValidationError for field
+func NewValidationError(field, value, reason string) *ValidationError {
+	return &ValidationError{Field: field, Value: value, Reason: reason}
+}
+
+// IsValidationError reports whether err is or wraps a ValidationError
+func IsValidationError(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
@@ -0,0 +1,11 @@
+ALTER TABLE room_members
+    DROP COLUMN uuid,
+    DROP COLUMN room_id_uuid,
+    DROP COLUMN user_id_uuid;
+
+ALTER TABLE rooms
+    DROP COLUMN uuid,
+    DROP COLUMN created_by_uuid;
+
+ALTER TABLE users
+    DROP COLUMN uuid;
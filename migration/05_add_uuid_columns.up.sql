@@ -0,0 +1,15 @@
+-- UUID primary keys, step 1 of 3: add nullable UUID columns next to every
+-- integer key and foreign key. Steps 06 (backfill) and 07 (swap keys) are
+-- separate migrations so a failure resumes at the step that broke: MySQL
+-- DDL is not transactional.
+ALTER TABLE users
+    ADD COLUMN uuid CHAR(36) NULL AFTER id;
+
+ALTER TABLE rooms
+    ADD COLUMN uuid CHAR(36) NULL AFTER id,
+    ADD COLUMN created_by_uuid CHAR(36) NULL AFTER created_by;
+
+ALTER TABLE room_members
+    ADD COLUMN uuid CHAR(36) NULL AFTER id,
+    ADD COLUMN room_id_uuid CHAR(36) NULL AFTER room_id,
+    ADD COLUMN user_id_uuid CHAR(36) NULL AFTER user_id;
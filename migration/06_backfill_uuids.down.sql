@@ -0,0 +1,3 @@
+UPDATE room_members SET uuid = NULL, room_id_uuid = NULL, user_id_uuid = NULL;
+UPDATE rooms SET uuid = NULL, created_by_uuid = NULL;
+UPDATE users SET uuid = NULL;
@@ -0,0 +1,15 @@
+-- UUID primary keys, step 2 of 3: give every existing row a UUID and copy
+-- the parent's UUID into each foreign key column. Safe to re-run.
+UPDATE users SET uuid = UUID() WHERE uuid IS NULL;
+UPDATE rooms SET uuid = UUID() WHERE uuid IS NULL;
+UPDATE room_members SET uuid = UUID() WHERE uuid IS NULL;
+
+UPDATE rooms r
+    JOIN users u ON u.id = r.created_by
+SET r.created_by_uuid = u.uuid;
+
+UPDATE room_members m
+    JOIN rooms r ON r.id = m.room_id
+    JOIN users u ON u.id = m.user_id
+SET m.room_id_uuid = r.uuid,
+    m.user_id_uuid = u.uuid;
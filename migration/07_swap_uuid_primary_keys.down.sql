@@ -0,0 +1,66 @@
+-- Restore integer keys. Rows are renumbered; the UUIDs are kept in the side
+-- columns added by 05 so 07 can be re-applied without losing them.
+
+ALTER TABLE room_members
+    DROP FOREIGN KEY fk_room_members_room,
+    DROP FOREIGN KEY fk_room_members_user;
+ALTER TABLE rooms
+    DROP FOREIGN KEY fk_rooms_created_by;
+ALTER TABLE room_members
+    DROP INDEX uniq_active_member,
+    DROP INDEX idx_room_members_user;
+ALTER TABLE rooms
+    DROP INDEX idx_rooms_created_by;
+
+-- users
+ALTER TABLE users
+    DROP PRIMARY KEY,
+    CHANGE COLUMN id uuid CHAR(36) NULL;
+ALTER TABLE users
+    ADD COLUMN id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY FIRST;
+
+-- rooms
+ALTER TABLE rooms
+    DROP PRIMARY KEY,
+    CHANGE COLUMN id uuid CHAR(36) NULL,
+    CHANGE COLUMN created_by created_by_uuid CHAR(36) NULL;
+ALTER TABLE rooms
+    ADD COLUMN id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY FIRST,
+    ADD COLUMN created_by BIGINT UNSIGNED NULL AFTER name;
+UPDATE rooms r
+    JOIN users u ON u.uuid = r.created_by_uuid
+SET r.created_by = u.id;
+ALTER TABLE rooms
+    MODIFY created_by BIGINT UNSIGNED NOT NULL;
+
+-- room_members
+ALTER TABLE room_members
+    DROP PRIMARY KEY,
+    CHANGE COLUMN id uuid CHAR(36) NULL,
+    CHANGE COLUMN room_id room_id_uuid CHAR(36) NULL,
+    CHANGE COLUMN user_id user_id_uuid CHAR(36) NULL;
+ALTER TABLE room_members
+    ADD COLUMN id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY FIRST,
+    ADD COLUMN room_id BIGINT UNSIGNED NULL AFTER id,
+    ADD COLUMN user_id BIGINT UNSIGNED NULL AFTER room_id;
+UPDATE room_members m
+    JOIN rooms r ON r.uuid = m.room_id_uuid
+    JOIN users u ON u.uuid = m.user_id_uuid
+SET m.room_id = r.id,
+    m.user_id = u.id;
+ALTER TABLE room_members
+    MODIFY room_id BIGINT UNSIGNED NOT NULL,
+    MODIFY user_id BIGINT UNSIGNED NOT NULL,
+    ADD UNIQUE KEY uniq_active_member (room_id, user_id, left_at);
+
+ALTER TABLE rooms
+    ADD CONSTRAINT fk_rooms_created_by
+        FOREIGN KEY (created_by) REFERENCES users(id)
+            ON DELETE RESTRICT;
+ALTER TABLE room_members
+    ADD CONSTRAINT fk_room_members_room
+        FOREIGN KEY (room_id) REFERENCES rooms(id)
+            ON DELETE CASCADE,
+    ADD CONSTRAINT fk_room_members_user
+        FOREIGN KEY (user_id) REFERENCES users(id)
+            ON DELETE CASCADE;
@@ -0,0 +1,63 @@
+-- UUID primary keys, step 3 of 3: drop the integer keys and promote the UUID
+-- columns to primary and foreign keys under the original names.
+
+-- Foreign keys must go before the keys they reference can change
+ALTER TABLE room_members
+    DROP FOREIGN KEY fk_room_members_room,
+    DROP FOREIGN KEY fk_room_members_user;
+ALTER TABLE rooms
+    DROP FOREIGN KEY fk_rooms_created_by;
+ALTER TABLE room_members
+    DROP INDEX uniq_active_member;
+
+-- users
+ALTER TABLE users
+    MODIFY id BIGINT UNSIGNED NOT NULL,
+    DROP PRIMARY KEY;
+ALTER TABLE users
+    DROP COLUMN id;
+ALTER TABLE users
+    CHANGE COLUMN uuid id CHAR(36) NOT NULL FIRST,
+    ADD PRIMARY KEY (id);
+
+-- rooms
+ALTER TABLE rooms
+    MODIFY id BIGINT UNSIGNED NOT NULL,
+    DROP PRIMARY KEY;
+ALTER TABLE rooms
+    DROP COLUMN id,
+    DROP COLUMN created_by;
+ALTER TABLE rooms
+    CHANGE COLUMN uuid id CHAR(36) NOT NULL FIRST,
+    CHANGE COLUMN created_by_uuid created_by CHAR(36) NOT NULL AFTER name,
+    ADD PRIMARY KEY (id),
+    ADD INDEX idx_rooms_created_by (created_by);
+
+-- room_members
+ALTER TABLE room_members
+    MODIFY id BIGINT UNSIGNED NOT NULL,
+    DROP PRIMARY KEY;
+ALTER TABLE room_members
+    DROP COLUMN id,
+    DROP COLUMN room_id,
+    DROP COLUMN user_id;
+ALTER TABLE room_members
+    CHANGE COLUMN uuid id CHAR(36) NOT NULL FIRST,
+    CHANGE COLUMN room_id_uuid room_id CHAR(36) NOT NULL AFTER id,
+    CHANGE COLUMN user_id_uuid user_id CHAR(36) NOT NULL AFTER room_id,
+    ADD PRIMARY KEY (id),
+    ADD UNIQUE KEY uniq_active_member (room_id, user_id, left_at),
+    ADD INDEX idx_room_members_user (user_id);
+
+-- Foreign keys, now on the UUID columns
+ALTER TABLE rooms
+    ADD CONSTRAINT fk_rooms_created_by
+        FOREIGN KEY (created_by) REFERENCES users(id)
+            ON DELETE RESTRICT;
+ALTER TABLE room_members
+    ADD CONSTRAINT fk_room_members_room
+        FOREIGN KEY (room_id) REFERENCES rooms(id)
+            ON DELETE CASCADE,
+    ADD CONSTRAINT fk_room_members_user
+        FOREIGN KEY (user_id) REFERENCES users(id)
+            ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- The original casing of emails isn't kept, so there is nothing to restore.
+DO 0;
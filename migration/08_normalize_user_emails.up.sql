@@ -0,0 +1,5 @@
+-- Emails are stored trimmed and lower-cased so the UNIQUE index on email
+-- enforces case-insensitive uniqueness whatever the column collation.
+-- This fails if two users' emails differ only by case; merge those first.
+UPDATE users SET email = NULL WHERE TRIM(email) = '';
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email IS NOT NULL;
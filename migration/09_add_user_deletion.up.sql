@@ -0,0 +1,7 @@
+-- deleted_at marks a soft-deleted account; the user store hides these rows
+-- unless asked to include them. erased_at records when personal data was
+-- anonymized by the erase workflow.
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN erased_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_users_deleted_at (deleted_at);
@@ -0,0 +1,6 @@
+-- Fails if two former memberships of the same room and user ended at the
+-- same second, which uniq_active_member rejects
+ALTER TABLE room_members
+    ADD UNIQUE KEY uniq_active_member (room_id, user_id, left_at),
+    DROP INDEX uniq_active_membership,
+    DROP COLUMN active_key;
@@ -0,0 +1,24 @@
+-- A user can hold at most one active membership of a room. left_at is NULL
+-- on active rows and MySQL treats NULLs as distinct, so uniq_active_member
+-- (room_id, user_id, left_at) never enforced that. active_key is 1 while the
+-- membership is active and NULL once it ends, so a unique key on it only
+-- collides for two active rows.
+ALTER TABLE room_members
+    ADD COLUMN active_key TINYINT GENERATED ALWAYS AS (IF(left_at IS NULL, 1, NULL)) STORED,
+    ADD INDEX idx_room_members_room_user (room_id, user_id),
+    DROP INDEX uniq_active_member;
+
+-- Close duplicate active memberships left by concurrent joins, keeping the
+-- earliest of each
+UPDATE room_members m
+    JOIN room_members keep
+        ON keep.room_id = m.room_id
+        AND keep.user_id = m.user_id
+        AND keep.left_at IS NULL
+        AND (keep.joined_at < m.joined_at OR (keep.joined_at = m.joined_at AND keep.id < m.id))
+SET m.left_at = m.joined_at
+WHERE m.left_at IS NULL;
+
+ALTER TABLE room_members
+    ADD UNIQUE KEY uniq_active_membership (room_id, user_id, active_key),
+    DROP INDEX idx_room_members_room_user;
@@ -0,0 +1,10 @@
+// Package migration embeds the SQL migration files so the mlm binary can
+// run them from any working directory.
+package migration
+
+import "embed"
+
+// FS holds every NN_name.up.sql / NN_name.down.sql file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -24,9 +24,9 @@ import (
 
 // RoomMember is an object representing the database table.
 type RoomMember struct {
-	ID       uint64    `boil:"id" json:"id" toml:"id" yaml:"id"`
-	RoomID   uint64    `boil:"room_id" json:"room_id" toml:"room_id" yaml:"room_id"`
-	UserID   uint64    `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
+	ID       string    `boil:"id" json:"id" toml:"id" yaml:"id"`
+	RoomID   string    `boil:"room_id" json:"room_id" toml:"room_id" yaml:"room_id"`
+	UserID   string    `boil:"user_id" json:"user_id" toml:"user_id" yaml:"user_id"`
 	JoinedAt null.Time `boil:"joined_at" json:"joined_at,omitempty" toml:"joined_at" yaml:"joined_at,omitempty"`
 	LeftAt   null.Time `boil:"left_at" json:"left_at,omitempty" toml:"left_at" yaml:"left_at,omitempty"`
 
@@ -64,22 +64,24 @@ var RoomMemberTableColumns = struct {
 
 // Generated where
 
-type whereHelperuint64 struct{ field string }
-
-func (w whereHelperuint64) EQ(x uint64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.EQ, x) }
-func (w whereHelperuint64) NEQ(x uint64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
-func (w whereHelperuint64) LT(x uint64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.LT, x) }
-func (w whereHelperuint64) LTE(x uint64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.LTE, x) }
-func (w whereHelperuint64) GT(x uint64) qm.QueryMod  { return qmhelper.Where(w.field, qmhelper.GT, x) }
-func (w whereHelperuint64) GTE(x uint64) qm.QueryMod { return qmhelper.Where(w.field, qmhelper.GTE, x) }
-func (w whereHelperuint64) IN(slice []uint64) qm.QueryMod {
+type whereHelperstring struct{ field string }
+
+func (w whereHelperstring) EQ(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.EQ, x) }
+func (w whereHelperstring) NEQ(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
+func (w whereHelperstring) LT(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.LT, x) }
+func (w whereHelperstring) LTE(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.LTE, x) }
+func (w whereHelperstring) GT(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.GT, x) }
+func (w whereHelperstring) GTE(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.GTE, x) }
+func (w whereHelperstring) LIKE(x string) qm.QueryMod  { return qm.Where(w.field+" LIKE ?", x) }
+func (w whereHelperstring) NLIKE(x string) qm.QueryMod { return qm.Where(w.field+" NOT LIKE ?", x) }
+func (w whereHelperstring) IN(slice []string) qm.QueryMod {
 	values := make([]interface{}, 0, len(slice))
 	for _, value := range slice {
 		values = append(values, value)
 	}
 	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
 }
-func (w whereHelperuint64) NIN(slice []uint64) qm.QueryMod {
+func (w whereHelperstring) NIN(slice []string) qm.QueryMod {
 	values := make([]interface{}, 0, len(slice))
 	for _, value := range slice {
 		values = append(values, value)
@@ -112,15 +114,15 @@ func (w whereHelpernull_Time) IsNull() qm.QueryMod    { return qmhelper.WhereIsN
 func (w whereHelpernull_Time) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
 
 var RoomMemberWhere = struct {
-	ID       whereHelperuint64
-	RoomID   whereHelperuint64
-	UserID   whereHelperuint64
+	ID       whereHelperstring
+	RoomID   whereHelperstring
+	UserID   whereHelperstring
 	JoinedAt whereHelpernull_Time
 	LeftAt   whereHelpernull_Time
 }{
-	ID:       whereHelperuint64{field: "`room_members`.`id`"},
-	RoomID:   whereHelperuint64{field: "`room_members`.`room_id`"},
-	UserID:   whereHelperuint64{field: "`room_members`.`user_id`"},
+	ID:       whereHelperstring{field: "`room_members`.`id`"},
+	RoomID:   whereHelperstring{field: "`room_members`.`room_id`"},
+	UserID:   whereHelperstring{field: "`room_members`.`user_id`"},
 	JoinedAt: whereHelpernull_Time{field: "`room_members`.`joined_at`"},
 	LeftAt:   whereHelpernull_Time{field: "`room_members`.`left_at`"},
 }
@@ -143,8 +145,8 @@ type roomMemberL struct{}
 
 var (
 	roomMemberAllColumns            = []string{"id", "room_id", "user_id", "joined_at", "left_at"}
-	roomMemberColumnsWithoutDefault = []string{"room_id", "user_id", "left_at"}
-	roomMemberColumnsWithDefault    = []string{"id", "joined_at"}
+	roomMemberColumnsWithoutDefault = []string{"id", "room_id", "user_id", "left_at"}
+	roomMemberColumnsWithDefault    = []string{"joined_at"}
 	roomMemberPrimaryKeyColumns     = []string{"id"}
 	roomMemberGeneratedColumns      = []string{}
 )
@@ -467,7 +469,7 @@ func RoomMembers(mods ...qm.QueryMod) roomMemberQuery {
 
 // FindRoomMember retrieves a single record by ID with an executor.
 // If selectCols is empty Find will return all columns.
-func FindRoomMember(ctx context.Context, exec boil.ContextExecutor, iD uint64, selectCols ...string) (*RoomMember, error) {
+func FindRoomMember(ctx context.Context, exec boil.ContextExecutor, iD string, selectCols ...string) (*RoomMember, error) {
 	roomMemberObj := &RoomMember{}
 
 	sel := "*"
@@ -554,29 +556,18 @@ func (o *RoomMember) Insert(ctx context.Context, exec boil.ContextExecutor, colu
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	result, err := exec.ExecContext(ctx, cache.query, vals...)
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to insert into room_members")
 	}
 
-	var lastID int64
 	var identifierCols []interface{}
 
 	if len(cache.retMapping) == 0 {
 		goto CacheNoHooks
 	}
 
-	lastID, err = result.LastInsertId()
-	if err != nil {
-		return ErrSyncFail
-	}
-
-	o.ID = uint64(lastID)
-	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == roomMemberMapping["id"] {
-		goto CacheNoHooks
-	}
-
 	identifierCols = []interface{}{
 		o.ID,
 	}
@@ -829,13 +820,12 @@ func (o *RoomMember) Upsert(ctx context.Context, exec boil.ContextExecutor, upda
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	result, err := exec.ExecContext(ctx, cache.query, vals...)
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to upsert for room_members")
 	}
 
-	var lastID int64
 	var uniqueMap []uint64
 	var nzUniqueCols []interface{}
 
@@ -843,16 +833,6 @@ func (o *RoomMember) Upsert(ctx context.Context, exec boil.ContextExecutor, upda
 		goto CacheNoHooks
 	}
 
-	lastID, err = result.LastInsertId()
-	if err != nil {
-		return ErrSyncFail
-	}
-
-	o.ID = uint64(lastID)
-	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == roomMemberMapping["id"] {
-		goto CacheNoHooks
-	}
-
 	uniqueMap, err = queries.BindMapping(roomMemberType, roomMemberMapping, nzUniques)
 	if err != nil {
 		return errors.Wrap(err, "models: unable to retrieve unique values for room_members")
@@ -1027,7 +1007,7 @@ func (o *RoomMemberSlice) ReloadAll(ctx context.Context, exec boil.ContextExecut
 }
 
 // RoomMemberExists checks if the RoomMember row exists.
-func RoomMemberExists(ctx context.Context, exec boil.ContextExecutor, iD uint64) (bool, error) {
+func RoomMemberExists(ctx context.Context, exec boil.ContextExecutor, iD string) (bool, error) {
 	var exists bool
 	sql := "select exists(select 1 from `room_members` where `id`=? limit 1)"
 
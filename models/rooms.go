@@ -24,9 +24,9 @@ import (
 
 // Room is an object representing the database table.
 type Room struct {
-	ID        uint64    `boil:"id" json:"id" toml:"id" yaml:"id"`
+	ID        string    `boil:"id" json:"id" toml:"id" yaml:"id"`
 	Name      string    `boil:"name" json:"name" toml:"name" yaml:"name"`
-	CreatedBy uint64    `boil:"created_by" json:"created_by" toml:"created_by" yaml:"created_by"`
+	CreatedBy string    `boil:"created_by" json:"created_by" toml:"created_by" yaml:"created_by"`
 	IsActive  null.Bool `boil:"is_active" json:"is_active,omitempty" toml:"is_active" yaml:"is_active,omitempty"`
 	CreatedAt null.Time `boil:"created_at" json:"created_at,omitempty" toml:"created_at" yaml:"created_at,omitempty"`
 
@@ -64,31 +64,6 @@ var RoomTableColumns = struct {
 
 // Generated where
 
-type whereHelperstring struct{ field string }
-
-func (w whereHelperstring) EQ(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.EQ, x) }
-func (w whereHelperstring) NEQ(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.NEQ, x) }
-func (w whereHelperstring) LT(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.LT, x) }
-func (w whereHelperstring) LTE(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.LTE, x) }
-func (w whereHelperstring) GT(x string) qm.QueryMod    { return qmhelper.Where(w.field, qmhelper.GT, x) }
-func (w whereHelperstring) GTE(x string) qm.QueryMod   { return qmhelper.Where(w.field, qmhelper.GTE, x) }
-func (w whereHelperstring) LIKE(x string) qm.QueryMod  { return qm.Where(w.field+" LIKE ?", x) }
-func (w whereHelperstring) NLIKE(x string) qm.QueryMod { return qm.Where(w.field+" NOT LIKE ?", x) }
-func (w whereHelperstring) IN(slice []string) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereIn(fmt.Sprintf("%s IN ?", w.field), values...)
-}
-func (w whereHelperstring) NIN(slice []string) qm.QueryMod {
-	values := make([]interface{}, 0, len(slice))
-	for _, value := range slice {
-		values = append(values, value)
-	}
-	return qm.WhereNotIn(fmt.Sprintf("%s NOT IN ?", w.field), values...)
-}
-
 type whereHelpernull_Bool struct{ field string }
 
 func (w whereHelpernull_Bool) EQ(x null.Bool) qm.QueryMod {
@@ -114,15 +89,15 @@ func (w whereHelpernull_Bool) IsNull() qm.QueryMod    { return qmhelper.WhereIsN
 func (w whereHelpernull_Bool) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
 
 var RoomWhere = struct {
-	ID        whereHelperuint64
+	ID        whereHelperstring
 	Name      whereHelperstring
-	CreatedBy whereHelperuint64
+	CreatedBy whereHelperstring
 	IsActive  whereHelpernull_Bool
 	CreatedAt whereHelpernull_Time
 }{
-	ID:        whereHelperuint64{field: "`rooms`.`id`"},
+	ID:        whereHelperstring{field: "`rooms`.`id`"},
 	Name:      whereHelperstring{field: "`rooms`.`name`"},
-	CreatedBy: whereHelperuint64{field: "`rooms`.`created_by`"},
+	CreatedBy: whereHelperstring{field: "`rooms`.`created_by`"},
 	IsActive:  whereHelpernull_Bool{field: "`rooms`.`is_active`"},
 	CreatedAt: whereHelpernull_Time{field: "`rooms`.`created_at`"},
 }
@@ -145,8 +120,8 @@ type roomL struct{}
 
 var (
 	roomAllColumns            = []string{"id", "name", "created_by", "is_active", "created_at"}
-	roomColumnsWithoutDefault = []string{"name", "created_by"}
-	roomColumnsWithDefault    = []string{"id", "is_active", "created_at"}
+	roomColumnsWithoutDefault = []string{"id", "name", "created_by"}
+	roomColumnsWithDefault    = []string{"is_active", "created_at"}
 	roomPrimaryKeyColumns     = []string{"id"}
 	roomGeneratedColumns      = []string{}
 )
@@ -469,7 +444,7 @@ func Rooms(mods ...qm.QueryMod) roomQuery {
 
 // FindRoom retrieves a single record by ID with an executor.
 // If selectCols is empty Find will return all columns.
-func FindRoom(ctx context.Context, exec boil.ContextExecutor, iD uint64, selectCols ...string) (*Room, error) {
+func FindRoom(ctx context.Context, exec boil.ContextExecutor, iD string, selectCols ...string) (*Room, error) {
 	roomObj := &Room{}
 
 	sel := "*"
@@ -563,29 +538,18 @@ func (o *Room) Insert(ctx context.Context, exec boil.ContextExecutor, columns bo
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	result, err := exec.ExecContext(ctx, cache.query, vals...)
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to insert into rooms")
 	}
 
-	var lastID int64
 	var identifierCols []interface{}
 
 	if len(cache.retMapping) == 0 {
 		goto CacheNoHooks
 	}
 
-	lastID, err = result.LastInsertId()
-	if err != nil {
-		return ErrSyncFail
-	}
-
-	o.ID = uint64(lastID)
-	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == roomMapping["id"] {
-		goto CacheNoHooks
-	}
-
 	identifierCols = []interface{}{
 		o.ID,
 	}
@@ -845,13 +809,12 @@ func (o *Room) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColu
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	result, err := exec.ExecContext(ctx, cache.query, vals...)
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to upsert for rooms")
 	}
 
-	var lastID int64
 	var uniqueMap []uint64
 	var nzUniqueCols []interface{}
 
@@ -859,16 +822,6 @@ func (o *Room) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColu
 		goto CacheNoHooks
 	}
 
-	lastID, err = result.LastInsertId()
-	if err != nil {
-		return ErrSyncFail
-	}
-
-	o.ID = uint64(lastID)
-	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == roomMapping["id"] {
-		goto CacheNoHooks
-	}
-
 	uniqueMap, err = queries.BindMapping(roomType, roomMapping, nzUniques)
 	if err != nil {
 		return errors.Wrap(err, "models: unable to retrieve unique values for rooms")
@@ -1043,7 +996,7 @@ func (o *RoomSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) er
 }
 
 // RoomExists checks if the Room row exists.
-func RoomExists(ctx context.Context, exec boil.ContextExecutor, iD uint64) (bool, error) {
+func RoomExists(ctx context.Context, exec boil.ContextExecutor, iD string) (bool, error) {
 	var exists bool
 	sql := "select exists(select 1 from `rooms` where `id`=? limit 1)"
 
@@ -24,11 +24,14 @@ import (
 
 // User is an object representing the database table.
 type User struct {
-	ID          uint64      `boil:"id" json:"id" toml:"id" yaml:"id"`
+	ID          string      `boil:"id" json:"id" toml:"id" yaml:"id"`
 	Username    string      `boil:"username" json:"username" toml:"username" yaml:"username"`
 	DisplayName null.String `boil:"display_name" json:"display_name,omitempty" toml:"display_name" yaml:"display_name,omitempty"`
 	Gender      null.String `boil:"gender" json:"gender,omitempty" toml:"gender" yaml:"gender,omitempty"`
 	CreatedAt   null.Time   `boil:"created_at" json:"created_at,omitempty" toml:"created_at" yaml:"created_at,omitempty"`
+	Email       null.String `boil:"email" json:"email,omitempty" toml:"email" yaml:"email,omitempty"`
+	DeletedAt   null.Time   `boil:"deleted_at" json:"deleted_at,omitempty" toml:"deleted_at" yaml:"deleted_at,omitempty"`
+	ErasedAt    null.Time   `boil:"erased_at" json:"erased_at,omitempty" toml:"erased_at" yaml:"erased_at,omitempty"`
 
 	R *userR `boil:"-" json:"-" toml:"-" yaml:"-"`
 	L userL  `boil:"-" json:"-" toml:"-" yaml:"-"`
@@ -40,12 +43,18 @@ var UserColumns = struct {
 	DisplayName string
 	Gender      string
 	CreatedAt   string
+	Email       string
+	DeletedAt   string
+	ErasedAt    string
 }{
 	ID:          "id",
 	Username:    "username",
 	DisplayName: "display_name",
 	Gender:      "gender",
 	CreatedAt:   "created_at",
+	Email:       "email",
+	DeletedAt:   "deleted_at",
+	ErasedAt:    "erased_at",
 }
 
 var UserTableColumns = struct {
@@ -54,12 +63,18 @@ var UserTableColumns = struct {
 	DisplayName string
 	Gender      string
 	CreatedAt   string
+	Email       string
+	DeletedAt   string
+	ErasedAt    string
 }{
 	ID:          "users.id",
 	Username:    "users.username",
 	DisplayName: "users.display_name",
 	Gender:      "users.gender",
 	CreatedAt:   "users.created_at",
+	Email:       "users.email",
+	DeletedAt:   "users.deleted_at",
+	ErasedAt:    "users.erased_at",
 }
 
 // Generated where
@@ -109,17 +124,23 @@ func (w whereHelpernull_String) IsNull() qm.QueryMod    { return qmhelper.WhereI
 func (w whereHelpernull_String) IsNotNull() qm.QueryMod { return qmhelper.WhereIsNotNull(w.field) }
 
 var UserWhere = struct {
-	ID          whereHelperuint64
+	ID          whereHelperstring
 	Username    whereHelperstring
 	DisplayName whereHelpernull_String
 	Gender      whereHelpernull_String
 	CreatedAt   whereHelpernull_Time
+	Email       whereHelpernull_String
+	DeletedAt   whereHelpernull_Time
+	ErasedAt    whereHelpernull_Time
 }{
-	ID:          whereHelperuint64{field: "`users`.`id`"},
+	ID:          whereHelperstring{field: "`users`.`id`"},
 	Username:    whereHelperstring{field: "`users`.`username`"},
 	DisplayName: whereHelpernull_String{field: "`users`.`display_name`"},
 	Gender:      whereHelpernull_String{field: "`users`.`gender`"},
 	CreatedAt:   whereHelpernull_Time{field: "`users`.`created_at`"},
+	Email:       whereHelpernull_String{field: "`users`.`email`"},
+	DeletedAt:   whereHelpernull_Time{field: "`users`.`deleted_at`"},
+	ErasedAt:    whereHelpernull_Time{field: "`users`.`erased_at`"},
 }
 
 // UserRels is where relationship names are stored.
@@ -139,9 +160,9 @@ func (*userR) NewStruct() *userR {
 type userL struct{}
 
 var (
-	userAllColumns            = []string{"id", "username", "display_name", "gender", "created_at"}
-	userColumnsWithoutDefault = []string{"username", "display_name", "gender"}
-	userColumnsWithDefault    = []string{"id", "created_at"}
+	userAllColumns            = []string{"id", "username", "display_name", "gender", "created_at", "email", "deleted_at", "erased_at"}
+	userColumnsWithoutDefault = []string{"id", "username", "display_name", "gender", "email", "deleted_at", "erased_at"}
+	userColumnsWithDefault    = []string{"created_at"}
 	userPrimaryKeyColumns     = []string{"id"}
 	userGeneratedColumns      = []string{}
 )
@@ -464,7 +485,7 @@ func Users(mods ...qm.QueryMod) userQuery {
 
 // FindUser retrieves a single record by ID with an executor.
 // If selectCols is empty Find will return all columns.
-func FindUser(ctx context.Context, exec boil.ContextExecutor, iD uint64, selectCols ...string) (*User, error) {
+func FindUser(ctx context.Context, exec boil.ContextExecutor, iD string, selectCols ...string) (*User, error) {
 	userObj := &User{}
 
 	sel := "*"
@@ -558,29 +579,18 @@ func (o *User) Insert(ctx context.Context, exec boil.ContextExecutor, columns bo
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	result, err := exec.ExecContext(ctx, cache.query, vals...)
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to insert into users")
 	}
 
-	var lastID int64
 	var identifierCols []interface{}
 
 	if len(cache.retMapping) == 0 {
 		goto CacheNoHooks
 	}
 
-	lastID, err = result.LastInsertId()
-	if err != nil {
-		return ErrSyncFail
-	}
-
-	o.ID = uint64(lastID)
-	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == userMapping["id"] {
-		goto CacheNoHooks
-	}
-
 	identifierCols = []interface{}{
 		o.ID,
 	}
@@ -736,6 +746,7 @@ func (o UserSlice) UpdateAll(ctx context.Context, exec boil.ContextExecutor, col
 var mySQLUserUniqueColumns = []string{
 	"id",
 	"username",
+	"email",
 }
 
 // Upsert attempts an insert using an executor, and does an update or ignore on conflict.
@@ -841,13 +852,12 @@ func (o *User) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColu
 		fmt.Fprintln(writer, cache.query)
 		fmt.Fprintln(writer, vals)
 	}
-	result, err := exec.ExecContext(ctx, cache.query, vals...)
+	_, err = exec.ExecContext(ctx, cache.query, vals...)
 
 	if err != nil {
 		return errors.Wrap(err, "models: unable to upsert for users")
 	}
 
-	var lastID int64
 	var uniqueMap []uint64
 	var nzUniqueCols []interface{}
 
@@ -855,16 +865,6 @@ func (o *User) Upsert(ctx context.Context, exec boil.ContextExecutor, updateColu
 		goto CacheNoHooks
 	}
 
-	lastID, err = result.LastInsertId()
-	if err != nil {
-		return ErrSyncFail
-	}
-
-	o.ID = uint64(lastID)
-	if lastID != 0 && len(cache.retMapping) == 1 && cache.retMapping[0] == userMapping["id"] {
-		goto CacheNoHooks
-	}
-
 	uniqueMap, err = queries.BindMapping(userType, userMapping, nzUniques)
 	if err != nil {
 		return errors.Wrap(err, "models: unable to retrieve unique values for users")
@@ -1039,7 +1039,7 @@ func (o *UserSlice) ReloadAll(ctx context.Context, exec boil.ContextExecutor) er
 }
 
 // UserExists checks if the User row exists.
-func UserExists(ctx context.Context, exec boil.ContextExecutor, iD uint64) (bool, error) {
+func UserExists(ctx context.Context, exec boil.ContextExecutor, iD string) (bool, error) {
 	var exists bool
 	sql := "select exists(select 1 from `users` where `id`=? limit 1)"
 